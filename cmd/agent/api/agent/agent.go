@@ -27,6 +27,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -58,6 +59,7 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/status", getStatus).Methods("GET")
 	r.HandleFunc("/stream-logs", streamLogs).Methods("POST")
 	r.HandleFunc("/dogstatsd-stats", getDogstatsdStats).Methods("GET")
+	r.HandleFunc("/dogstatsd-context-limits", getDogstatsdContextLimits).Methods("GET")
 	r.HandleFunc("/status/formatted", getFormattedStatus).Methods("GET")
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusGetterHandler).Methods("GET")
@@ -302,6 +304,33 @@ func getDogstatsdStats(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonStats)
 }
 
+func getDogstatsdContextLimits(w http.ResponseWriter, r *http.Request) {
+	log.Info("Got a request for the Dogstatsd context limits.")
+
+	stats, enabled := aggregator.GetContextLimitStats()
+	if !enabled {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := json.Marshal(map[string]string{
+			"error":      "Dogstatsd context limits not enabled in the Agent configuration",
+			"error_type": "not enabled",
+		})
+		w.WriteHeader(400)
+		w.Write(body)
+		return
+	}
+
+	jsonStats, err := json.Marshal(stats)
+	if err != nil {
+		log.Errorf("Error getting marshalled Dogstatsd context limits: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonStats)
+}
+
 func getFormattedStatus(w http.ResponseWriter, r *http.Request) {
 	log.Info("Got a request for the formatted status. Making formatted status.")
 	s, err := status.GetAndFormatStatus()
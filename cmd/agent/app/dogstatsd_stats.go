@@ -13,6 +13,7 @@ import (
 	"os"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
@@ -24,6 +25,7 @@ import (
 
 var (
 	dsdStatsFilePath string
+	dsdContextLimits bool
 )
 
 func init() {
@@ -31,6 +33,7 @@ func init() {
 	dogstatsdStatsCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
 	dogstatsdStatsCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
 	dogstatsdStatsCmd.Flags().StringVarP(&dsdStatsFilePath, "file", "o", "", "Output the dogstatsd-stats command to a file")
+	dogstatsdStatsCmd.Flags().BoolVarP(&dsdContextLimits, "context-limits", "", false, "print the metrics and origins which exceeded the dogstatsd context limits")
 }
 
 var dogstatsdStatsCmd = &cobra.Command{
@@ -59,7 +62,13 @@ var dogstatsdStatsCmd = &cobra.Command{
 }
 
 func requestDogstatsdStats() error {
-	fmt.Printf("Getting the dogstatsd stats from the agent.\n\n")
+	endpoint, formatStats := "dogstatsd-stats", dogstatsd.FormatDebugStats
+	if dsdContextLimits {
+		endpoint, formatStats = "dogstatsd-context-limits", aggregator.FormatContextLimitStats
+		fmt.Printf("Getting the dogstatsd context limits from the agent.\n\n")
+	} else {
+		fmt.Printf("Getting the dogstatsd stats from the agent.\n\n")
+	}
 	var e error
 	var s string
 	c := util.GetClient(false) // FIX: get certificates right then make this true
@@ -67,7 +76,7 @@ func requestDogstatsdStats() error {
 	if err != nil {
 		return err
 	}
-	urlstr := fmt.Sprintf("https://%v:%v/agent/dogstatsd-stats", ipcAddress, config.Datadog.GetInt("cmd_port"))
+	urlstr := fmt.Sprintf("https://%v:%v/agent/%s", ipcAddress, config.Datadog.GetInt("cmd_port"), endpoint)
 
 	// Set session token
 	e = util.SetAuthToken()
@@ -102,7 +111,7 @@ func requestDogstatsdStats() error {
 	} else if jsonStatus {
 		s = string(r)
 	} else {
-		s, e = dogstatsd.FormatDebugStats(r)
+		s, e = formatStats(r)
 		if e != nil {
 			fmt.Printf("Could not format the statistics, the data must be inconsistent. You may want to try the JSON output. Contact the support if you continue having issues.\n")
 			return nil
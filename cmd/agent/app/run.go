@@ -37,7 +37,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	orchcfg "github.com/DataDog/datadog-agent/pkg/orchestrator/config"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/remotewrite"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/serializer/sink"
 	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
@@ -61,6 +63,7 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/embed"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/net"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/nvidia/jetson"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/openmetrics"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/cpu"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/disk"
@@ -81,6 +84,8 @@ var (
 
 	orchestratorForwarder *forwarder.DefaultForwarder
 
+	metricSerializer *serializer.Serializer
+
 	runCmd = &cobra.Command{
 		Use:   "run",
 		Short: "Run the Agent",
@@ -346,8 +351,8 @@ func StartAgent() error {
 	}
 
 	// setup the aggregator
-	s := serializer.NewSerializer(common.Forwarder, orchestratorForwarder)
-	agg := aggregator.InitAggregator(s, hostname)
+	metricSerializer = serializer.NewSerializerWithSinks(common.Forwarder, orchestratorForwarder, sink.FromConfig())
+	agg := aggregator.InitAggregator(metricSerializer, hostname)
 	agg.AddAgentStartupTelemetry(version.AgentVersion)
 
 	// start dogstatsd
@@ -360,6 +365,13 @@ func StartAgent() error {
 	}
 	log.Debugf("statsd started")
 
+	// start the Prometheus remote write receiver
+	if remotewrite.IsEnabled() {
+		if err = remotewrite.StartServer(agg); err != nil {
+			log.Errorf("Could not start the Prometheus remote write receiver: %s", err)
+		}
+	}
+
 	// Start SNMP trap server
 	if traps.IsEnabled() {
 		if config.Datadog.GetBool("logs_enabled") {
@@ -406,7 +418,7 @@ func StartAgent() error {
 	misconfig.ToLog()
 
 	// setup the metadata collector
-	common.MetadataScheduler = metadata.NewScheduler(s)
+	common.MetadataScheduler = metadata.NewScheduler(metricSerializer)
 	if err := metadata.SetupMetadataCollection(common.MetadataScheduler, metadata.AllDefaultCollectors); err != nil {
 		return err
 	}
@@ -447,10 +459,14 @@ func StopAgent() {
 		common.MetadataScheduler.Stop()
 	}
 	traps.StopServer()
+	remotewrite.StopServer()
 	api.StopServer()
 	clcrunnerapi.StopCLCRunnerServer()
 	jmx.StopJmxfetch()
 	aggregator.StopDefaultAggregator()
+	if metricSerializer != nil {
+		metricSerializer.Stop()
+	}
 	if common.Forwarder != nil {
 		common.Forwarder.Stop()
 	}
@@ -20,6 +20,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/exposition"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/transform"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -108,6 +110,8 @@ var (
 	aggregatorOrchestratorMetadata             = expvar.Int{}
 	aggregatorOrchestratorMetadataErrors       = expvar.Int{}
 	aggregatorDogstatsdContexts                = expvar.Int{}
+	aggregatorTransformDroppedMetricSample     = expvar.Int{}
+	aggregatorContextLimitExceeded             = expvar.Int{}
 
 	tlmFlush = telemetry.NewCounter("aggregator", "flush",
 		[]string{"data_type", "state"}, "Number of metrics/service checks/events flushed")
@@ -117,6 +121,10 @@ var (
 		nil, "Count of hostname update")
 	tlmDogstatsdContexts = telemetry.NewGauge("aggregator", "dogstatsd_contexts",
 		nil, "Count the number of dogstatsd contexts in the aggregator")
+	tlmTransformDropped = telemetry.NewCounter("aggregator", "transform_dropped",
+		nil, "Count of metric samples dropped by the metric transform rules")
+	tlmContextLimitExceeded = telemetry.NewCounter("aggregator", "context_limit_exceeded",
+		[]string{"limit", "action"}, "Count of dogstatsd metric samples whose new context exceeded the context limits")
 
 	// Hold series to be added to aggregated series on each flush
 	recurrentSeries     metrics.Series
@@ -155,6 +163,8 @@ func init() {
 	aggregatorExpvars.Set("OrchestratorMetadata", &aggregatorOrchestratorMetadata)
 	aggregatorExpvars.Set("OrchestratorMetadataErrors", &aggregatorOrchestratorMetadataErrors)
 	aggregatorExpvars.Set("DogstatsdContexts", &aggregatorDogstatsdContexts)
+	aggregatorExpvars.Set("TransformDroppedMetricSample", &aggregatorTransformDroppedMetricSample)
+	aggregatorExpvars.Set("ContextLimitExceeded", &aggregatorContextLimitExceeded)
 }
 
 // InitAggregator returns the Singleton instance
@@ -209,6 +219,8 @@ type BufferedAggregator struct {
 
 	statsdSampler      TimeSampler
 	checkSamplers      map[check.ID]*CheckSampler
+	transformer        *transform.Transformer // nil when no metric transform rules are configured
+	exposer            *exposition.Exposer    // nil when the OpenMetrics exposition is disabled
 	serviceChecks      metrics.ServiceChecks
 	events             metrics.Events
 	flushInterval      time.Duration
@@ -274,9 +286,42 @@ func NewBufferedAggregator(s serializer.MetricSerializer, hostname string, flush
 		agentTags:               tagger.AgentTags,
 	}
 
+	aggregator.transformer = newTransformer()
+	aggregator.statsdSampler.contextResolver.limiter = newContextLimiter()
+	aggregator.exposer = newExposer()
+
 	return aggregator
 }
 
+// newTransformer returns the transformer of the metric_transform_rules, nil if
+// there are no rules or if they are invalid.
+func newTransformer() *transform.Transformer {
+	rules, err := config.GetMetricTransformRules()
+	if err != nil {
+		return nil
+	}
+	transformer, err := transform.NewTransformer(rules, config.Datadog.GetInt("metric_transform_cache_size"))
+	if err != nil {
+		log.Errorf("Could not create the metric transformer, the metric transform rules are ignored: %v", err)
+		return nil
+	}
+	return transformer
+}
+
+// newExposer returns the OpenMetrics exposer of the flushed metrics, nil if the
+// exposition is disabled or misconfigured.
+func newExposer() *exposition.Exposer {
+	if !exposition.IsEnabled() {
+		return nil
+	}
+	exposerConfig, err := exposition.ReadConfig()
+	if err != nil {
+		log.Errorf("Could not configure the OpenMetrics exposition, it is disabled: %v", err)
+		return nil
+	}
+	return exposition.NewExposer(exposerConfig)
+}
+
 // AddRecurrentSeries adds a serie to the series that are sent at every flush
 func AddRecurrentSeries(newSerie *metrics.Serie) {
 	recurrentSeriesLock.Lock()
@@ -364,6 +409,9 @@ func (agg *BufferedAggregator) handleSenderSample(ss senderMetricSample) {
 		if ss.commit {
 			checkSampler.commit(timeNowNano())
 		} else {
+			if !agg.transform(&ss.metricSample.Name, &ss.metricSample.Tags) {
+				return
+			}
 			ss.metricSample.Tags = util.SortUniqInPlace(ss.metricSample.Tags)
 			checkSampler.addSample(ss.metricSample)
 		}
@@ -377,6 +425,9 @@ func (agg *BufferedAggregator) handleSenderBucket(checkBucket senderHistogramBuc
 	defer agg.mu.Unlock()
 
 	if checkSampler, ok := agg.checkSamplers[checkBucket.id]; ok {
+		if !agg.transform(&checkBucket.bucket.Name, &checkBucket.bucket.Tags) {
+			return
+		}
 		checkBucket.bucket.Tags = util.SortUniqInPlace(checkBucket.bucket.Tags)
 		checkSampler.addBucket(checkBucket.bucket)
 	} else {
@@ -410,9 +461,28 @@ func (agg *BufferedAggregator) addEvent(e metrics.Event) {
 
 // addSample adds the metric sample
 func (agg *BufferedAggregator) addSample(metricSample *metrics.MetricSample, timestamp float64) {
+	if !agg.transform(&metricSample.Name, &metricSample.Tags) {
+		return
+	}
 	agg.statsdSampler.addSample(metricSample, timestamp)
 }
 
+// transform applies the metric transform rules to the name and tags of a metric,
+// it returns false if the metric is dropped.
+func (agg *BufferedAggregator) transform(name *string, tags *[]string) bool {
+	if agg.transformer == nil {
+		return true
+	}
+	newName, newTags, keep := agg.transformer.Transform(*name, *tags)
+	if !keep {
+		aggregatorTransformDroppedMetricSample.Add(1)
+		tlmTransformDropped.Inc()
+		return false
+	}
+	*name, *tags = newName, newTags
+	return true
+}
+
 // GetSeriesAndSketches grabs all the series & sketches from the queue and clears the queue
 // The parameter `before` is used as an end interval while retrieving series and sketches
 // from the time sampler. Metrics and sketches before this timestamp should be returned.
@@ -522,6 +592,10 @@ func (agg *BufferedAggregator) sendSeries(start time.Time, series metrics.Series
 		}
 	}
 
+	if agg.exposer != nil {
+		agg.exposer.UpdateSeries(series)
+	}
+
 	if waitForSerializer {
 		agg.pushSeries(start, series)
 	} else {
@@ -532,6 +606,9 @@ func (agg *BufferedAggregator) sendSeries(start time.Time, series metrics.Series
 func (agg *BufferedAggregator) sendSketches(start time.Time, sketches metrics.SketchSeriesList, waitForSerializer bool) {
 	// Serialize and forward sketches in a separate goroutine
 	addFlushCount("Sketches", int64(len(sketches)))
+	if agg.exposer != nil {
+		agg.exposer.UpdateSketches(sketches)
+	}
 	if len(sketches) != 0 {
 		if waitForSerializer {
 			agg.pushSketches(start, sketches)
@@ -675,6 +752,9 @@ func (agg *BufferedAggregator) Stop() {
 		}
 	}
 
+	if agg.exposer != nil {
+		agg.exposer.Stop()
+	}
 }
 
 func (agg *BufferedAggregator) run() {
@@ -686,6 +766,12 @@ func (agg *BufferedAggregator) run() {
 		}
 	}
 
+	if agg.exposer != nil {
+		if err := agg.exposer.Start(); err != nil {
+			log.Errorf("Could not start the OpenMetrics exposition: %v", err)
+		}
+	}
+
 	for {
 		select {
 		case <-agg.stopChan:
@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/transform"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -323,3 +324,31 @@ func TestTags(t *testing.T) {
 		})
 	}
 }
+
+func TestTransformRules(t *testing.T) {
+	agg := NewBufferedAggregator(nil, "hostname", DefaultFlushInterval)
+	transformer, err := transform.NewTransformer([]config.MetricTransformRule{
+		{Match: "debug.*", Drop: true},
+		{Match: "app.*", Name: "team.$1", DropTags: []string{"user_id"}},
+	}, 10)
+	require.NoError(t, err)
+	agg.transformer = transformer
+
+	// dogstatsd samples
+	agg.addSample(&metrics.MetricSample{Name: "debug.requests", Value: 1, Mtype: metrics.GaugeType, SampleRate: 1}, 10)
+	agg.addSample(&metrics.MetricSample{Name: "app.requests", Value: 2, Mtype: metrics.GaugeType, Tags: []string{"env:prod", "user_id:42"}, SampleRate: 1}, 10)
+	series, _ := agg.statsdSampler.flush(100)
+	require.Len(t, series, 1)
+	assert.Equal(t, "team.requests", series[0].Name)
+	assert.Equal(t, []string{"env:prod"}, series[0].Tags)
+
+	// check samples
+	require.NoError(t, agg.registerSender(checkID1))
+	agg.handleSenderSample(senderMetricSample{checkID1, &metrics.MetricSample{Name: "debug.jobs", Value: 1, Mtype: metrics.GaugeType, SampleRate: 1}, false})
+	agg.handleSenderSample(senderMetricSample{checkID1, &metrics.MetricSample{Name: "app.jobs", Value: 3, Mtype: metrics.GaugeType, Tags: []string{"user_id:42"}, SampleRate: 1}, false})
+	agg.handleSenderSample(senderMetricSample{checkID1, &metrics.MetricSample{}, true})
+	series, _ = agg.checkSamplers[checkID1].flush()
+	require.Len(t, series, 1)
+	assert.Equal(t, "team.jobs", series[0].Name)
+	assert.Empty(t, series[0].Tags)
+}
@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// contextLimitActionOverflow folds the contexts over the limits into the overflow context of their metric
+	contextLimitActionOverflow = "overflow"
+	// contextLimitActionDrop drops the samples of the contexts over the limits
+	contextLimitActionDrop = "drop"
+
+	// overflowTag tags the overflow contexts, into which the contexts over the limits are folded
+	overflowTag = "context_overflow:true"
+
+	// maxContextLimitOffenders is the maximum number of metrics and origins recorded as exceeding the limits
+	maxContextLimitOffenders = 100
+)
+
+// contextLimiter limits the number of contexts tracked per metric name and per origin.
+// It's not safe for concurrent use, except for its stats.
+type contextLimiter struct {
+	maxPerMetric     int
+	maxPerOrigin     int
+	drop             bool
+	contextsByMetric map[string]int
+	contextsByOrigin map[string]int
+	stats            *contextLimitStats
+}
+
+// ContextLimitStats holds the metrics and origins whose contexts exceeded the limits, with
+// the number of samples folded into the overflow contexts or dropped.
+type ContextLimitStats struct {
+	Action  string            `json:"action"`
+	Metrics map[string]uint64 `json:"metrics"`
+	Origins map[string]uint64 `json:"origins"`
+}
+
+// contextLimitStats is the ContextLimitStats updated by the aggregator and read by the API
+type contextLimitStats struct {
+	sync.Mutex
+	ContextLimitStats
+}
+
+// newContextLimiter returns the limiter of the contexts of the DogStatsD metrics, nil if no limit is configured.
+func newContextLimiter() *contextLimiter {
+	maxPerMetric := config.Datadog.GetInt("dogstatsd_context_limit_per_metric")
+	maxPerOrigin := config.Datadog.GetInt("dogstatsd_context_limit_per_origin")
+	if maxPerMetric <= 0 && maxPerOrigin <= 0 {
+		return nil
+	}
+
+	action := config.Datadog.GetString("dogstatsd_context_limit_action")
+	if action != contextLimitActionOverflow && action != contextLimitActionDrop {
+		log.Warnf("Invalid dogstatsd_context_limit_action %q, using %q", action, contextLimitActionOverflow)
+		action = contextLimitActionOverflow
+	}
+
+	return &contextLimiter{
+		maxPerMetric:     maxPerMetric,
+		maxPerOrigin:     maxPerOrigin,
+		drop:             action == contextLimitActionDrop,
+		contextsByMetric: make(map[string]int),
+		contextsByOrigin: make(map[string]int),
+		stats: &contextLimitStats{
+			ContextLimitStats: ContextLimitStats{
+				Action:  action,
+				Metrics: make(map[string]uint64),
+				Origins: make(map[string]uint64),
+			},
+		},
+	}
+}
+
+// track tracks a new context of a metric sent by an origin, the origin is empty when unknown.
+// It returns false when the context exceeds a limit, the context is not tracked then.
+func (l *contextLimiter) track(name string, origin string) bool {
+	if l.maxPerMetric > 0 && l.contextsByMetric[name] >= l.maxPerMetric {
+		l.recordExceeded(name, origin, "metric")
+		return false
+	}
+	if origin != "" && l.maxPerOrigin > 0 && l.contextsByOrigin[origin] >= l.maxPerOrigin {
+		l.recordExceeded(name, origin, "origin")
+		return false
+	}
+
+	l.contextsByMetric[name]++
+	if origin != "" {
+		l.contextsByOrigin[origin]++
+	}
+	return true
+}
+
+// untrack stops tracking an expired context.
+func (l *contextLimiter) untrack(name string, origin string) {
+	decrement(l.contextsByMetric, name)
+	if origin != "" {
+		decrement(l.contextsByOrigin, origin)
+	}
+}
+
+func decrement(counts map[string]int, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+	} else {
+		counts[key]--
+	}
+}
+
+// recordExceeded records the metric and origin of a sample exceeding a limit.
+func (l *contextLimiter) recordExceeded(name string, origin string, limit string) {
+	action := contextLimitActionOverflow
+	if l.drop {
+		action = contextLimitActionDrop
+	}
+	tlmContextLimitExceeded.Inc(limit, action)
+	aggregatorContextLimitExceeded.Add(1)
+
+	l.stats.Lock()
+	defer l.stats.Unlock()
+	if recordOffender(l.stats.Metrics, name) {
+		log.Warnf("The metric %q exceeded the DogStatsD contexts limit per %s, its new contexts are %s", name, limit, actionDescription(l.drop))
+	}
+	if origin != "" && recordOffender(l.stats.Origins, origin) {
+		log.Warnf("The origin %q exceeded the DogStatsD contexts limit per %s, its new contexts are %s", origin, limit, actionDescription(l.drop))
+	}
+}
+
+// recordOffender increments the count of an offender, it returns true when it's recorded for the first time.
+func recordOffender(offenders map[string]uint64, key string) bool {
+	if _, found := offenders[key]; found {
+		offenders[key]++
+		return false
+	}
+	if len(offenders) >= maxContextLimitOffenders {
+		return false
+	}
+	offenders[key] = 1
+	return true
+}
+
+func actionDescription(drop bool) string {
+	if drop {
+		return "dropped"
+	}
+	return "folded into an overflow context tagged with " + overflowTag
+}
+
+// get returns a copy of the stats.
+func (s *contextLimitStats) get() ContextLimitStats {
+	s.Lock()
+	defer s.Unlock()
+	stats := ContextLimitStats{
+		Action:  s.Action,
+		Metrics: make(map[string]uint64, len(s.Metrics)),
+		Origins: make(map[string]uint64, len(s.Origins)),
+	}
+	for name, count := range s.Metrics {
+		stats.Metrics[name] = count
+	}
+	for origin, count := range s.Origins {
+		stats.Origins[origin] = count
+	}
+	return stats
+}
+
+// GetContextLimitStats returns the metrics and origins whose DogStatsD contexts exceeded
+// the limits, false if no limit is configured.
+func GetContextLimitStats() (ContextLimitStats, bool) {
+	if aggregatorInstance == nil {
+		return ContextLimitStats{}, false
+	}
+	limiter := aggregatorInstance.statsdSampler.contextResolver.limiter
+	if limiter == nil {
+		return ContextLimitStats{}, false
+	}
+	return limiter.stats.get(), true
+}
+
+// FormatContextLimitStats formats the jsonified ContextLimitStats for the dogstatsd-stats command.
+func FormatContextLimitStats(data []byte) (string, error) {
+	var stats ContextLimitStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "Contexts over the limits are: %s\n\n", actionDescription(stats.Action == contextLimitActionDrop))
+	formatOffenders(buf, "Metric", stats.Metrics)
+	buf.WriteString("\n")
+	formatOffenders(buf, "Origin", stats.Origins)
+	return buf.String(), nil
+}
+
+func formatOffenders(buf *bytes.Buffer, title string, offenders map[string]uint64) {
+	// put offenders in order: first is the one with the most samples over the limits
+	order := make([]string, 0, len(offenders))
+	for key := range offenders {
+		order = append(order, key)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return offenders[order[i]] > offenders[order[j]]
+	})
+
+	header := fmt.Sprintf("%-60s | %-20s\n", title, "Samples over limits")
+	buf.WriteString(header)
+	buf.WriteString(strings.Repeat("-", len(header)) + "\n")
+	for _, key := range order {
+		fmt.Fprintf(buf, "%-60s | %-20d\n", key, offenders[key])
+	}
+	if len(offenders) == 0 {
+		buf.WriteString("None exceeded the limits.\n")
+	}
+}
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build test
+
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func newTestContextLimiter(t *testing.T, perMetric int, perOrigin int, action string) *contextLimiter {
+	config.Datadog.Set("dogstatsd_context_limit_per_metric", perMetric)
+	config.Datadog.Set("dogstatsd_context_limit_per_origin", perOrigin)
+	config.Datadog.Set("dogstatsd_context_limit_action", action)
+	defer config.Datadog.Set("dogstatsd_context_limit_per_metric", 0)
+	defer config.Datadog.Set("dogstatsd_context_limit_per_origin", 0)
+	defer config.Datadog.Set("dogstatsd_context_limit_action", contextLimitActionOverflow)
+
+	limiter := newContextLimiter()
+	require.NotNil(t, limiter)
+	return limiter
+}
+
+func TestNewContextLimiterDisabled(t *testing.T) {
+	assert.Nil(t, newContextLimiter())
+}
+
+func TestContextLimitPerMetricOverflow(t *testing.T) {
+	contextResolver := newContextResolver()
+	contextResolver.limiter = newTestContextLimiter(t, 2, 0, contextLimitActionOverflow)
+
+	var keys []ckey.ContextKey
+	for i := 0; i < 4; i++ {
+		sample := &metrics.MetricSample{Name: "my.metric", Tags: []string{fmt.Sprintf("user:%d", i)}, Host: "host"}
+		key, tracked := contextResolver.trackContextFromOrigin(sample, "", 1)
+		assert.True(t, tracked)
+		keys = append(keys, key)
+	}
+	// another metric isn't limited
+	_, tracked := contextResolver.trackContextFromOrigin(&metrics.MetricSample{Name: "other.metric"}, "", 1)
+	assert.True(t, tracked)
+
+	// the 3rd and 4th contexts are folded into the overflow context
+	assert.Equal(t, keys[2], keys[3])
+	assert.NotEqual(t, keys[1], keys[2])
+	assert.Equal(t, 4, contextResolver.length())
+	overflow := contextResolver.contextsByKey[keys[2]]
+	assert.Equal(t, "my.metric", overflow.Name)
+	assert.Equal(t, "host", overflow.Host)
+	assert.Equal(t, []string{overflowTag}, overflow.Tags)
+
+	stats := contextResolver.limiter.stats.get()
+	assert.Equal(t, map[string]uint64{"my.metric": 2}, stats.Metrics)
+	assert.Empty(t, stats.Origins)
+}
+
+func TestContextLimitPerOriginDrop(t *testing.T) {
+	contextResolver := newContextResolver()
+	contextResolver.limiter = newTestContextLimiter(t, 0, 1, contextLimitActionDrop)
+
+	_, tracked := contextResolver.trackContextFromOrigin(&metrics.MetricSample{Name: "a"}, "container_id://abc", 1)
+	assert.True(t, tracked)
+	_, tracked = contextResolver.trackContextFromOrigin(&metrics.MetricSample{Name: "b"}, "container_id://abc", 1)
+	assert.False(t, tracked)
+	// other origins and samples without origin aren't limited
+	_, tracked = contextResolver.trackContextFromOrigin(&metrics.MetricSample{Name: "b"}, "container_id://def", 1)
+	assert.True(t, tracked)
+	_, tracked = contextResolver.trackContextFromOrigin(&metrics.MetricSample{Name: "c"}, "", 1)
+	assert.True(t, tracked)
+	// a known context is still tracked
+	_, tracked = contextResolver.trackContextFromOrigin(&metrics.MetricSample{Name: "a"}, "container_id://abc", 2)
+	assert.True(t, tracked)
+	assert.Equal(t, 3, contextResolver.length())
+
+	stats := contextResolver.limiter.stats.get()
+	assert.Equal(t, map[string]uint64{"b": 1}, stats.Metrics)
+	assert.Equal(t, map[string]uint64{"container_id://abc": 1}, stats.Origins)
+
+	// the expired contexts free their slot
+	contextResolver.expireContexts(3)
+	assert.Equal(t, 0, contextResolver.length())
+	_, tracked = contextResolver.trackContextFromOrigin(&metrics.MetricSample{Name: "b"}, "container_id://abc", 4)
+	assert.True(t, tracked)
+}
+
+func TestTimeSamplerContextLimit(t *testing.T) {
+	sampler := NewTimeSampler(10)
+	sampler.contextResolver.limiter = newTestContextLimiter(t, 1, 0, contextLimitActionDrop)
+
+	sampler.addSample(&metrics.MetricSample{Name: "my.metric", Value: 1, Mtype: metrics.GaugeType, Tags: []string{"a"}, SampleRate: 1}, 12)
+	sampler.addSample(&metrics.MetricSample{Name: "my.metric", Value: 2, Mtype: metrics.GaugeType, Tags: []string{"b"}, SampleRate: 1}, 12)
+
+	series, _ := sampler.flush(30)
+	require.Len(t, series, 1)
+	assert.Equal(t, []string{"a"}, series[0].Tags)
+}
+
+func TestFormatContextLimitStats(t *testing.T) {
+	data, err := json.Marshal(ContextLimitStats{
+		Action:  contextLimitActionOverflow,
+		Metrics: map[string]uint64{"my.metric": 3, "other.metric": 12},
+	})
+	require.NoError(t, err)
+
+	formatted, err := FormatContextLimitStats(data)
+	require.NoError(t, err)
+	assert.Contains(t, formatted, overflowTag)
+	assert.Regexp(t, `(?s)other\.metric +\| 12 .*my\.metric +\| 3 `, formatted)
+	assert.Contains(t, formatted, "None exceeded the limits.")
+}
@@ -18,6 +18,10 @@ type Context struct {
 	Name string
 	Tags []string
 	Host string
+	// origin is the origin of the samples of the context, used to apply the context limits
+	origin string
+	// overflow is true for the contexts into which the contexts over the limits are folded
+	overflow bool
 }
 
 // ContextResolver allows tracking and expiring contexts
@@ -28,6 +32,8 @@ type ContextResolver struct {
 	// buffer slice allocated once per ContextResolver to combine and sort
 	// tags, origin detection tags and k8s tags.
 	tagsBuffer *util.TagsBuilder
+	// limiter limits the number of contexts, nil when there are no limits
+	limiter *contextLimiter
 }
 
 // generateContextKey generates the contextKey associated with the context of the metricSample
@@ -46,22 +52,54 @@ func newContextResolver() *ContextResolver {
 
 // trackContext returns the contextKey associated with the context of the metricSample and tracks that context
 func (cr *ContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp float64) ckey.ContextKey {
+	contextKey, _ := cr.trackContextFromOrigin(metricSampleContext, "", currentTimestamp)
+	return contextKey
+}
+
+// trackContextFromOrigin tracks the context of the metricSample sent by the given origin like trackContext.
+// When a new context exceeds the limits, the metricSample is folded into the overflow context of its metric,
+// whose key is returned, or dropped and false is returned.
+func (cr *ContextResolver) trackContextFromOrigin(metricSampleContext metrics.MetricSampleContext, origin string, currentTimestamp float64) (ckey.ContextKey, bool) {
 	metricSampleContext.GetTags(cr.tagsBuffer)
 	contextKey := cr.generateContextKey(metricSampleContext, cr.tagsBuffer)
 
 	if _, ok := cr.contextsByKey[contextKey]; !ok {
+		if cr.limiter != nil && !cr.limiter.track(metricSampleContext.GetName(), origin) {
+			cr.tagsBuffer.Reset()
+			if cr.limiter.drop {
+				return contextKey, false
+			}
+			return cr.trackOverflowContext(metricSampleContext, currentTimestamp), true
+		}
 		// making a copy of tags for the context since tagsBuffer
 		// will be reused later. This allow us to allocate one slice
 		// per context instead of one per sample.
 		cr.contextsByKey[contextKey] = &Context{
-			Name: metricSampleContext.GetName(),
-			Tags: cr.tagsBuffer.Copy(),
-			Host: metricSampleContext.GetHost(),
+			Name:   metricSampleContext.GetName(),
+			Tags:   cr.tagsBuffer.Copy(),
+			Host:   metricSampleContext.GetHost(),
+			origin: origin,
 		}
 	}
 	cr.lastSeenByKey[contextKey] = currentTimestamp
 
 	cr.tagsBuffer.Reset()
+	return contextKey, true
+}
+
+// trackOverflowContext tracks the overflow context of the metric of the metricSample and returns its key.
+func (cr *ContextResolver) trackOverflowContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp float64) ckey.ContextKey {
+	tags := []string{overflowTag}
+	contextKey := cr.keyGenerator.Generate(metricSampleContext.GetName(), metricSampleContext.GetHost(), tags)
+	if _, ok := cr.contextsByKey[contextKey]; !ok {
+		cr.contextsByKey[contextKey] = &Context{
+			Name:     metricSampleContext.GetName(),
+			Tags:     tags,
+			Host:     metricSampleContext.GetHost(),
+			overflow: true,
+		}
+	}
+	cr.lastSeenByKey[contextKey] = currentTimestamp
 	return contextKey
 }
 
@@ -99,6 +137,9 @@ func (cr *ContextResolver) expireContexts(expireTimestamp float64) []ckey.Contex
 
 	// Delete expired context keys
 	for _, expiredContextKey := range expiredContextKeys {
+		if context := cr.contextsByKey[expiredContextKey]; cr.limiter != nil && !context.overflow {
+			cr.limiter.untrack(context.Name, context.origin)
+		}
 		delete(cr.contextsByKey, expiredContextKey)
 		delete(cr.lastSeenByKey, expiredContextKey)
 	}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package exposition
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	// unmappedTagsLabel exposes the tags missing from the label mapping as labels named after their key
+	unmappedTagsLabel = "label"
+	// unmappedTagsDrop drops the tags missing from the label mapping
+	unmappedTagsDrop = "drop"
+)
+
+var validNameReplacement = regexp.MustCompile(`^[a-zA-Z0-9_]*$`)
+
+// IsEnabled returns whether the OpenMetrics exposition is enabled in the Agent configuration.
+func IsEnabled() bool {
+	return config.Datadog.GetBool("openmetrics_exposition.enabled")
+}
+
+// Config contains the configuration of the OpenMetrics exposition.
+type Config struct {
+	Port     int    `mapstructure:"port"`
+	BindHost string `mapstructure:"bind_host"`
+	// NamePrefix is prepended to the metric names
+	NamePrefix string `mapstructure:"name_prefix"`
+	// NameReplacement replaces the characters not allowed in the metric and label names
+	NameReplacement string `mapstructure:"name_replacement"`
+	// LabelMapping maps tag keys to label names, tags mapped to an empty name are dropped
+	LabelMapping map[string]string `mapstructure:"label_mapping"`
+	// UnmappedTags is what to do with the tags missing from LabelMapping: `label` or `drop`
+	UnmappedTags string `mapstructure:"unmapped_tags"`
+}
+
+// ReadConfig builds and returns configuration from Agent configuration.
+func ReadConfig() (*Config, error) {
+	var c Config
+	err := config.Datadog.UnmarshalKey("openmetrics_exposition", &c)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Port <= 0 {
+		return nil, fmt.Errorf("invalid openmetrics_exposition.port: %d", c.Port)
+	}
+	if !validNameReplacement.MatchString(c.NameReplacement) {
+		return nil, fmt.Errorf("invalid openmetrics_exposition.name_replacement %q, only letters, digits and underscores are allowed", c.NameReplacement)
+	}
+	if c.UnmappedTags != unmappedTagsLabel && c.UnmappedTags != unmappedTagsDrop {
+		return nil, fmt.Errorf("invalid openmetrics_exposition.unmapped_tags %q, must be `%s` or `%s`", c.UnmappedTags, unmappedTagsLabel, unmappedTagsDrop)
+	}
+	for key, label := range c.LabelMapping {
+		if label != "" && !validLabelName.MatchString(label) {
+			return nil, fmt.Errorf("invalid openmetrics_exposition.label_mapping label %q for the tag %q", label, key)
+		}
+		if label == quantileLabel {
+			return nil, fmt.Errorf("invalid openmetrics_exposition.label_mapping label %q for the tag %q, the label is reserved for the summaries", label, key)
+		}
+	}
+	if c.BindHost == "" {
+		// Default to global bind_host option.
+		c.BindHost = config.GetBindHost()
+	}
+
+	return &c, nil
+}
+
+// Addr returns the host:port address to listen on.
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.BindHost, strconv.Itoa(c.Port))
+}
@@ -0,0 +1,301 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package exposition
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	typeGauge   = "gauge"
+	typeUnknown = "unknown"
+	typeSummary = "summary"
+
+	// quantileLabel is the label reserved for the quantiles of the summaries,
+	// the tags with this key are exposed with the exportedQuantileLabel label
+	quantileLabel         = "quantile"
+	exportedQuantileLabel = "exported_quantile"
+)
+
+var (
+	invalidNameChars  = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	validLabelName    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	// sketchQuantiles are the quantiles exposed for the distributions
+	sketchQuantiles = []float64{0.5, 0.75, 0.95, 0.99}
+)
+
+// Exposer renders the series and sketches flushed by the aggregator in the
+// OpenMetrics text format.
+type Exposer struct {
+	config     *Config
+	httpServer *http.Server
+
+	mu       sync.RWMutex
+	series   map[string]*family
+	sketches map[string]*family
+}
+
+// family is a metric family of the exposition, with its rendered samples
+type family struct {
+	name    string
+	mtype   string
+	samples bytes.Buffer
+	// labelSets holds the labels of the metrics of the family, to expose each of them once
+	labelSets map[string]struct{}
+}
+
+// addLabels returns false if the family already has a metric with the labels, which
+// happens when tags are dropped or when several tags or names map to the same ones.
+func (f *family) addLabels(labels []string) bool {
+	key := strings.Join(labels, ",")
+	if _, found := f.labelSets[key]; found {
+		log.Debugf("Skipping a duplicate %s %s{%s} from the OpenMetrics exposition", f.mtype, f.name, key)
+		return false
+	}
+	f.labelSets[key] = struct{}{}
+	return true
+}
+
+// NewExposer returns a new Exposer.
+func NewExposer(config *Config) *Exposer {
+	return &Exposer{config: config}
+}
+
+// UpdateSeries replaces the exposed series with the last flushed ones. The series
+// are rendered right away, as the serializer modifies them once they are flushed.
+func (e *Exposer) UpdateSeries(series metrics.Series) {
+	families := make(map[string]*family)
+	for _, serie := range series {
+		if len(serie.Points) == 0 {
+			continue
+		}
+		mtype := typeGauge
+		if serie.MType == metrics.APICountType {
+			// the counts are reset at every flush, which doesn't fit the OpenMetrics counters
+			mtype = typeUnknown
+		}
+		f := e.family(families, serie.Name, mtype)
+		if f == nil {
+			continue
+		}
+		labels := e.labels(serie.Tags, serie.Host, serie.Device)
+		if !f.addLabels(labels) {
+			continue
+		}
+		point := serie.Points[len(serie.Points)-1]
+		writeSample(&f.samples, f.name, labels, point.Value, point.Ts)
+	}
+
+	e.mu.Lock()
+	e.series = families
+	e.mu.Unlock()
+}
+
+// UpdateSketches replaces the exposed sketches with the last flushed ones, they
+// are exposed as summaries.
+func (e *Exposer) UpdateSketches(sketches metrics.SketchSeriesList) {
+	families := make(map[string]*family)
+	for _, sketchSeries := range sketches {
+		if len(sketchSeries.Points) == 0 {
+			continue
+		}
+		point := sketchSeries.Points[len(sketchSeries.Points)-1]
+		if point.Sketch == nil {
+			continue
+		}
+		f := e.family(families, sketchSeries.Name, typeSummary)
+		if f == nil {
+			continue
+		}
+		labels := e.labels(sketchSeries.Tags, sketchSeries.Host, "")
+		if !f.addLabels(labels) {
+			continue
+		}
+		ts := float64(point.Ts)
+		config := quantile.Default()
+		for _, q := range sketchQuantiles {
+			quantileLabels := append(labels[:len(labels):len(labels)], `quantile="`+strconv.FormatFloat(q, 'f', -1, 64)+`"`)
+			writeSample(&f.samples, f.name, quantileLabels, point.Sketch.Quantile(config, q), ts)
+		}
+		writeSample(&f.samples, f.name+"_sum", labels, point.Sketch.Basic.Sum, ts)
+		writeSample(&f.samples, f.name+"_count", labels, float64(point.Sketch.Basic.Cnt), ts)
+	}
+
+	e.mu.Lock()
+	e.sketches = families
+	e.mu.Unlock()
+}
+
+// family returns the family of a metric, nil if the metric has the same name
+// as a family of another type.
+func (e *Exposer) family(families map[string]*family, name string, mtype string) *family {
+	name = e.sanitizeName(name)
+	f, found := families[name]
+	if !found {
+		f = &family{name: name, mtype: mtype, labelSets: make(map[string]struct{})}
+		families[name] = f
+	}
+	if f.mtype != mtype {
+		log.Debugf("Skipping the %s %q from the OpenMetrics exposition, a %s has the same name", mtype, name, f.mtype)
+		return nil
+	}
+	return f
+}
+
+// sanitizeName returns the prefixed metric name, in which the characters not allowed
+// by OpenMetrics are replaced.
+func (e *Exposer) sanitizeName(name string) string {
+	name = invalidNameChars.ReplaceAllLiteralString(e.config.NamePrefix+name, e.config.NameReplacement)
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// labelName returns the label name of a tag key, false if the tag is dropped.
+func (e *Exposer) labelName(key string) (string, bool) {
+	if label, found := e.config.LabelMapping[key]; found {
+		return label, label != ""
+	}
+	if e.config.UnmappedTags == unmappedTagsDrop {
+		return "", false
+	}
+	label := invalidLabelChars.ReplaceAllLiteralString(key, e.config.NameReplacement)
+	if label == "" || (label[0] >= '0' && label[0] <= '9') {
+		label = "_" + label
+	}
+	if label == quantileLabel {
+		label = exportedQuantileLabel
+	}
+	return label, true
+}
+
+// labels returns the sorted `name="value"` labels of the tags, host and device of a
+// metric. The values of the tags mapped to the same label are joined with commas,
+// the tags without value get the `true` value.
+func (e *Exposer) labels(tags []string, host string, device string) []string {
+	values := make(map[string][]string, len(tags)+2)
+	add := func(tag string) {
+		key, value := tag, "true"
+		if i := strings.IndexByte(tag, ':'); i >= 0 {
+			key, value = tag[:i], tag[i+1:]
+		}
+		label, keep := e.labelName(key)
+		if !keep {
+			return
+		}
+		for _, v := range values[label] {
+			if v == value {
+				return
+			}
+		}
+		values[label] = append(values[label], value)
+	}
+
+	if host != "" {
+		add("host:" + host)
+	}
+	if device != "" {
+		add("device:" + device)
+	}
+	for _, tag := range tags {
+		add(tag)
+	}
+
+	labels := make([]string, 0, len(values))
+	for label, labelValues := range values {
+		sort.Strings(labelValues)
+		labels = append(labels, label+`="`+labelValueEscaper.Replace(strings.Join(labelValues, ","))+`"`)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// writeSample writes a sample line of the exposition, ts is in seconds.
+func writeSample(buf *bytes.Buffer, name string, labels []string, value float64, ts float64) {
+	buf.WriteString(name)
+	if len(labels) > 0 {
+		buf.WriteByte('{')
+		buf.WriteString(strings.Join(labels, ","))
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	if ts > 0 {
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatFloat(ts, 'f', -1, 64))
+	}
+	buf.WriteByte('\n')
+}
+
+// render renders the families of the series and the sketches sorted by name. The sketch
+// families sharing their name, or the name of their _sum and _count samples, with a series
+// family are skipped so that every name has a single type.
+func render(series, sketches map[string]*family) []byte {
+	families := make(map[string]*family, len(series)+len(sketches))
+	for name, f := range series {
+		families[name] = f
+	}
+	for name, f := range sketches {
+		if conflict := findConflict(series, name, name+"_sum", name+"_count"); conflict != nil {
+			log.Debugf("Skipping the %s %q from the OpenMetrics exposition, a %s has the name %q", f.mtype, name, conflict.mtype, conflict.name)
+			continue
+		}
+		families[name] = f
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		f := families[name]
+		buf.WriteString("# TYPE " + f.name + " " + f.mtype + "\n")
+		buf.Write(f.samples.Bytes())
+	}
+	return buf.Bytes()
+}
+
+// findConflict returns the first family having one of the names, nil if there is none.
+func findConflict(families map[string]*family, names ...string) *family {
+	for _, name := range names {
+		if f, found := families[name]; found {
+			return f
+		}
+	}
+	return nil
+}
+
+// write writes the exposition of the last flushed series and sketches.
+func (e *Exposer) write(w io.Writer) error {
+	e.mu.RLock()
+	rendered := render(e.series, e.sketches)
+	e.mu.RUnlock()
+	for _, data := range [][]byte{rendered, []byte("# EOF\n")} {
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package exposition
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+)
+
+func newTestExposer() *Exposer {
+	return NewExposer(&Config{
+		NameReplacement: "_",
+		LabelMapping:    map[string]string{"kube_namespace": "namespace", "user_id": ""},
+		UnmappedTags:    unmappedTagsLabel,
+	})
+}
+
+func serve(t *testing.T, e *Exposer) string {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, metricsPath, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, contentType, w.Header().Get("Content-Type"))
+	return w.Body.String()
+}
+
+func TestUpdateSeries(t *testing.T) {
+	e := newTestExposer()
+	e.UpdateSeries(metrics.Series{
+		{
+			Name:   "my.gauge",
+			Points: []metrics.Point{{Value: 1, Ts: 1600000000}, {Value: 2.5, Ts: 1600000010}},
+			Tags:   []string{"kube_namespace:default", "env:prod", "env:dev", "user_id:42", "canary"},
+			Host:   "web-1",
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			Name:   "my.gauge",
+			Points: []metrics.Point{{Value: 3, Ts: 1600000010}},
+			Tags:   []string{"path:/a\"b"},
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			Name:   "9.requests-count",
+			Points: []metrics.Point{{Value: 12, Ts: 1600000010}},
+			MType:  metrics.APICountType,
+		},
+		{
+			// same name as a gauge, skipped
+			Name:   "my_gauge",
+			Points: []metrics.Point{{Value: 4, Ts: 1600000010}},
+			MType:  metrics.APICountType,
+		},
+		{
+			Name:  "no.points",
+			MType: metrics.APIGaugeType,
+		},
+	})
+
+	assert.Equal(t, `# TYPE _9_requests_count unknown
+_9_requests_count 12 1600000010
+# TYPE my_gauge gauge
+my_gauge{canary="true",env="dev,prod",host="web-1",namespace="default"} 2.5 1600000010
+my_gauge{path="/a\"b"} 3 1600000010
+# EOF
+`, serve(t, e))
+
+	// the next flush replaces the series
+	e.UpdateSeries(metrics.Series{})
+	assert.Equal(t, "# EOF\n", serve(t, e))
+}
+
+func TestUpdateSketches(t *testing.T) {
+	e := newTestExposer()
+	e.config.NamePrefix = "dd."
+	e.config.UnmappedTags = unmappedTagsDrop
+
+	agent := &quantile.Agent{}
+	for i := 1; i <= 100; i++ {
+		agent.Insert(float64(i), 1)
+	}
+	e.UpdateSketches(metrics.SketchSeriesList{{
+		Name:   "request.latency",
+		Tags:   []string{"kube_namespace:default", "env:prod"},
+		Points: []metrics.SketchPoint{{Sketch: agent.Finish(), Ts: 1600000010}},
+	}})
+	e.UpdateSeries(metrics.Series{{
+		Name:   "my.gauge",
+		Points: []metrics.Point{{Value: 1, Ts: 1600000010}},
+		MType:  metrics.APIGaugeType,
+	}})
+
+	assert.Regexp(t, `^# TYPE dd_my_gauge gauge
+dd_my_gauge 1 1600000010
+# TYPE dd_request_latency summary
+dd_request_latency\{namespace="default",quantile="0.5"\} 5\d\.\d+ 1600000010
+dd_request_latency\{namespace="default",quantile="0.75"\} 7\d\.\d+ 1600000010
+dd_request_latency\{namespace="default",quantile="0.95"\} 9\d\.\d+ 1600000010
+dd_request_latency\{namespace="default",quantile="0.99"\} 9\d\.\d+ 1600000010
+dd_request_latency_sum\{namespace="default"\} 5050 1600000010
+dd_request_latency_count\{namespace="default"\} 100 1600000010
+# EOF
+$`, serve(t, e))
+}
+
+func TestUpdateSeriesDuplicates(t *testing.T) {
+	e := newTestExposer()
+	e.config.LabelMapping["pod_namespace"] = "namespace"
+	e.UpdateSeries(metrics.Series{
+		{
+			Name:   "my.gauge",
+			Points: []metrics.Point{{Value: 1, Ts: 1600000010}},
+			Tags:   []string{"kube_namespace:default", "quantile:high"},
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			// same labels once the tags are mapped, skipped
+			Name:   "my.gauge",
+			Points: []metrics.Point{{Value: 2, Ts: 1600000010}},
+			Tags:   []string{"pod_namespace:default", "quantile:high"},
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			// same name once sanitized, skipped
+			Name:   "my_gauge",
+			Points: []metrics.Point{{Value: 3, Ts: 1600000010}},
+			Tags:   []string{"kube_namespace:default", "quantile:high"},
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			// same labels once the unmapped tags are dropped, skipped
+			Name:   "my.count",
+			Points: []metrics.Point{{Value: 4, Ts: 1600000010}},
+			Tags:   []string{"user_id:1"},
+			MType:  metrics.APICountType,
+		},
+		{
+			Name:   "my.count",
+			Points: []metrics.Point{{Value: 5, Ts: 1600000010}},
+			Tags:   []string{"user_id:2"},
+			MType:  metrics.APICountType,
+		},
+	})
+
+	assert.Equal(t, `# TYPE my_count unknown
+my_count 4 1600000010
+# TYPE my_gauge gauge
+my_gauge{exported_quantile="high",namespace="default"} 1 1600000010
+# EOF
+`, serve(t, e))
+}
+
+func TestUpdateSketchesSameNameAsSeries(t *testing.T) {
+	e := newTestExposer()
+	agent := &quantile.Agent{}
+	agent.Insert(1, 1)
+	e.UpdateSketches(metrics.SketchSeriesList{
+		{
+			Name:   "request.latency",
+			Points: []metrics.SketchPoint{{Sketch: agent.Finish(), Ts: 1600000010}},
+		},
+		{
+			Name:   "request.size",
+			Points: []metrics.SketchPoint{{Sketch: agent.Finish(), Ts: 1600000010}},
+		},
+	})
+	e.UpdateSeries(metrics.Series{
+		{
+			Name:   "request.latency",
+			Points: []metrics.Point{{Value: 1, Ts: 1600000010}},
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			Name:   "request.size.count",
+			Points: []metrics.Point{{Value: 2, Ts: 1600000010}},
+			MType:  metrics.APICountType,
+		},
+	})
+
+	// the series win, every name has a single type
+	assert.Equal(t, `# TYPE request_latency gauge
+request_latency 1 1600000010
+# TYPE request_size_count unknown
+request_size_count 2 1600000010
+# EOF
+`, serve(t, e))
+}
+
+func TestServeHTTPMethodNotAllowed(t *testing.T) {
+	w := httptest.NewRecorder()
+	newTestExposer().ServeHTTP(w, httptest.NewRequest(http.MethodPost, metricsPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestReadConfig(t *testing.T) {
+	defer config.Datadog.Set("openmetrics_exposition.name_replacement", "_")
+	defer config.Datadog.Set("openmetrics_exposition.unmapped_tags", unmappedTagsLabel)
+
+	c, err := ReadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 9202, c.Port)
+	assert.Equal(t, "_", c.NameReplacement)
+	assert.Equal(t, unmappedTagsLabel, c.UnmappedTags)
+
+	config.Datadog.Set("openmetrics_exposition.name_replacement", ".")
+	_, err = ReadConfig()
+	assert.Error(t, err)
+
+	config.Datadog.Set("openmetrics_exposition.name_replacement", "_")
+	config.Datadog.Set("openmetrics_exposition.unmapped_tags", "keep")
+	_, err = ReadConfig()
+	assert.Error(t, err)
+}
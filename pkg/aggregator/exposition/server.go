@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package exposition
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// metricsPath is the path of the exposition endpoint
+	metricsPath = "/metrics"
+	contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	stopTimeout = 5 * time.Second
+)
+
+// Start starts serving the exposition.
+func (e *Exposer) Start() error {
+	listener, err := net.Listen("tcp", e.config.Addr())
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, e)
+	e.httpServer = &http.Server{Handler: mux}
+
+	go func() {
+		log.Infof("Start exposing the flushed metrics in the OpenMetrics format on %s", e.config.Addr())
+		if err := e.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Errorf("OpenMetrics exposition server stopped: %s", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops serving the exposition, if it is served.
+func (e *Exposer) Stop() {
+	if e.httpServer == nil {
+		return
+	}
+	log.Infof("Stop listening on %s", e.config.Addr())
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := e.httpServer.Shutdown(ctx); err != nil {
+		log.Errorf("Stopping server. Timeout after %s: %s", stopTimeout, err)
+	}
+	e.httpServer = nil
+}
+
+// ServeHTTP serves the exposition of the last flushed series and sketches.
+func (e *Exposer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if err := e.write(w); err != nil {
+		log.Debugf("Could not write the OpenMetrics exposition to %s: %s", r.RemoteAddr, err)
+	}
+}
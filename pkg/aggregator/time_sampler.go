@@ -55,7 +55,11 @@ func (s *TimeSampler) isBucketStillOpen(bucketStartTimestamp, timestamp int64) b
 // Add the metricSample to the correct bucket
 func (s *TimeSampler) addSample(metricSample *metrics.MetricSample, timestamp float64) {
 	// Keep track of the context
-	contextKey := s.contextResolver.trackContext(metricSample, timestamp)
+	contextKey, tracked := s.contextResolver.trackContextFromOrigin(metricSample, sampleOrigin(metricSample), timestamp)
+	if !tracked {
+		// dropped by the context limits
+		return
+	}
 	bucketStart := s.calculateBucketStart(timestamp)
 
 	switch metricSample.Mtype {
@@ -80,6 +84,15 @@ func (s *TimeSampler) addSample(metricSample *metrics.MetricSample, timestamp fl
 	}
 }
 
+// sampleOrigin returns the origin of a metric sample used to apply the context limits,
+// empty when it is unknown.
+func sampleOrigin(metricSample *metrics.MetricSample) string {
+	if metricSample.OriginID != "" {
+		return metricSample.OriginID
+	}
+	return metricSample.K8sOriginID
+}
+
 func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.SketchPoint) metrics.SketchSeries {
 	ctx, _ := s.contextResolver.get(ck)
 	ss := metrics.SketchSeries{
@@ -0,0 +1,202 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/golang-lru"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/wildcard"
+)
+
+const (
+	matchTypeWildcard = "wildcard"
+	matchTypeRegex    = "regex"
+)
+
+// Transformer applies transform rules to the name and tags of the metrics
+// before they are aggregated.
+type Transformer struct {
+	rules []*rule
+	// cache holds the result of the rules matching the metric names
+	cache *lru.Cache
+}
+
+// rule is a parsed transform rule
+type rule struct {
+	regex      *regexp.Regexp
+	drop       bool
+	name       string
+	dropTags   []*regexp.Regexp
+	renameTags map[string]string
+}
+
+// nameResult is the outcome of matching the rules against a metric name, the
+// tags operations of the matching rules are applied to each metric sample.
+type nameResult struct {
+	name     string
+	dropped  bool
+	tagRules []*rule
+}
+
+// NewTransformer creates, validates, prepares a new Transformer, it returns nil
+// when there are no rules.
+func NewTransformer(configRules []config.MetricTransformRule, cacheSize int) (*Transformer, error) {
+	if len(configRules) == 0 {
+		return nil, nil
+	}
+
+	var rules []*rule
+	for i, configRule := range configRules {
+		if configRule.Match == "" {
+			return nil, fmt.Errorf("transform rule num %d: match is required", i)
+		}
+		matchType := configRule.MatchType
+		if matchType == "" {
+			matchType = matchTypeWildcard
+		}
+		if matchType != matchTypeWildcard && matchType != matchTypeRegex {
+			return nil, fmt.Errorf("transform rule num %d: invalid match type, must be `wildcard` or `regex`", i)
+		}
+		if !configRule.Drop && configRule.Name == "" && len(configRule.DropTags) == 0 && len(configRule.RenameTags) == 0 {
+			return nil, fmt.Errorf("transform rule num %d: one of drop, name, drop_tags or rename_tags is required", i)
+		}
+		regex, err := buildRegex(configRule.Match, matchType)
+		if err != nil {
+			return nil, fmt.Errorf("transform rule num %d: %v", i, err)
+		}
+		r := &rule{
+			regex:      regex,
+			drop:       configRule.Drop,
+			name:       configRule.Name,
+			renameTags: configRule.RenameTags,
+		}
+		for _, pattern := range configRule.DropTags {
+			r.dropTags = append(r.dropTags, wildcardRegex(pattern))
+		}
+		rules = append(rules, r)
+	}
+
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Transformer{rules: rules, cache: cache}, nil
+}
+
+// buildRegex returns the regex of the match of a rule, the `*` of the wildcard
+// patterns match any sequence of characters and are captured.
+func buildRegex(match string, matchType string) (*regexp.Regexp, error) {
+	if matchType == matchTypeWildcard {
+		match = strings.Replace(regexp.QuoteMeta(match), `\*`, "(.*)", -1)
+	}
+	regex, err := regexp.Compile("^" + match + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid match `%s`. cannot compile regex: %v", match, err)
+	}
+	return regex, nil
+}
+
+// wildcardRegex returns the regex of a drop_tags pattern, patterns without `:`
+// match the tag keys, the other ones match the whole tags.
+func wildcardRegex(pattern string) *regexp.Regexp {
+	expr := wildcard.Expr(pattern)
+	if !strings.Contains(pattern, ":") {
+		// tags without value are matched by their key too
+		expr += "(:.*)?"
+	}
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+// Transform applies the rules to the name and tags of a metric. It returns false when
+// the metric is dropped. The given tags are not modified, a new slice is returned
+// when a rule changes them.
+func (t *Transformer) Transform(name string, tags []string) (string, []string, bool) {
+	result := t.matchName(name)
+	if result.dropped {
+		return "", nil, false
+	}
+	for _, r := range result.tagRules {
+		tags = r.transformTags(tags)
+	}
+	return result.name, tags, true
+}
+
+// matchName applies the rules matching a metric name, the rules following a rename
+// match the new name.
+func (t *Transformer) matchName(name string) *nameResult {
+	if cached, found := t.cache.Get(name); found {
+		return cached.(*nameResult)
+	}
+
+	result := &nameResult{name: name}
+	for _, r := range t.rules {
+		matches := r.regex.FindStringSubmatchIndex(result.name)
+		if matches == nil {
+			continue
+		}
+		if r.drop {
+			result.dropped = true
+			break
+		}
+		if len(r.dropTags) > 0 || len(r.renameTags) > 0 {
+			result.tagRules = append(result.tagRules, r)
+		}
+		if r.name != "" {
+			result.name = string(r.regex.ExpandString([]byte{}, r.name, result.name, matches))
+		}
+	}
+
+	t.cache.Add(name, result)
+	return result
+}
+
+// transformTags drops and renames the tags matched by the rule.
+func (r *rule) transformTags(tags []string) []string {
+	var transformed []string
+	for i, tag := range tags {
+		newTag, keep := r.transformTag(tag)
+		if transformed == nil {
+			if keep && newTag == tag {
+				continue
+			}
+			// copy the tags on the first change to not modify the given slice
+			transformed = make([]string, i, len(tags))
+			copy(transformed, tags[:i])
+		}
+		if keep {
+			transformed = append(transformed, newTag)
+		}
+	}
+	if transformed == nil {
+		return tags
+	}
+	return transformed
+}
+
+// transformTag returns the tag renamed by the rule, false if it is dropped.
+func (r *rule) transformTag(tag string) (string, bool) {
+	for _, dropTag := range r.dropTags {
+		if dropTag.MatchString(tag) {
+			return "", false
+		}
+	}
+	if len(r.renameTags) == 0 {
+		return tag, true
+	}
+	key, value := tag, ""
+	if i := strings.IndexByte(tag, ':'); i >= 0 {
+		key, value = tag[:i], tag[i:]
+	}
+	if newKey, found := r.renameTags[key]; found {
+		return newKey + value, true
+	}
+	return tag, true
+}
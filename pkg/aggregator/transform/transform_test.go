@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestNewTransformerNoRules(t *testing.T) {
+	transformer, err := NewTransformer(nil, 10)
+	require.NoError(t, err)
+	assert.Nil(t, transformer)
+}
+
+func TestNewTransformerErrors(t *testing.T) {
+	for _, rule := range []config.MetricTransformRule{
+		{Drop: true},
+		{Match: "foo.*"},
+		{Match: "foo.*", MatchType: "glob", Drop: true},
+		{Match: "foo(", MatchType: "regex", Drop: true},
+	} {
+		_, err := NewTransformer([]config.MetricTransformRule{rule}, 10)
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	transformer, err := NewTransformer([]config.MetricTransformRule{
+		{Match: "team_a.debug.*", Drop: true},
+		{Match: "team_a.*", DropTags: []string{"user_id", "env:staging*"}},
+		{Match: "legacy.*.count", Name: "app.$1.total"},
+		{Match: `app\.(.*)\.total`, MatchType: "regex", RenameTags: map[string]string{"host_name": "origin_host"}},
+		{Match: "app.jobs.total", DropTags: []string{"job_*"}},
+	}, 10)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name         string
+		tags         []string
+		expectedName string
+		expectedTags []string
+		dropped      bool
+	}{
+		{
+			name:    "team_a.debug.requests",
+			tags:    []string{"env:prod"},
+			dropped: true,
+		},
+		{
+			name:         "team_a.requests",
+			tags:         []string{"env:staging-1", "user_id:42", "user_id", "env:prod", "service:api"},
+			expectedName: "team_a.requests",
+			expectedTags: []string{"env:prod", "service:api"},
+		},
+		{
+			name:         "legacy.jobs.count",
+			tags:         []string{"host_name:web-1", "job_id:12", "queue:default"},
+			expectedName: "app.jobs.total",
+			expectedTags: []string{"origin_host:web-1", "queue:default"},
+		},
+		{
+			name:         "legacy.requests.count",
+			tags:         []string{"host_name", "user_id:42"},
+			expectedName: "app.requests.total",
+			expectedTags: []string{"origin_host", "user_id:42"},
+		},
+		{
+			name:         "other.metric",
+			tags:         []string{"user_id:42"},
+			expectedName: "other.metric",
+			expectedTags: []string{"user_id:42"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tags := append([]string{}, test.tags...)
+			// the result is cached on the second call
+			for i := 0; i < 2; i++ {
+				name, newTags, keep := transformer.Transform(test.name, tags)
+				assert.Equal(t, !test.dropped, keep)
+				assert.Equal(t, test.expectedName, name)
+				assert.Equal(t, test.expectedTags, newTags)
+				assert.Equal(t, test.tags, tags, "the given tags should not be modified")
+			}
+		})
+	}
+}
+
+func TestTransformTagsUnchanged(t *testing.T) {
+	transformer, err := NewTransformer([]config.MetricTransformRule{
+		{Match: "*", DropTags: []string{"user_id"}},
+	}, 10)
+	require.NoError(t, err)
+
+	tags := []string{"env:prod", "service:api"}
+	_, newTags, keep := transformer.Transform("app.requests", tags)
+	assert.True(t, keep)
+	// the slice is reused when no tag is dropped
+	assert.Equal(t, &tags[0], &newTags[0])
+}
@@ -10,14 +10,25 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/types"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 const (
-	openmetricsCheckName  = "openmetrics"
-	openmetricsInitConfig = "{}"
+	openmetricsCheckName     = "openmetrics"
+	openmetricsCoreCheckName = "openmetrics_core"
+	openmetricsInitConfig    = "{}"
 )
 
+// checkName returns the name of the check scheduled on the Prometheus endpoints,
+// the Go core check replaces the Python one when 'prometheus_scrape.core_check' is enabled
+func checkName() string {
+	if config.Datadog.GetBool("prometheus_scrape.core_check") {
+		return openmetricsCoreCheckName
+	}
+	return openmetricsCheckName
+}
+
 // buildInstances generates check config instances based on the Prometheus config and the object annotations
 // The second returned value is true if more than one instance is found
 func buildInstances(pc *types.PrometheusCheck, annotations map[string]string, namespacedName string) ([]integration.Data, bool) {
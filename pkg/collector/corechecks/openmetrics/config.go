@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/util/wildcard"
+)
+
+const (
+	defaultTimeout         = 10
+	defaultBearerTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// labelJoinConfig joins the labels of a metric to the samples of the other metrics
+// sharing the values of the labels to match.
+type labelJoinConfig struct {
+	LabelsToMatch []string `yaml:"labels_to_match"`
+	LabelsToGet   []string `yaml:"labels_to_get"`
+}
+
+// instanceConfig holds the options of the openmetrics check instances, they are the same
+// as the ones of the Python openmetrics check. The tags are set by CommonConfigure.
+type instanceConfig struct {
+	URL                               string                     `yaml:"prometheus_url"`
+	Namespace                         string                     `yaml:"namespace"`
+	Metrics                           []interface{}              `yaml:"metrics"`
+	Prefix                            string                     `yaml:"prometheus_metrics_prefix"`
+	HealthServiceCheck                *bool                      `yaml:"health_service_check"`
+	LabelToHostname                   string                     `yaml:"label_to_hostname"`
+	LabelJoins                        map[string]labelJoinConfig `yaml:"label_joins"`
+	LabelsMapper                      map[string]string          `yaml:"labels_mapper"`
+	TypeOverrides                     map[string]string          `yaml:"type_overrides"`
+	SendHistogramsBuckets             *bool                      `yaml:"send_histograms_buckets"`
+	SendMonotonicCounter              *bool                      `yaml:"send_monotonic_counter"`
+	SendDistributionCountsAsMonotonic bool                       `yaml:"send_distribution_counts_as_monotonic"`
+	SendDistributionSumsAsMonotonic   bool                       `yaml:"send_distribution_sums_as_monotonic"`
+	ExcludeLabels                     []string                   `yaml:"exclude_labels"`
+	IgnoreMetrics                     []string                   `yaml:"ignore_metrics"`
+	BearerTokenAuth                   bool                       `yaml:"bearer_token_auth"`
+	BearerTokenPath                   string                     `yaml:"bearer_token_path"`
+	Username                          string                     `yaml:"username"`
+	Password                          string                     `yaml:"password"`
+	TLSVerify                         *bool                      `yaml:"tls_verify"`
+	TLSCert                           string                     `yaml:"tls_cert"`
+	TLSPrivateKey                     string                     `yaml:"tls_private_key"`
+	TLSCACert                         string                     `yaml:"tls_ca_cert"`
+	Headers                           map[string]string          `yaml:"headers"`
+	ExtraHeaders                      map[string]string          `yaml:"extra_headers"`
+	Timeout                           int                        `yaml:"timeout"`
+}
+
+// config is the parsed configuration of a check instance.
+type config struct {
+	instanceConfig
+	metrics            *metricsMatcher
+	excludedLabels     map[string]struct{}
+	healthServiceCheck bool
+	histogramBuckets   bool
+	monotonicCounter   bool
+	tlsVerify          bool
+	timeout            time.Duration
+}
+
+// metricsMatcher returns the names of the collected metrics.
+type metricsMatcher struct {
+	// names maps the Prometheus names to the metric names
+	names map[string]string
+	// patterns matches the metrics collected with their Prometheus name
+	patterns []*regexp.Regexp
+	// ignored matches the metrics never collected
+	ignored []*regexp.Regexp
+}
+
+func parseConfig(data []byte) (*config, error) {
+	var instance instanceConfig
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return nil, err
+	}
+	if instance.URL == "" {
+		return nil, fmt.Errorf("prometheus_url is required")
+	}
+	if len(instance.Metrics) == 0 {
+		return nil, fmt.Errorf("metrics is required")
+	}
+	for name, metricType := range instance.TypeOverrides {
+		if _, found := typeOverrides[metricType]; !found {
+			return nil, fmt.Errorf("invalid type_overrides of %s: %s", name, metricType)
+		}
+	}
+	for name, join := range instance.LabelJoins {
+		if len(join.LabelsToMatch) == 0 || len(join.LabelsToGet) == 0 {
+			return nil, fmt.Errorf("label_joins of %s requires labels_to_match and labels_to_get", name)
+		}
+	}
+
+	matcher, err := newMetricsMatcher(instance.Metrics, instance.IgnoreMetrics)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &config{
+		instanceConfig:     instance,
+		metrics:            matcher,
+		excludedLabels:     make(map[string]struct{}, len(instance.ExcludeLabels)),
+		healthServiceCheck: boolOrDefault(instance.HealthServiceCheck, true),
+		histogramBuckets:   boolOrDefault(instance.SendHistogramsBuckets, true),
+		monotonicCounter:   boolOrDefault(instance.SendMonotonicCounter, true),
+		tlsVerify:          boolOrDefault(instance.TLSVerify, true),
+		timeout:            defaultTimeout * time.Second,
+	}
+	for _, label := range instance.ExcludeLabels {
+		c.excludedLabels[label] = struct{}{}
+	}
+	if instance.Timeout > 0 {
+		c.timeout = time.Duration(instance.Timeout) * time.Second
+	}
+	if c.BearerTokenPath == "" {
+		c.BearerTokenPath = defaultBearerTokenPath
+	}
+	return c, nil
+}
+
+// newMetricsMatcher parses the metrics option, a list of Prometheus names, optionally with
+// wildcards, or of mappings of Prometheus names to metric names.
+func newMetricsMatcher(metrics []interface{}, ignoreMetrics []string) (*metricsMatcher, error) {
+	matcher := &metricsMatcher{
+		names: make(map[string]string),
+	}
+	for _, metric := range metrics {
+		switch value := metric.(type) {
+		case string:
+			if strings.ContainsAny(value, "*?") {
+				matcher.patterns = append(matcher.patterns, wildcard.Regexp(value))
+			} else {
+				matcher.names[value] = value
+			}
+		case map[interface{}]interface{}:
+			for promName, name := range value {
+				promNameStr, ok1 := promName.(string)
+				nameStr, ok2 := name.(string)
+				if !ok1 || !ok2 {
+					return nil, fmt.Errorf("invalid metrics mapping %v: %v", promName, name)
+				}
+				matcher.names[promNameStr] = nameStr
+			}
+		default:
+			return nil, fmt.Errorf("invalid metrics entry: %v", metric)
+		}
+	}
+	for _, metric := range ignoreMetrics {
+		matcher.ignored = append(matcher.ignored, wildcard.Regexp(metric))
+	}
+	return matcher, nil
+}
+
+// metricName returns the name of the metric with the given Prometheus name,
+// false if it is not collected.
+func (m *metricsMatcher) metricName(promName string) (string, bool) {
+	for _, re := range m.ignored {
+		if re.MatchString(promName) {
+			return "", false
+		}
+	}
+	if name, found := m.names[promName]; found {
+		return name, true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(promName) {
+			return promName, true
+		}
+	}
+	return "", false
+}
+
+func boolOrDefault(value *bool, defaultValue bool) bool {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
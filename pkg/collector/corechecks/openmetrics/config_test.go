@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+prometheus_url: http://localhost:8080/metrics
+namespace: app
+metrics:
+  - http_requests_total
+  - process_*
+  - go_goroutines: goroutines
+ignore_metrics:
+  - process_start_time_seconds
+timeout: 5
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://localhost:8080/metrics", cfg.URL)
+	assert.Equal(t, 5*time.Second, cfg.timeout)
+	assert.True(t, cfg.healthServiceCheck)
+	assert.True(t, cfg.histogramBuckets)
+	assert.True(t, cfg.monotonicCounter)
+	assert.True(t, cfg.tlsVerify)
+	assert.Equal(t, defaultBearerTokenPath, cfg.BearerTokenPath)
+
+	for promName, expected := range map[string]string{
+		"http_requests_total":        "http_requests_total",
+		"process_cpu_seconds_total":  "process_cpu_seconds_total",
+		"go_goroutines":              "goroutines",
+		"process_start_time_seconds": "",
+		"go_threads":                 "",
+	} {
+		name, collected := cfg.metrics.metricName(promName)
+		assert.Equal(t, expected != "", collected, promName)
+		assert.Equal(t, expected, name, promName)
+	}
+}
+
+func TestParseConfigFromAutodiscovery(t *testing.T) {
+	// autodiscovery generates JSON instances
+	cfg, err := parseConfig([]byte(`{"prometheus_url":"http://%%host%%:%%port%%/metrics","namespace":"","metrics":["*"],"send_histograms_buckets":false}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "", cfg.Namespace)
+	assert.False(t, cfg.histogramBuckets)
+	name, collected := cfg.metrics.metricName("anything")
+	assert.True(t, collected)
+	assert.Equal(t, "anything", name)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for _, data := range []string{
+		`metrics: ["*"]`,
+		`prometheus_url: http://localhost/metrics`,
+		`{prometheus_url: http://localhost/metrics, metrics: [1]}`,
+		`{prometheus_url: http://localhost/metrics, metrics: ["*"], type_overrides: {foo: set}}`,
+		`{prometheus_url: http://localhost/metrics, metrics: ["*"], label_joins: {foo: {labels_to_match: [pod]}}}`,
+	} {
+		_, err := parseConfig([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
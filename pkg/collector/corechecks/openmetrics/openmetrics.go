@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// CheckName is the name of the check, it can be scheduled on the Prometheus endpoints
+// discovered by autodiscovery instead of the Python openmetrics check.
+const CheckName = "openmetrics_core"
+
+// Check scrapes an endpoint exposing metrics in the Prometheus text or protobuf
+// format and submits them to the aggregator.
+type Check struct {
+	core.CheckBase
+	cfg     *config
+	scraper *scraper
+}
+
+// Configure parses the check configuration and initializes the scraper
+func (c *Check) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		log.Errorf("Error parsing configuration file: %s", err)
+		return err
+	}
+	scraper, err := newScraper(cfg)
+	if err != nil {
+		return err
+	}
+
+	c.BuildID(data, initConfig)
+	c.cfg = cfg
+	c.scraper = scraper
+
+	return c.CommonConfigure(data, source)
+}
+
+// Run scrapes the endpoint and submits its metrics
+func (c *Check) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	families, err := c.scraper.scrape()
+	if err != nil {
+		c.submitHealth(sender, metrics.ServiceCheckCritical, err.Error())
+		sender.Commit()
+		return err
+	}
+	c.submitHealth(sender, metrics.ServiceCheckOK, "")
+	newSubmitter(c.cfg, sender, families).submit()
+	sender.Commit()
+	return nil
+}
+
+// submitHealth submits the service check reporting whether the endpoint could be scraped.
+func (c *Check) submitHealth(sender aggregator.Sender, status metrics.ServiceCheckStatus, message string) {
+	if !c.cfg.healthServiceCheck {
+		return
+	}
+	sender.ServiceCheck(c.cfg.metricName("prometheus.health"), status, "", []string{"endpoint:" + c.cfg.URL}, message)
+}
+
+func factory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(CheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(CheckName, factory)
+}
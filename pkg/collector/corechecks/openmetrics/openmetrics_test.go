@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const textPayload = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200",pod="web-1"} 1027
+http_requests_total{method="post",code="400",pod="web-2"} 3
+# HELP queue_size The size of the queue.
+# TYPE queue_size gauge
+queue_size{queue="default"} 12
+queue_size{queue="empty"} NaN
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 5
+request_duration_seconds_bucket{le="1"} 8
+request_duration_seconds_bucket{le="+Inf"} 9
+request_duration_seconds_sum 4.2
+request_duration_seconds_count 9
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds{quantile="0.99"} 0.3
+rpc_duration_seconds_sum 17.5
+rpc_duration_seconds_count 100
+# TYPE pod_info gauge
+pod_info{pod="web-1",node="node-a",phase="running"} 1
+pod_info{pod="web-2",node="node-b",phase="running"} 1
+`
+
+func newTestCheck(t *testing.T, instance string) (*Check, *mocksender.MockSender) {
+	// initializes the aggregator used by CommonConfigure
+	mocksender.NewMockSender("")
+	check := factory().(*Check)
+	require.NoError(t, check.Configure([]byte(instance), []byte(""), "test"))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	return check, sender
+}
+
+func TestCheckTextFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", string(expfmt.FmtText))
+		w.Write([]byte(textPayload))
+	}))
+	defer server.Close()
+
+	check, sender := newTestCheck(t, fmt.Sprintf(`
+prometheus_url: %s
+namespace: app
+metrics:
+  - http_requests_total: http.requests
+  - queue_size
+  - request_duration_seconds
+  - rpc_duration_seconds
+labels_mapper:
+  code: status_code
+exclude_labels:
+  - method
+label_joins:
+  pod_info:
+    labels_to_match: [pod]
+    labels_to_get: [node]
+tags:
+  - env:prod
+`, server.URL))
+	require.NoError(t, check.Run())
+
+	sender.AssertServiceCheck(t, "app.prometheus.health", metrics.ServiceCheckOK, "", []string{"endpoint:" + server.URL}, "")
+	sender.AssertMetric(t, "MonotonicCount", "app.http.requests", 1027, "", []string{"status_code:200", "pod:web-1", "node:node-a"})
+	sender.AssertMetric(t, "MonotonicCount", "app.http.requests", 3, "", []string{"status_code:400", "pod:web-2", "node:node-b"})
+	sender.AssertMetricNotTaggedWith(t, "MonotonicCount", "app.http.requests", []string{"method:post"})
+	sender.AssertMetric(t, "Gauge", "app.queue_size", 12, "", []string{"queue:default"})
+	sender.AssertMetricNotTaggedWith(t, "Gauge", "app.queue_size", []string{"queue:empty"})
+
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.sum", 4.2, "", nil)
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.count", 9, "", []string{"upper_bound:none"})
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.count", 5, "", []string{"upper_bound:0.1"})
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.count", 8, "", []string{"upper_bound:1.0"})
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.count", 9, "", []string{"upper_bound:+Inf"})
+
+	sender.AssertMetric(t, "Gauge", "app.rpc_duration_seconds.sum", 17.5, "", nil)
+	sender.AssertMetric(t, "Gauge", "app.rpc_duration_seconds.count", 100, "", nil)
+	sender.AssertMetric(t, "Gauge", "app.rpc_duration_seconds.quantile", 0.05, "", []string{"quantile:0.5"})
+	sender.AssertMetric(t, "Gauge", "app.rpc_duration_seconds.quantile", 0.3, "", []string{"quantile:0.99"})
+
+	// the joined metric is not collected
+	sender.AssertNotCalled(t, "Gauge", "app.pod_info", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestCheckProtobufFormat(t *testing.T) {
+	families := []*dto.MetricFamily{
+		{
+			Name: proto.String("node_load1"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{{Name: proto.String("instance"), Value: proto.String("node-a")}},
+				Gauge: &dto.Gauge{Value: proto.Float64(0.42)},
+			}},
+		},
+		{
+			Name: proto.String("node_cpu_seconds_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{
+				Label:   []*dto.LabelPair{{Name: proto.String("instance"), Value: proto.String("node-a")}},
+				Counter: &dto.Counter{Value: proto.Float64(1234)},
+			}},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.Contains(r.Header.Get("Accept"), "application/vnd.google.protobuf"))
+		w.Header().Set("Content-Type", string(expfmt.FmtProtoDelim))
+		encoder := expfmt.NewEncoder(w, expfmt.FmtProtoDelim)
+		for _, family := range families {
+			require.NoError(t, encoder.Encode(family))
+		}
+	}))
+	defer server.Close()
+
+	check, sender := newTestCheck(t, fmt.Sprintf(`
+prometheus_url: %s
+namespace: node
+prometheus_metrics_prefix: node_
+metrics: ["*"]
+label_to_hostname: instance
+send_monotonic_counter: false
+`, server.URL))
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Gauge", "node.load1", 0.42, "node-a", []string{"instance:node-a"})
+	sender.AssertMetric(t, "Gauge", "node.cpu_seconds_total", 1234, "node-a", []string{"instance:node-a"})
+	sender.AssertNotCalled(t, "MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCheckTypeOverrides(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("jobs_processed 42\n"))
+	}))
+	defer server.Close()
+
+	check, sender := newTestCheck(t, fmt.Sprintf(`
+prometheus_url: %s
+metrics: [jobs_processed]
+type_overrides:
+  jobs_processed: counter
+health_service_check: false
+`, server.URL))
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "MonotonicCount", "jobs_processed", 42, "", nil)
+	sender.AssertNotCalled(t, "ServiceCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCheckScrapeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	check, sender := newTestCheck(t, fmt.Sprintf(`
+prometheus_url: %s
+namespace: app
+metrics: ["*"]
+`, server.URL))
+	require.Error(t, check.Run())
+
+	sender.AssertServiceCheck(t, "app.prometheus.health", metrics.ServiceCheckCritical, "", []string{"endpoint:" + server.URL}, fmt.Sprintf("unexpected status code 500 from %s", server.URL))
+	sender.AssertNumberOfCalls(t, "Gauge", 0)
+}
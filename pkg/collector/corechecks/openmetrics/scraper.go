@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+)
+
+// acceptHeader prefers the protobuf exposition format and falls back to the text one.
+const acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3,*/*;q=0.1`
+
+// scraper fetches the metric families exposed by an endpoint.
+type scraper struct {
+	cfg    *config
+	client *http.Client
+}
+
+func newScraper(cfg *config) (*scraper, error) {
+	transport := httputils.CreateHTTPTransport()
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	return &scraper{
+		cfg: cfg,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.timeout,
+		},
+	}, nil
+}
+
+func buildTLSConfig(cfg *config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !cfg.tlsVerify,
+	}
+	if cfg.TLSCACert != "" {
+		caCert, err := ioutil.ReadFile(cfg.TLSCACert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read tls_ca_cert: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in tls_ca_cert %s", cfg.TLSCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.TLSCert != "" {
+		keyFile := cfg.TLSPrivateKey
+		if keyFile == "" {
+			// the certificate and the key can be stored in the same file
+			keyFile = cfg.TLSCert
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load tls_cert: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// scrape returns the metric families exposed by the endpoint, in the text or protobuf format.
+func (s *scraper) scrape() ([]*dto.MetricFamily, error) {
+	req, err := http.NewRequest("GET", s.cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", acceptHeader)
+	for name, value := range s.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range s.cfg.ExtraHeaders {
+		req.Header.Set(name, value)
+	}
+	if s.cfg.Username != "" {
+		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+	}
+	if s.cfg.BearerTokenAuth {
+		// the token is read on each scrape as it can be rotated
+		token, err := ioutil.ReadFile(s.cfg.BearerTokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the bearer token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, s.cfg.URL)
+	}
+
+	format := expfmt.ResponseFormat(resp.Header)
+	if format == expfmt.FmtUnknown {
+		// endpoints often omit the content type of the text format
+		format = expfmt.FmtText
+	}
+	return decodeFamilies(resp.Body, format)
+}
+
+func decodeFamilies(r io.Reader, format expfmt.Format) ([]*dto.MetricFamily, error) {
+	decoder := expfmt.NewDecoder(r, format)
+	var families []*dto.MetricFamily
+	for {
+		family := &dto.MetricFamily{}
+		if err := decoder.Decode(family); err != nil {
+			if err == io.EOF {
+				return families, nil
+			}
+			return nil, fmt.Errorf("could not parse the metrics: %v", err)
+		}
+		families = append(families, family)
+	}
+}
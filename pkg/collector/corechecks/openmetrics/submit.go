@@ -0,0 +1,291 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+)
+
+// allLabels is the labels_to_get value joining all the labels of a metric
+const allLabels = "*"
+
+// typeOverrides are the metric types that can be set with the type_overrides option
+var typeOverrides = map[string]dto.MetricType{
+	"gauge":   dto.MetricType_GAUGE,
+	"counter": dto.MetricType_COUNTER,
+	"untyped": dto.MetricType_UNTYPED,
+}
+
+// metricName returns the name of the metric prefixed with the namespace.
+func (c *config) metricName(name string) string {
+	if c.Namespace == "" {
+		return name
+	}
+	return c.Namespace + "." + name
+}
+
+// submitter submits the samples of the metric families of a scrape to a sender.
+type submitter struct {
+	cfg      *config
+	sender   aggregator.Sender
+	families []*dto.MetricFamily
+	// joinNames are the sorted names of the metrics of the label_joins option
+	joinNames []string
+	// joinedLabels holds the labels to get of each joined metric, indexed by the values of its labels to match
+	joinedLabels map[string]map[string][]*dto.LabelPair
+}
+
+func newSubmitter(cfg *config, sender aggregator.Sender, families []*dto.MetricFamily) *submitter {
+	s := &submitter{
+		cfg:          cfg,
+		sender:       sender,
+		families:     families,
+		joinedLabels: make(map[string]map[string][]*dto.LabelPair, len(cfg.LabelJoins)),
+	}
+	for name := range cfg.LabelJoins {
+		s.joinNames = append(s.joinNames, name)
+	}
+	sort.Strings(s.joinNames)
+	s.storeJoinedLabels()
+	return s
+}
+
+// storeJoinedLabels indexes the labels of the metrics of the label_joins option,
+// they are joined to the samples of all the metrics of the scrape.
+func (s *submitter) storeJoinedLabels() {
+	if len(s.cfg.LabelJoins) == 0 {
+		return
+	}
+	for _, family := range s.families {
+		name := s.promName(family)
+		join, found := s.cfg.LabelJoins[name]
+		if !found {
+			continue
+		}
+		labels := make(map[string][]*dto.LabelPair)
+		for _, metric := range family.Metric {
+			key, found := joinKey(metric.Label, join.LabelsToMatch)
+			if !found {
+				continue
+			}
+			labels[key] = labelsToGet(metric.Label, join)
+		}
+		s.joinedLabels[name] = labels
+	}
+}
+
+// submit submits the samples of the collected metrics.
+func (s *submitter) submit() {
+	for _, family := range s.families {
+		promName := s.promName(family)
+		name, collected := s.cfg.metrics.metricName(promName)
+		if !collected {
+			continue
+		}
+		name = s.cfg.metricName(name)
+		metricType := family.GetType()
+		if override, found := typeOverrides[s.cfg.TypeOverrides[promName]]; found {
+			metricType = override
+		}
+		for _, metric := range family.Metric {
+			hostname, tags := s.tags(metric)
+			switch metricType {
+			case dto.MetricType_COUNTER:
+				s.submitCounter(name, metric, hostname, tags)
+			case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+				if value, ok := scalarValue(metric); ok {
+					s.sender.Gauge(name, value, hostname, tags)
+				}
+			case dto.MetricType_SUMMARY:
+				s.submitSummary(name, metric.GetSummary(), hostname, tags)
+			case dto.MetricType_HISTOGRAM:
+				s.submitHistogram(name, metric.GetHistogram(), hostname, tags)
+			}
+		}
+	}
+}
+
+// promName returns the name of the family without the prometheus_metrics_prefix.
+func (s *submitter) promName(family *dto.MetricFamily) string {
+	return strings.TrimPrefix(family.GetName(), s.cfg.Prefix)
+}
+
+func (s *submitter) submitCounter(name string, metric *dto.Metric, hostname string, tags []string) {
+	value, ok := scalarValue(metric)
+	if !ok {
+		return
+	}
+	if s.cfg.monotonicCounter {
+		s.sender.MonotonicCount(name, value, hostname, tags)
+	} else {
+		s.sender.Gauge(name, value, hostname, tags)
+	}
+}
+
+func (s *submitter) submitSummary(name string, summary *dto.Summary, hostname string, tags []string) {
+	if summary == nil {
+		return
+	}
+	s.submitDistributionCount(s.cfg.SendDistributionCountsAsMonotonic, name+".count", float64(summary.GetSampleCount()), hostname, tags)
+	s.submitDistributionCount(s.cfg.SendDistributionSumsAsMonotonic, name+".sum", summary.GetSampleSum(), hostname, tags)
+	for _, quantile := range summary.Quantile {
+		if !isValid(quantile.GetValue()) {
+			continue
+		}
+		s.sender.Gauge(name+".quantile", quantile.GetValue(), hostname, withTag(tags, "quantile:"+formatFloat(quantile.GetQuantile())))
+	}
+}
+
+func (s *submitter) submitHistogram(name string, histogram *dto.Histogram, hostname string, tags []string) {
+	if histogram == nil {
+		return
+	}
+	countTags := tags
+	if s.cfg.histogramBuckets {
+		countTags = withTag(tags, "upper_bound:none")
+	}
+	s.submitDistributionCount(s.cfg.SendDistributionCountsAsMonotonic, name+".count", float64(histogram.GetSampleCount()), hostname, countTags)
+	s.submitDistributionCount(s.cfg.SendDistributionSumsAsMonotonic, name+".sum", histogram.GetSampleSum(), hostname, tags)
+	if !s.cfg.histogramBuckets {
+		return
+	}
+	for _, bucket := range histogram.Bucket {
+		// bucket counts are cumulative like in the exposition format
+		s.submitDistributionCount(s.cfg.SendDistributionCountsAsMonotonic, name+".count", float64(bucket.GetCumulativeCount()), hostname,
+			withTag(tags, "upper_bound:"+formatFloat(bucket.GetUpperBound())))
+	}
+}
+
+func (s *submitter) submitDistributionCount(monotonic bool, name string, value float64, hostname string, tags []string) {
+	if !isValid(value) {
+		return
+	}
+	if monotonic {
+		s.sender.MonotonicCount(name, value, hostname, tags)
+	} else {
+		s.sender.Gauge(name, value, hostname, tags)
+	}
+}
+
+// tags returns the hostname of the sample, set by the label_to_hostname option,
+// and its tags built from its labels and the joined labels.
+func (s *submitter) tags(metric *dto.Metric) (string, []string) {
+	labels := metric.Label
+	for _, name := range s.joinNames {
+		if key, found := joinKey(metric.Label, s.cfg.LabelJoins[name].LabelsToMatch); found {
+			labels = append(labels[:len(labels):len(labels)], s.joinedLabels[name][key]...)
+		}
+	}
+
+	hostname := ""
+	tags := make([]string, 0, len(labels))
+	for _, label := range labels {
+		name := label.GetName()
+		if _, excluded := s.cfg.excludedLabels[name]; excluded {
+			continue
+		}
+		if s.cfg.LabelToHostname != "" && name == s.cfg.LabelToHostname {
+			hostname = label.GetValue()
+		}
+		if mapped, found := s.cfg.LabelsMapper[name]; found {
+			name = mapped
+		}
+		tags = append(tags, name+":"+label.GetValue())
+	}
+	return hostname, tags
+}
+
+// joinKey returns the values of the labels to match, false if one of them is missing.
+func joinKey(labels []*dto.LabelPair, labelsToMatch []string) (string, bool) {
+	values := make([]string, 0, len(labelsToMatch))
+	for _, name := range labelsToMatch {
+		value, found := labelValue(labels, name)
+		if !found {
+			return "", false
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+// labelsToGet returns the labels of a joined metric added to the matching samples.
+func labelsToGet(labels []*dto.LabelPair, join labelJoinConfig) []*dto.LabelPair {
+	var result []*dto.LabelPair
+	for _, label := range labels {
+		if contains(join.LabelsToMatch, label.GetName()) {
+			continue
+		}
+		if contains(join.LabelsToGet, allLabels) || contains(join.LabelsToGet, label.GetName()) {
+			result = append(result, label)
+		}
+	}
+	return result
+}
+
+func labelValue(labels []*dto.LabelPair, name string) (string, bool) {
+	for _, label := range labels {
+		if label.GetName() == name {
+			return label.GetValue(), true
+		}
+	}
+	return "", false
+}
+
+// scalarValue returns the value of a gauge, counter or untyped sample.
+func scalarValue(metric *dto.Metric) (float64, bool) {
+	var value float64
+	switch {
+	case metric.Gauge != nil:
+		value = metric.Gauge.GetValue()
+	case metric.Counter != nil:
+		value = metric.Counter.GetValue()
+	case metric.Untyped != nil:
+		value = metric.Untyped.GetValue()
+	default:
+		return 0, false
+	}
+	return value, isValid(value)
+}
+
+func isValid(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// formatFloat formats the quantiles and bucket bounds like the Python openmetrics check
+// so the tags are kept when moving from one check to the other.
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(formatted, ".") {
+		formatted += ".0"
+	}
+	return formatted
+}
+
+// withTag returns a copy of the tags with an additional tag.
+func withTag(tags []string, tag string) []string {
+	result := make([]string, len(tags), len(tags)+1)
+	copy(result, tags)
+	return append(result, tag)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -44,6 +44,7 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.bucket_size_seconds")
 	config.SetKnown("apm_config.watchdog_check_delay")
 	config.SetKnown("apm_config.sync_flushing")
+	config.SetKnown("apm_config.tail_sampling.policies")
 
 	if runtime.GOARCH == "386" && runtime.GOOS == "windows" {
 		// on Windows-32 bit, the trace agent isn't installed.  Set the default to disabled
@@ -74,10 +75,19 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.profiling_additional_endpoints", "DD_APM_PROFILING_ADDITIONAL_ENDPOINTS") //nolint:errcheck
 	config.BindEnv("apm_config.additional_endpoints", "DD_APM_ADDITIONAL_ENDPOINTS")                     //nolint:errcheck
 	config.BindEnv("apm_config.replace_tags", "DD_APM_REPLACE_TAGS")                                     //nolint:errcheck
+	config.BindEnv("apm_config.span_rules", "DD_APM_SPAN_RULES")                                         //nolint:errcheck
 	config.BindEnv("apm_config.analyzed_spans", "DD_APM_ANALYZED_SPANS")                                 //nolint:errcheck
 	config.BindEnv("apm_config.ignore_resources", "DD_APM_IGNORE_RESOURCES", "DD_IGNORE_RESOURCE")       //nolint:errcheck
 	config.BindEnv("apm_config.receiver_socket", "DD_APM_RECEIVER_SOCKET")                               //nolint:errcheck
 	config.BindEnv("apm_config.windows_pipe_name", "DD_APM_WINDOWS_PIPE_NAME")                           //nolint:errcheck
+	config.BindEnv("apm_config.otlp.bind_host", "DD_APM_OTLP_BIND_HOST")                                 //nolint:errcheck
+	config.BindEnv("apm_config.otlp.http_port", "DD_APM_OTLP_HTTP_PORT")                                 //nolint:errcheck
+	config.BindEnv("apm_config.otlp.grpc_port", "DD_APM_OTLP_GRPC_PORT")                                 //nolint:errcheck
+	config.BindEnv("apm_config.tail_sampling.enabled", "DD_APM_TAIL_SAMPLING_ENABLED")                   //nolint:errcheck
+	config.BindEnv("apm_config.tail_sampling.decision_wait", "DD_APM_TAIL_SAMPLING_DECISION_WAIT")       //nolint:errcheck
+	config.BindEnv("apm_config.tail_sampling.max_memory", "DD_APM_TAIL_SAMPLING_MAX_MEMORY")             //nolint:errcheck
+	config.BindEnv("apm_config.extra_aggregators", "DD_APM_EXTRA_AGGREGATORS")                           //nolint:errcheck
+	config.BindEnv("apm_config.extra_aggregators_max_values", "DD_APM_EXTRA_AGGREGATORS_MAX_VALUES")     //nolint:errcheck
 	config.BindEnv("apm_config.sync_flushing", "DD_APM_SYNC_FLUSHING")                                   //nolint:errcheck
 	config.BindEnv("apm_config.filter_tags.require", "DD_APM_FILTER_TAGS_REQUIRE")                       //nolint:errcheck
 	config.BindEnv("apm_config.filter_tags.reject", "DD_APM_FILTER_TAGS_REJECT")                         //nolint:errcheck
@@ -107,6 +117,14 @@ func setupAPM(config Config) {
 		return out
 	})
 
+	config.SetEnvKeyTransformer("apm_config.span_rules", func(in string) interface{} {
+		var out []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Warnf(`"apm_config.span_rules" can not be parsed: %v`, err)
+		}
+		return out
+	})
+
 	config.SetEnvKeyTransformer("apm_config.analyzed_spans", func(in string) interface{} {
 		out, err := parseAnalyzedSpans(in)
 		if err != nil {
@@ -134,6 +134,16 @@ type MetricMapping struct {
 	Tags      map[string]string `mapstructure:"tags" json:"tags"`
 }
 
+// MetricTransformRule represents a rule transforming the metrics before aggregation
+type MetricTransformRule struct {
+	Match      string            `mapstructure:"match" json:"match"`
+	MatchType  string            `mapstructure:"match_type" json:"match_type"`
+	Drop       bool              `mapstructure:"drop" json:"drop"`
+	Name       string            `mapstructure:"name" json:"name"`
+	DropTags   []string          `mapstructure:"drop_tags" json:"drop_tags"`
+	RenameTags map[string]string `mapstructure:"rename_tags" json:"rename_tags"`
+}
+
 // Warnings represent the warnings in the config
 type Warnings struct {
 	TraceMallocEnabledWithPy2 bool
@@ -331,6 +341,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_apikey_validation_interval", DefaultAPIKeyValidationInterval) // in minutes
 	config.BindEnvAndSetDefault("forwarder_num_workers", 1)
 	config.BindEnvAndSetDefault("forwarder_stop_timeout", 2)
+	config.BindEnvAndSetDefault("forwarder_priority_classes", map[string]string{})
+	config.BindEnvAndSetDefault("forwarder_bandwidth_limits", map[string]string{}) // in bytes per second, by domain
 	// Forwarder retry settings
 	config.BindEnvAndSetDefault("forwarder_backoff_factor", 2)
 	config.BindEnvAndSetDefault("forwarder_backoff_base", 2)
@@ -345,6 +357,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_flush_to_disk_mem_ratio", 0.5)
 	config.BindEnvAndSetDefault("forwarder_storage_max_size_in_bytes", 0) // 0 means disabled. This is a BETA feature.
 	config.BindEnvAndSetDefault("forwarder_storage_max_disk_ratio", 0.95) // Do not store transactions on disk when the disk usage exceeds 95% of the disk capacity.
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_key", "")   // base64 encoded AES-256 key, empty means disabled
+	config.BindEnvAndSetDefault("forwarder_storage_encryption_key_file", "")
 
 	// Dogstatsd
 	config.BindEnvAndSetDefault("use_dogstatsd", true)
@@ -383,6 +397,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("dogstatsd_entity_id_precedence", false)
 	// Sends Dogstatsd parse errors to the Debug level instead of the Error level
 	config.BindEnvAndSetDefault("dogstatsd_disable_verbose_logs", false)
+	// Bounds, in seconds, of the timestamps set by the clients with the `|T` field
+	config.BindEnvAndSetDefault("dogstatsd_timestamp_max_age", 3600)
+	config.BindEnvAndSetDefault("dogstatsd_timestamp_max_future", 600)
+	// Limits of the number of dogstatsd contexts per metric name and per origin, 0 means no limit
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_per_metric", 0)
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_per_origin", 0)
+	// What happens to the contexts over the limits: `overflow` or `drop`
+	config.BindEnvAndSetDefault("dogstatsd_context_limit_action", "overflow")
 
 	_ = config.BindEnv("dogstatsd_mapper_profiles")
 	config.SetEnvKeyTransformer("dogstatsd_mapper_profiles", func(in string) interface{} {
@@ -393,6 +415,17 @@ func InitConfig(config Config) {
 		return mappings
 	})
 
+	// Rules transforming the metrics of the checks and DogStatsD before aggregation
+	config.BindEnvAndSetDefault("metric_transform_cache_size", 1000)
+	_ = config.BindEnv("metric_transform_rules")
+	config.SetEnvKeyTransformer("metric_transform_rules", func(in string) interface{} {
+		var rules []MetricTransformRule
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"metric_transform_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
+
 	config.BindEnvAndSetDefault("statsd_forward_host", "")
 	config.BindEnvAndSetDefault("statsd_forward_port", 0)
 	config.BindEnvAndSetDefault("statsd_metric_namespace", "")
@@ -466,6 +499,7 @@ func InitConfig(config Config) {
 
 	config.BindEnvAndSetDefault("prometheus_scrape.enabled", false)           // Enables the prometheus config provider
 	config.BindEnvAndSetDefault("prometheus_scrape.service_endpoints", false) // Enables Service Endpoints checks in the prometheus config provider
+	config.BindEnvAndSetDefault("prometheus_scrape.core_check", false)        // Schedules the Go openmetrics core check instead of the Python one
 	_ = config.BindEnv("prometheus_scrape.checks")                            // Defines any extra prometheus/openmetrics check configurations to be handled by the prometheus config provider
 	config.SetEnvKeyTransformer("prometheus_scrape.checks", func(in string) interface{} {
 		var promChecks []*types.PrometheusCheck
@@ -487,6 +521,34 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("snmp_traps_config.bind_host", "localhost")
 	config.BindEnvAndSetDefault("snmp_traps_config.stop_timeout", 5) // in seconds
 
+	// Prometheus remote write receiver
+	config.BindEnvAndSetDefault("prometheus_remote_write.enabled", false)
+	config.BindEnvAndSetDefault("prometheus_remote_write.port", 9201)
+	config.BindEnvAndSetDefault("prometheus_remote_write.bind_host", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.max_request_size", 10*1024*1024) // in bytes
+	config.BindEnvAndSetDefault("prometheus_remote_write.timestamp_max_age", 3600)        // in seconds
+	config.BindEnvAndSetDefault("prometheus_remote_write.timestamp_max_future", 600)      // in seconds
+
+	// OpenMetrics exposition of the flushed metrics
+	config.BindEnvAndSetDefault("openmetrics_exposition.enabled", false)
+	config.BindEnvAndSetDefault("openmetrics_exposition.port", 9202)
+	config.BindEnvAndSetDefault("openmetrics_exposition.bind_host", "")
+	config.BindEnvAndSetDefault("openmetrics_exposition.name_prefix", "")
+	config.BindEnvAndSetDefault("openmetrics_exposition.name_replacement", "_")
+	config.BindEnvAndSetDefault("openmetrics_exposition.label_mapping", map[string]string{})
+	config.BindEnvAndSetDefault("openmetrics_exposition.unmapped_tags", "label")
+
+	// Metrics sinks
+	config.BindEnvAndSetDefault("metrics_sinks.datadog.enabled", true)
+	config.BindEnvAndSetDefault("metrics_sinks.file.enabled", false)
+	config.BindEnvAndSetDefault("metrics_sinks.file.path", "")               // defaults to <run_path>/metrics.ndjson
+	config.BindEnvAndSetDefault("metrics_sinks.file.max_size", 10*1024*1024) // in bytes
+	config.BindEnvAndSetDefault("metrics_sinks.file.max_files", 5)
+	config.BindEnvAndSetDefault("metrics_sinks.otlp.enabled", false)
+	config.BindEnvAndSetDefault("metrics_sinks.otlp.protocol", "http")
+	config.BindEnvAndSetDefault("metrics_sinks.otlp.endpoint", "")
+	config.BindEnvAndSetDefault("metrics_sinks.otlp.timeout", 10) // in seconds
+
 	// Kube ApiServer
 	config.BindEnvAndSetDefault("kubernetes_kubeconfig_path", "")
 	config.BindEnvAndSetDefault("leader_lease_duration", "60")
@@ -609,6 +671,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.frame_size", 9000)
 	// increase the number of files that can be tailed in parallel:
 	config.BindEnvAndSetDefault("logs_config.open_files_limit", 100)
+	// select the files of wildcard paths to tail first when open_files_limit is reached, by_name or by_modification_time
+	config.BindEnvAndSetDefault("logs_config.file_wildcard_selection_mode", "by_name")
+	// identify the tailed files by a checksum of their first bytes instead of their path, 0 means disabled
+	config.BindEnvAndSetDefault("logs_config.file_fingerprint_size", 0)
 	// add global processing rules that are applied on all logs
 	config.BindEnv("logs_config.processing_rules") //nolint:errcheck
 	// enforce the agent to use files to collect container logs on kubernetes environment
@@ -628,6 +694,15 @@ func InitConfig(config Config) {
 	// This field lets you increase the read timeout to prevent the client from
 	// timing out too early in such a situation. Value in seconds.
 	config.BindEnvAndSetDefault("logs_config.docker_client_read_timeout", 30)
+	// Automatically detect the multi-line aggregation pattern of the file and container sources
+	// that do not define a multi_line processing rule.
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_detection", false)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_default_sample_size", 500)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_default_match_timeout", 30) // Seconds
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_default_match_threshold", 0.48)
+	// Store on disk the payloads that can not be sent while the intake is unavailable, 0 means disabled.
+	config.BindEnvAndSetDefault("logs_config.spill_buffer_max_size_in_bytes", 0)
+	config.BindEnvAndSetDefault("logs_config.spill_buffer_path", "") // defaults to <logs_config.run_path>/spill_buffer
 	// Internal Use Only: avoid modifying those configuration parameters, this could lead to unexpected results.
 	config.BindEnvAndSetDefault("logs_config.run_path", defaultRunPath)
 	config.BindEnv("logs_config.dd_url") //nolint:errcheck
@@ -635,6 +710,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.use_tcp", false)
 	config.BindEnvAndSetDefault("logs_config.use_compression", true)
 	config.BindEnvAndSetDefault("logs_config.compression_level", 6) // Default level for the gzip/deflate algorithm
+	config.BindEnvAndSetDefault("logs_config.compression_kind", "gzip")
 	config.BindEnvAndSetDefault("logs_config.batch_wait", DefaultBatchWait)
 	config.BindEnvAndSetDefault("logs_config.connection_reset_interval", 0) // in seconds, 0 means disabled
 	config.BindEnvAndSetDefault("logs_config.dd_port", 10516)
@@ -1315,6 +1391,22 @@ func getDogstatsdMappingProfilesConfig(config Config) ([]MappingProfile, error)
 	return mappings, nil
 }
 
+// GetMetricTransformRules returns the rules transforming the metrics before aggregation
+func GetMetricTransformRules() ([]MetricTransformRule, error) {
+	return getMetricTransformRulesConfig(Datadog)
+}
+
+func getMetricTransformRulesConfig(config Config) ([]MetricTransformRule, error) {
+	var rules []MetricTransformRule
+	if config.IsSet("metric_transform_rules") {
+		err := config.UnmarshalKey("metric_transform_rules", &rules)
+		if err != nil {
+			return []MetricTransformRule{}, log.Errorf("Could not parse metric_transform_rules: %v", err)
+		}
+	}
+	return rules, nil
+}
+
 // IsCLCRunner returns whether the Agent is in cluster check runner mode
 func IsCLCRunner() bool {
 	if !Datadog.GetBool("clc_runner_enabled") {
@@ -924,6 +924,61 @@ func TestDogstatsdMappingProfilesEnv(t *testing.T) {
 	assert.Equal(t, mappings, expected)
 }
 
+func TestMetricTransformRulesOk(t *testing.T) {
+	datadogYaml := `
+metric_transform_rules:
+  - match: "team_a.debug.*"
+    drop: true
+  - match: "team_b.*"
+    name: "team_b.legacy.$1"
+    drop_tags: ["user_id", "env:staging*"]
+    rename_tags:
+      host_name: host_alias
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+
+	rules, err := getMetricTransformRulesConfig(testConfig)
+
+	expectedRules := []MetricTransformRule{
+		{Match: "team_a.debug.*", Drop: true},
+		{
+			Match:      "team_b.*",
+			Name:       "team_b.legacy.$1",
+			DropTags:   []string{"user_id", "env:staging*"},
+			RenameTags: map[string]string{"host_name": "host_alias"},
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedRules, rules)
+}
+
+func TestMetricTransformRulesError(t *testing.T) {
+	datadogYaml := `
+metric_transform_rules:
+  - abc
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+	rules, err := getMetricTransformRulesConfig(testConfig)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Could not parse metric_transform_rules")
+	assert.Empty(t, rules)
+}
+
+func TestMetricTransformRulesEnv(t *testing.T) {
+	env := "DD_METRIC_TRANSFORM_RULES"
+	err := os.Setenv(env, `[{"match":"team_a.*","drop_tags":["user_id"]},{"match":"legacy\\.(.*)","match_type":"regex","name":"new.$1"}]`)
+	assert.Nil(t, err)
+	defer os.Unsetenv(env)
+	expected := []MetricTransformRule{
+		{Match: "team_a.*", DropTags: []string{"user_id"}},
+		{Match: "legacy\\.(.*)", MatchType: "regex", Name: "new.$1"},
+	}
+	rules, _ := GetMetricTransformRules()
+	assert.Equal(t, expected, rules)
+}
+
 func TestPrometheusScrapeChecksEnv(t *testing.T) {
 	env := "DD_PROMETHEUS_SCRAPE_CHECKS"
 	err := os.Setenv(env, `[{"configurations":[{"timeout":5,"send_distribution_buckets":true}],"autodiscovery":{"kubernetes_container_names":["my-app"],"kubernetes_annotations":{"include":{"custom_label":"true"}}}}]`)
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
 
@@ -14,7 +15,7 @@ var (
 	cardinalityTagPrefix = "dd.internal.card:"
 )
 
-func extractTagsMetadata(tags []string, defaultHostname string, originTags string, entityIDPrecedenceEnabled bool) ([]string, string, string, string, string) {
+func extractTagsMetadata(tags []string, defaultHostname string, originTags string, containerID string, entityIDPrecedenceEnabled bool) ([]string, string, string, string, string) {
 	host := defaultHostname
 
 	n := 0
@@ -34,11 +35,18 @@ func extractTagsMetadata(tags []string, defaultHostname string, originTags strin
 	tags = tags[:n]
 
 	origin := ""
-	// We use the UDS socket origin if no origin ID was specify in the tags
-	// or 'dogstatsd_entity_id_precedence' is set to False (default false).
+	// We use the container ID sent in the message, or the UDS socket origin, if no
+	// origin ID was specify in the tags or 'dogstatsd_entity_id_precedence' is set
+	// to False (default false).
 	if entityIDValue == "" || !entityIDPrecedenceEnabled {
 		// Add origin tags only if the entity id tags is not provided
-		origin = originTags
+		if containerID != "" {
+			// the container ID sent by the client is more reliable than the one
+			// resolved from the PID of the sender, which may belong to a sandbox
+			origin = containers.BuildTaggerEntityName(containerID)
+		} else {
+			origin = originTags
+		}
 	}
 
 	k8sOrigin := ""
@@ -86,7 +94,7 @@ func isExcluded(metricName, namespace string, excludedNamespaces []string) bool
 func enrichMetricSample(metricSamples []metrics.MetricSample, ddSample dogstatsdMetricSample, namespace string, excludedNamespaces []string,
 	defaultHostname string, origin string, entityIDPrecedenceEnabled bool, serverlessMode bool) []metrics.MetricSample {
 	metricName := ddSample.name
-	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(ddSample.tags, defaultHostname, origin, entityIDPrecedenceEnabled)
+	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(ddSample.tags, defaultHostname, origin, ddSample.containerID, entityIDPrecedenceEnabled)
 
 	if !isExcluded(metricName, namespace, excludedNamespaces) {
 		metricName = namespace + metricName
@@ -98,6 +106,13 @@ func enrichMetricSample(metricSamples []metrics.MetricSample, ddSample dogstatsd
 
 	mtype := enrichMetricType(ddSample.metricType)
 
+	// samples timestamped by the client hold their timestamp in nanoseconds,
+	// the other ones are stamped by the aggregator on arrival
+	var timestamp float64
+	if !ddSample.timestamp.IsZero() {
+		timestamp = float64(ddSample.timestamp.UnixNano())
+	}
+
 	// if 'ddSample.values' contains values we're enriching a multi-value
 	// dogstatsd message and will create a MetricSample per value. If not
 	// we will use 'ddSample.value'and return a single MetricSample
@@ -115,6 +130,7 @@ func enrichMetricSample(metricSamples []metrics.MetricSample, ddSample dogstatsd
 					OriginID:    originID,
 					K8sOriginID: k8sOriginID,
 					Cardinality: cardinality,
+					Timestamp:   timestamp,
 				})
 		}
 		return metricSamples
@@ -132,6 +148,7 @@ func enrichMetricSample(metricSamples []metrics.MetricSample, ddSample dogstatsd
 		OriginID:    originID,
 		K8sOriginID: k8sOriginID,
 		Cardinality: cardinality,
+		Timestamp:   timestamp,
 	})
 }
 
@@ -160,7 +177,7 @@ func enrichEventAlertType(dogstatsdAlertType alertType) metrics.EventAlertType {
 }
 
 func enrichEvent(event dogstatsdEvent, defaultHostname string, origin string, entityIDPrecedenceEnabled bool) *metrics.Event {
-	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(event.tags, defaultHostname, origin, entityIDPrecedenceEnabled)
+	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(event.tags, defaultHostname, origin, event.containerID, entityIDPrecedenceEnabled)
 
 	enrichedEvent := &metrics.Event{
 		Title:          event.title,
@@ -199,7 +216,7 @@ func enrichServiceCheckStatus(status serviceCheckStatus) metrics.ServiceCheckSta
 }
 
 func enrichServiceCheck(serviceCheck dogstatsdServiceCheck, defaultHostname string, origin string, entityIDPrecedenceEnabled bool) *metrics.ServiceCheck {
-	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(serviceCheck.tags, defaultHostname, origin, entityIDPrecedenceEnabled)
+	tags, hostnameFromTags, originID, k8sOriginID, cardinality := extractTagsMetadata(serviceCheck.tags, defaultHostname, origin, serviceCheck.containerID, entityIDPrecedenceEnabled)
 
 	enrichedServiceCheck := &metrics.ServiceCheck{
 		CheckName:   serviceCheck.name,
@@ -3,6 +3,7 @@ package dogstatsd
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/stretchr/testify/assert"
@@ -113,6 +114,19 @@ func TestConvertParseSingle(t *testing.T) {
 	}
 }
 
+func TestConvertParseSingleWithTimestamp(t *testing.T) {
+	parsed, err := parseAndEnrichSingleMetricMessage([]byte("daemon:666|g|T1633000000"), "", nil, "default-hostname")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "daemon", parsed.Name)
+	assert.Equal(t, float64(1633000000*time.Second), parsed.Timestamp)
+
+	parsed, err = parseAndEnrichSingleMetricMessage([]byte("daemon:666|g"), "", nil, "default-hostname")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, parsed.Timestamp)
+}
+
 func TestConvertParseSingleWithTags(t *testing.T) {
 	for metricSymbol, metricType := range symbolToType {
 
@@ -470,6 +484,15 @@ func TestServiceCheckOriginTag(t *testing.T) {
 	assert.Equal(t, []string{"tag1:test", "tag2"}, sc.Tags)
 }
 
+func TestServiceCheckContainerID(t *testing.T) {
+	sc, err := parseAndEnrichServiceCheckMessage([]byte("_sc|agent.up|0|#tag1:test|c:abc123|m:this is fine"), "default-hostname")
+	require.Nil(t, err)
+	assert.Equal(t, "agent.up", sc.CheckName)
+	assert.Equal(t, "this is fine", sc.Message)
+	assert.Equal(t, "container_id://abc123", sc.OriginID)
+	assert.Equal(t, []string{"tag1:test"}, sc.Tags)
+}
+
 func TestConvertEventMinimal(t *testing.T) {
 	e, err := parseAndEnrichEventMessage([]byte("_e{10,9}:test title|test text"), "default-hostname")
 
@@ -786,6 +809,25 @@ func TestEventOriginTag(t *testing.T) {
 	assert.Equal(t, "", e.OriginID)
 	assert.Equal(t, "kubernetes_pod_uid://testID", e.K8sOriginID)
 }
+
+func TestEventContainerID(t *testing.T) {
+	e, err := parseAndEnrichEventMessage([]byte("_e{10,9}:test title|test text|#tag1|c:abc123"), "default-hostname")
+
+	require.Nil(t, err)
+	assert.Equal(t, "test title", e.Title)
+	assert.Equal(t, []string{"tag1"}, e.Tags)
+	assert.Equal(t, "container_id://abc123", e.OriginID)
+}
+
+func TestConvertParseSingleWithContainerID(t *testing.T) {
+	parsed, err := parseAndEnrichSingleMetricMessage([]byte("daemon:666|g|#tag1|c:abc123"), "", nil, "default-hostname")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "daemon", parsed.Name)
+	assert.Equal(t, []string{"tag1"}, parsed.Tags)
+	assert.Equal(t, "container_id://abc123", parsed.OriginID)
+}
+
 func TestConvertNamespace(t *testing.T) {
 	parsed, err := parseAndEnrichSingleMetricMessage([]byte("daemon:21|ms"), "testNamespace.", nil, "default-hostname")
 
@@ -856,6 +898,7 @@ func TestEnrichTags(t *testing.T) {
 		tags                       []string
 		defaultHostname            string
 		originTags                 string
+		containerID                string
 		entityIDPrecendenceEnabled bool
 	}
 	tests := []struct {
@@ -1020,10 +1063,55 @@ func TestEnrichTags(t *testing.T) {
 			wantedK8sOrigin:   "kubernetes_pod_uid://42",
 			wantedCardinality: "",
 		},
+		{
+			name: "containerID present, host=foo, should use the containerID instead of the originTags",
+			args: args{
+				tags:                       []string{"env:prod", cardinalityTagPrefix + "high"},
+				defaultHostname:            "foo",
+				originTags:                 "originID",
+				containerID:                "abc123",
+				entityIDPrecendenceEnabled: true,
+			},
+			wantedTags:        []string{"env:prod"},
+			wantedHost:        "foo",
+			wantedOrigin:      "container_id://abc123",
+			wantedK8sOrigin:   "",
+			wantedCardinality: "high",
+		},
+		{
+			name: "entityId=42 and containerID present entityIDPrecendenceEnabled=true, host=foo, should not use the containerID",
+			args: args{
+				tags:                       []string{"env:prod", fmt.Sprintf("%s%s", entityIDTagPrefix, "42")},
+				defaultHostname:            "foo",
+				originTags:                 "originID",
+				containerID:                "abc123",
+				entityIDPrecendenceEnabled: true,
+			},
+			wantedTags:        []string{"env:prod"},
+			wantedHost:        "foo",
+			wantedOrigin:      "",
+			wantedK8sOrigin:   "kubernetes_pod_uid://42",
+			wantedCardinality: "",
+		},
+		{
+			name: "entityId=42 and containerID present entityIDPrecendenceEnabled=false, host=foo, should use the containerID",
+			args: args{
+				tags:                       []string{"env:prod", fmt.Sprintf("%s%s", entityIDTagPrefix, "42")},
+				defaultHostname:            "foo",
+				originTags:                 "originID",
+				containerID:                "abc123",
+				entityIDPrecendenceEnabled: false,
+			},
+			wantedTags:        []string{"env:prod"},
+			wantedHost:        "foo",
+			wantedOrigin:      "container_id://abc123",
+			wantedK8sOrigin:   "kubernetes_pod_uid://42",
+			wantedCardinality: "",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tags, host, origin, k8sOrigin, cardinality := extractTagsMetadata(tt.args.tags, tt.args.defaultHostname, tt.args.originTags, tt.args.entityIDPrecendenceEnabled)
+			tags, host, origin, k8sOrigin, cardinality := extractTagsMetadata(tt.args.tags, tt.args.defaultHostname, tt.args.originTags, tt.args.containerID, tt.args.entityIDPrecendenceEnabled)
 			assert.Equal(t, tt.wantedTags, tags)
 			assert.Equal(t, tt.wantedHost, host)
 			assert.Equal(t, tt.wantedOrigin, origin)
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"time"
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -24,6 +25,10 @@ var (
 	fieldSeparator = []byte("|")
 	colonSeparator = []byte(":")
 	commaSeparator = []byte(",")
+
+	// containerIDFieldPrefix is the prefix of the optional field holding the container ID
+	// of the client, it is shared by metrics, events and service checks
+	containerIDFieldPrefix = []byte("c:")
 )
 
 // parser parses dogstatsd messages
@@ -85,6 +90,14 @@ func (p *parser) parseTags(rawTags []byte) []string {
 	return tagsList
 }
 
+// parseContainerID returns the container ID sent by the client, empty if none was sent.
+func (p *parser) parseContainerID(rawContainerID []byte) string {
+	if len(rawContainerID) == 0 {
+		return ""
+	}
+	return p.interner.LoadOrStore(rawContainerID)
+}
+
 func (p *parser) parseMetricSample(message []byte) (dogstatsdMetricSample, error) {
 	// fast path to eliminate most of the gibberish
 	// especially important here since all the unidentified garbage gets
@@ -127,6 +140,8 @@ func (p *parser) parseMetricSample(message []byte) (dogstatsdMetricSample, error
 
 	sampleRate := 1.0
 	var tags []string
+	var timestamp time.Time
+	var containerID string
 	var optionalField []byte
 	for message != nil {
 		optionalField, message = nextField(message)
@@ -137,17 +152,26 @@ func (p *parser) parseMetricSample(message []byte) (dogstatsdMetricSample, error
 			if err != nil {
 				return dogstatsdMetricSample{}, fmt.Errorf("could not parse dogstatsd sample rate %q", optionalField)
 			}
+		} else if bytes.HasPrefix(optionalField, containerIDFieldPrefix) {
+			containerID = p.parseContainerID(optionalField[len(containerIDFieldPrefix):])
+		} else if bytes.HasPrefix(optionalField, timestampFieldPrefix) {
+			timestamp, err = parseMetricSampleTimestamp(optionalField[1:])
+			if err != nil {
+				return dogstatsdMetricSample{}, fmt.Errorf("could not parse dogstatsd timestamp %q", optionalField)
+			}
 		}
 	}
 
 	return dogstatsdMetricSample{
-		name:       p.interner.LoadOrStore(name),
-		value:      value,
-		values:     values,
-		setValue:   string(setValue),
-		metricType: metricType,
-		sampleRate: sampleRate,
-		tags:       tags,
+		name:        p.interner.LoadOrStore(name),
+		value:       value,
+		values:      values,
+		setValue:    string(setValue),
+		metricType:  metricType,
+		sampleRate:  sampleRate,
+		tags:        tags,
+		timestamp:   timestamp,
+		containerID: containerID,
 	}, nil
 }
 
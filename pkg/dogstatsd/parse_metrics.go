@@ -3,6 +3,7 @@ package dogstatsd
 import (
 	"bytes"
 	"fmt"
+	"time"
 )
 
 type metricType int
@@ -26,6 +27,7 @@ var (
 
 	tagsFieldPrefix       = []byte("#")
 	sampleRateFieldPrefix = []byte("@")
+	timestampFieldPrefix  = []byte("T")
 )
 
 type dogstatsdMetricSample struct {
@@ -39,6 +41,10 @@ type dogstatsdMetricSample struct {
 	metricType metricType
 	sampleRate float64
 	tags       []string
+	// timestamp set by the client, zero if the sample is stamped on arrival
+	timestamp time.Time
+	// containerID is the container ID sent by the client for origin detection
+	containerID string
 }
 
 // sanity checks a given message against the metric sample format
@@ -47,7 +53,7 @@ func hasMetricSampleFormat(message []byte) bool {
 		return false
 	}
 	separatorCount := bytes.Count(message, fieldSeparator)
-	if separatorCount < 1 || separatorCount > 3 {
+	if separatorCount < 1 || separatorCount > 5 {
 		return false
 	}
 	return true
@@ -87,3 +93,14 @@ func parseMetricSampleMetricType(rawMetricType []byte) (metricType, error) {
 func parseMetricSampleSampleRate(rawSampleRate []byte) (float64, error) {
 	return parseFloat64(rawSampleRate)
 }
+
+func parseMetricSampleTimestamp(rawTimestamp []byte) (time.Time, error) {
+	timestamp, err := parseInt64(rawTimestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if timestamp <= 0 {
+		return time.Time{}, fmt.Errorf("invalid timestamp: %d", timestamp)
+	}
+	return time.Unix(timestamp, 0), nil
+}
@@ -2,6 +2,7 @@ package dogstatsd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -241,6 +242,42 @@ func TestParseGaugeWithSampleRate(t *testing.T) {
 	assert.InEpsilon(t, 0.21, sample.sampleRate, epsilon)
 }
 
+func TestParseGaugeWithTimestamp(t *testing.T) {
+	sample, err := parseMetricSample([]byte("daemon:666|g|@0.21|#sometag:someval|T1633000000"))
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, "daemon", sample.name)
+	assert.InEpsilon(t, 666.0, sample.value, epsilon)
+	assert.Equal(t, gaugeType, sample.metricType)
+	assert.Equal(t, []string{"sometag:someval"}, sample.tags)
+	assert.InEpsilon(t, 0.21, sample.sampleRate, epsilon)
+	assert.Equal(t, time.Unix(1633000000, 0), sample.timestamp)
+}
+
+func TestParseGaugeWithContainerID(t *testing.T) {
+	sample, err := parseMetricSample([]byte("daemon:666|g|@0.21|#sometag:someval|c:abc123|T1633000000"))
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, "daemon", sample.name)
+	assert.Equal(t, []string{"sometag:someval"}, sample.tags)
+	assert.Equal(t, "abc123", sample.containerID)
+	assert.Equal(t, time.Unix(1633000000, 0), sample.timestamp)
+
+	sample, err = parseMetricSample([]byte("daemon:666|g|c:"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", sample.containerID)
+}
+
+func TestParseGaugeWithoutTimestamp(t *testing.T) {
+	sample, err := parseMetricSample([]byte("daemon:666|g"))
+
+	assert.NoError(t, err)
+	assert.True(t, sample.timestamp.IsZero())
+}
+
 func TestParseGaugeWithPoundOnly(t *testing.T) {
 	sample, err := parseMetricSample([]byte("daemon:666|g|#"))
 
@@ -300,4 +337,14 @@ func TestParseMetricError(t *testing.T) {
 	// invalid sample rate
 	_, err = parseMetricSample([]byte("daemon:666|g|@abc"))
 	assert.Error(t, err)
+
+	// invalid timestamp
+	_, err = parseMetricSample([]byte("daemon:666|g|Tabc"))
+	assert.Error(t, err)
+
+	_, err = parseMetricSample([]byte("daemon:666|g|T-1633000000"))
+	assert.Error(t, err)
+
+	_, err = parseMetricSample([]byte("daemon:666|g|T"))
+	assert.Error(t, err)
 }
@@ -41,11 +41,15 @@ var (
 	dogstatsdMetricPackets            = expvar.Int{}
 	dogstatsdPacketsLastSec           = expvar.Int{}
 	dogstatsdUnterminatedMetricErrors = expvar.Int{}
+	dogstatsdMetricTimestampRejected  = expvar.Int{}
 
 	tlmProcessed = telemetry.NewCounter("dogstatsd", "processed",
 		[]string{"message_type", "state"}, "Count of service checks/events/metrics processed by dogstatsd")
 	tlmProcessedErrorTags = map[string]string{"message_type": "metrics", "state": "error"}
 	tlmProcessedOkTags    = map[string]string{"message_type": "metrics", "state": "ok"}
+
+	tlmMetricTimestampRejected = telemetry.NewCounter("dogstatsd", "metric_timestamp_rejected",
+		[]string{"reason"}, "Count of metric samples rejected because their timestamp is too old or too far in the future")
 )
 
 func init() {
@@ -56,6 +60,7 @@ func init() {
 	dogstatsdExpvars.Set("MetricParseErrors", &dogstatsdMetricParseErrors)
 	dogstatsdExpvars.Set("MetricPackets", &dogstatsdMetricPackets)
 	dogstatsdExpvars.Set("UnterminatedMetricErrors", &dogstatsdUnterminatedMetricErrors)
+	dogstatsdExpvars.Set("MetricTimestampRejected", &dogstatsdMetricTimestampRejected)
 }
 
 // Server represent a Dogstatsd server
@@ -87,6 +92,9 @@ type Server struct {
 	eolTerminationEnabled     bool
 	telemetryEnabled          bool
 	entityIDPrecedenceEnabled bool
+	// timestampMaxAge and timestampMaxFuture bound the timestamps set by the clients
+	timestampMaxAge    time.Duration
+	timestampMaxFuture time.Duration
 	// disableVerboseLogs is a feature flag to disable the logs capable
 	// of flooding the logger output (e.g. parsing messages error).
 	// NOTE(remy): this should probably be dropped and use a throttler logger, see
@@ -230,6 +238,8 @@ func NewServer(aggregator *aggregator.BufferedAggregator, extraTags []string) (*
 		eolTerminationEnabled:     config.Datadog.GetBool("dogstatsd_eol_required"),
 		telemetryEnabled:          telemetry_utils.IsEnabled(),
 		entityIDPrecedenceEnabled: entityIDPrecedenceEnabled,
+		timestampMaxAge:           config.Datadog.GetDuration("dogstatsd_timestamp_max_age") * time.Second,
+		timestampMaxFuture:        config.Datadog.GetDuration("dogstatsd_timestamp_max_future") * time.Second,
 		disableVerboseLogs:        config.Datadog.GetBool("dogstatsd_disable_verbose_logs"),
 		Debug: &dsdServerDebug{
 			Stats: make(map[ckey.ContextKey]metricStat),
@@ -464,6 +474,11 @@ func (s *Server) parseMetricMessage(metricSamples []metrics.MetricSample, parser
 		tlmProcessed.IncWithTags(tlmProcessedErrorTags)
 		return metricSamples, err
 	}
+	if !sample.timestamp.IsZero() {
+		if err := s.checkTimestamp(sample.timestamp); err != nil {
+			return metricSamples, err
+		}
+	}
 	if s.mapper != nil {
 		mapResult := s.mapper.Map(sample.name)
 		if mapResult != nil {
@@ -492,6 +507,23 @@ func (s *Server) parseMetricMessage(metricSamples []metrics.MetricSample, parser
 	return metricSamples, nil
 }
 
+// checkTimestamp returns an error if the timestamp set by the client is too old
+// or too far in the future to be aggregated.
+func (s *Server) checkTimestamp(timestamp time.Time) error {
+	now := time.Now()
+	if timestamp.Before(now.Add(-s.timestampMaxAge)) {
+		dogstatsdMetricTimestampRejected.Add(1)
+		tlmMetricTimestampRejected.Inc("too_old")
+		return fmt.Errorf("timestamp %d is more than %s in the past", timestamp.Unix(), s.timestampMaxAge)
+	}
+	if timestamp.After(now.Add(s.timestampMaxFuture)) {
+		dogstatsdMetricTimestampRejected.Add(1)
+		tlmMetricTimestampRejected.Inc("in_future")
+		return fmt.Errorf("timestamp %d is more than %s in the future", timestamp.Unix(), s.timestampMaxFuture)
+	}
+	return nil
+}
+
 func (s *Server) parseEventMessage(parser *parser, message []byte, origin string) (*metrics.Event, error) {
 	sample, err := parser.parseEvent(message)
 	if err != nil {
@@ -516,6 +516,56 @@ func TestE2EParsing(t *testing.T) {
 	}
 }
 
+func TestTimestampedMetrics(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+
+	agg := mockAggregator()
+	metricOut, _, _ := agg.GetBufferedChannels()
+	metricWithTsOut := agg.GetBufferedMetricsWithTsChannel()
+	s, err := NewServer(agg, nil)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	// samples timestamped by the client are sent with their timestamp
+	timestamp := time.Now().Add(-5 * time.Minute).Unix()
+	conn.Write([]byte(fmt.Sprintf("daemon:666|g|#foo:bar|T%d\ndaemon:666|g|#foo:bar", timestamp)))
+	select {
+	case res := <-metricWithTsOut:
+		require.Len(t, res, 1)
+		assert.Equal(t, "daemon", res[0].Name)
+		assert.Equal(t, float64(timestamp*int64(time.Second)), res[0].Timestamp)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+	select {
+	case res := <-metricOut:
+		require.Len(t, res, 1)
+		assert.Equal(t, 0.0, res[0].Timestamp)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+
+	// samples too old or too far in the future are rejected
+	rejected := dogstatsdMetricTimestampRejected.Value()
+	conn.Write([]byte(fmt.Sprintf("daemon:666|g|T%d\ndaemon:666|g|T%d\ndaemon:666|g|T%d",
+		time.Now().Add(-2*time.Hour).Unix(), time.Now().Add(time.Hour).Unix(), timestamp)))
+	select {
+	case res := <-metricWithTsOut:
+		require.Len(t, res, 1)
+		assert.Equal(t, float64(timestamp*int64(time.Second)), res[0].Timestamp)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+	assert.Equal(t, rejected+2, dogstatsdMetricTimestampRejected.Value())
+}
+
 func TestExtraTags(t *testing.T) {
 	port, err := getAvailableUDPPort()
 	require.NoError(t, err)
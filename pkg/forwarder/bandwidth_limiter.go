@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"sync"
+	"time"
+)
+
+// bandwidthLimiter is a token bucket limiting the bandwidth used to send the
+// transactions of a domain. The bucket is refilled at `bytesPerSecond` and holds
+// up to `flushInterval` worth of bandwidth, so that the transactions retried at
+// every flush interval can be sent at once.
+type bandwidthLimiter struct {
+	domain         string
+	bytesPerSecond float64
+	capacity       float64
+
+	m      sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newBandwidthLimiter(domain string, bytesPerSecond int64) *bandwidthLimiter {
+	capacity := float64(bytesPerSecond) * flushInterval.Seconds()
+	return &bandwidthLimiter{
+		domain:         domain,
+		bytesPerSecond: float64(bytesPerSecond),
+		capacity:       capacity,
+		tokens:         capacity,
+		last:           time.Now(),
+		now:            time.Now,
+	}
+}
+
+// reserve returns the amount of the bucket kept for the transactions of a higher
+// priority: the transactions with a normal priority are only sent while the bucket
+// is filled above a quarter of its capacity and the ones with a low priority while
+// it is filled above half of its capacity.
+func (l *bandwidthLimiter) reserve(priority TransactionPriority) float64 {
+	switch {
+	case priority >= TransactionPriorityHigh:
+		return 0
+	case priority == TransactionPriorityNormal:
+		return l.capacity / 4
+	default:
+		return l.capacity / 2
+	}
+}
+
+// allow returns whether a transaction of `size` bytes can be sent now, in which case
+// its size is taken from the bucket. The bucket can go into debt so that the payloads
+// larger than its capacity are eventually sent.
+func (l *bandwidthLimiter) allow(size int, priority TransactionPriority) bool {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	now := l.now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.bytesPerSecond
+		if l.tokens > l.capacity {
+			l.tokens = l.capacity
+		}
+	}
+	l.last = now
+
+	if l.tokens <= l.reserve(priority) {
+		return false
+	}
+	l.tokens -= float64(size)
+	return true
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBandwidthLimiter(bytesPerSecond int64) (*bandwidthLimiter, *time.Time) {
+	now := time.Now()
+	l := newBandwidthLimiter("test", bytesPerSecond)
+	l.last = now
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestBandwidthLimiter(t *testing.T) {
+	// the capacity of the bucket is 500 bytes
+	l, now := newTestBandwidthLimiter(100)
+
+	assert.True(t, l.allow(300, TransactionPriorityHigh))
+	assert.True(t, l.allow(300, TransactionPriorityHigh))
+	// the bucket is in debt
+	assert.False(t, l.allow(1, TransactionPriorityHigh))
+
+	*now = now.Add(time.Second)
+	assert.False(t, l.allow(1, TransactionPriorityHigh))
+	*now = now.Add(time.Second)
+	assert.True(t, l.allow(1000, TransactionPriorityHigh))
+
+	// the bucket is refilled up to its capacity
+	*now = now.Add(time.Hour)
+	assert.True(t, l.allow(500, TransactionPriorityHigh))
+	assert.False(t, l.allow(1, TransactionPriorityHigh))
+}
+
+func TestBandwidthLimiterPriorities(t *testing.T) {
+	// the capacity of the bucket is 400 bytes
+	l, _ := newTestBandwidthLimiter(80)
+
+	assert.True(t, l.allow(150, TransactionPriorityLow))
+	assert.True(t, l.allow(100, TransactionPriorityLow))
+	// 150 bytes left: only the normal and high priorities can be sent
+	assert.False(t, l.allow(10, TransactionPriorityLow))
+	assert.True(t, l.allow(100, TransactionPriorityNormal))
+	// 50 bytes left: only the high priority can be sent
+	assert.False(t, l.allow(10, TransactionPriorityLow))
+	assert.False(t, l.allow(10, TransactionPriorityNormal))
+	assert.True(t, l.allow(50, TransactionPriorityHigh))
+	assert.False(t, l.allow(10, TransactionPriorityHigh))
+}
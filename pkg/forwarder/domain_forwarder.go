@@ -17,6 +17,9 @@ import (
 var (
 	chanBufferSize = 100
 	flushInterval  = 5 * time.Second
+	// throttledRetryInterval is the interval at which the transactions throttled by the
+	// bandwidth limit are sent again, at most chanBufferSize of them are waiting
+	throttledRetryInterval = time.Second
 )
 
 // domainForwarder is in charge of sending Transactions to Datadog backend over
@@ -29,6 +32,8 @@ type domainForwarder struct {
 	highPrio                  chan Transaction // use to receive new transactions
 	lowPrio                   chan Transaction // use to retry transactions
 	requeuedTransaction       chan Transaction
+	throttledTransaction      chan Transaction
+	throttledTransactions     []Transaction // waiting for bandwidth, only used by handleFailedTransactions
 	stopRetry                 chan bool
 	stopConnectionReset       chan bool
 	workers                   []*Worker
@@ -38,6 +43,7 @@ type domainForwarder struct {
 	m                         sync.Mutex // To control Start/Stop races
 	transactionPrioritySorter transactionPrioritySorter
 	blockedList               *blockedEndpoints
+	bandwidthLimiter          *bandwidthLimiter // nil when the bandwidth is not limited
 }
 
 func newDomainForwarder(
@@ -134,16 +140,57 @@ func (f *domainForwarder) requeueTransaction(t Transaction) {
 	tlmTxRetryQueueSize.Set(float64(retryQueueSize), f.domain)
 }
 
+// addThrottledTransaction holds a transaction throttled by the bandwidth limit until it
+// is sent again. The transaction with the lowest priority is dropped when too many of
+// them are waiting.
+func (f *domainForwarder) addThrottledTransaction(t Transaction) {
+	f.throttledTransactions = append(f.throttledTransactions, t)
+	if len(f.throttledTransactions) <= chanBufferSize {
+		return
+	}
+	f.transactionPrioritySorter.Sort(f.throttledTransactions)
+	dropped := f.throttledTransactions[len(f.throttledTransactions)-1]
+	f.throttledTransactions = f.throttledTransactions[:len(f.throttledTransactions)-1]
+
+	transactionEndpointName := dropped.GetEndpointName()
+	transactionsDroppedByEndpoint.Add(transactionEndpointName, 1)
+	transactionsDropped.Add(1)
+	tlmTxDropped.Inc(f.domain, transactionEndpointName)
+	log.Errorf("Dropped a transaction for endpoint '%s' because too many transactions are waiting for the bandwidth limit", dropped.GetTarget())
+}
+
+// retryThrottledTransactions sends the transactions throttled by the bandwidth limit to
+// the workers again, by priority. They are not counted as retried.
+func (f *domainForwarder) retryThrottledTransactions() {
+	f.transactionPrioritySorter.Sort(f.throttledTransactions)
+	for i, t := range f.throttledTransactions {
+		select {
+		case f.lowPrio <- t:
+		default:
+			// the workers are too busy, the remaining transactions wait for the next retry
+			f.throttledTransactions = append(f.throttledTransactions[:0], f.throttledTransactions[i:]...)
+			return
+		}
+	}
+	f.throttledTransactions = f.throttledTransactions[:0]
+}
+
 func (f *domainForwarder) handleFailedTransactions() {
 	ticker := time.NewTicker(flushInterval)
+	throttledTicker := time.NewTicker(throttledRetryInterval)
 	for {
 		select {
 		case tickTime := <-ticker.C:
 			f.retryTransactions(tickTime)
 		case t := <-f.requeuedTransaction:
 			f.requeueTransaction(t)
+		case <-throttledTicker.C:
+			f.retryThrottledTransactions()
+		case t := <-f.throttledTransaction:
+			f.addThrottledTransaction(t)
 		case <-f.stopRetry:
 			ticker.Stop()
+			throttledTicker.Stop()
 			return
 		}
 	}
@@ -171,6 +218,8 @@ func (f *domainForwarder) init() {
 	f.highPrio = make(chan Transaction, chanBufferSize)
 	f.lowPrio = make(chan Transaction, chanBufferSize)
 	f.requeuedTransaction = make(chan Transaction, chanBufferSize)
+	f.throttledTransaction = make(chan Transaction, chanBufferSize)
+	f.throttledTransactions = nil
 	f.stopRetry = make(chan bool)
 	f.stopConnectionReset = make(chan bool)
 	f.workers = []*Worker{}
@@ -191,6 +240,8 @@ func (f *domainForwarder) Start() error {
 
 	for i := 0; i < f.numberOfWorkers; i++ {
 		w := NewWorker(f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList)
+		w.bandwidthLimiter = f.bandwidthLimiter
+		w.throttledChan = f.throttledTransaction
 		w.Start()
 		f.workers = append(f.workers, w)
 	}
@@ -225,6 +276,7 @@ func (f *domainForwarder) Stop(purgeHighPrio bool) {
 	close(f.highPrio)
 	close(f.lowPrio)
 	close(f.requeuedTransaction)
+	close(f.throttledTransaction)
 	log.Info("domainForwarder stopped")
 	f.internalState = Stopped
 }
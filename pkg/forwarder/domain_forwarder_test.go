@@ -143,6 +143,40 @@ func TestRetryTransactions(t *testing.T) {
 	assert.Equal(t, int64(1), transactionsDropped.Value())
 }
 
+func TestRetryThrottledTransactions(t *testing.T) {
+	defer func(size int) { chanBufferSize = size }(chanBufferSize)
+	chanBufferSize = 2
+	forwarder := newDomainForwarderForTest(0)
+	forwarder.init()
+	droppedBefore := transactionsDropped.Value()
+
+	low := NewHTTPTransaction()
+	low.priority = TransactionPriorityLow
+	normal := NewHTTPTransaction()
+	high := NewHTTPTransaction()
+	high.priority = TransactionPriorityHigh
+
+	// the transaction with the lowest priority is dropped when too many are waiting
+	forwarder.addThrottledTransaction(low)
+	forwarder.addThrottledTransaction(normal)
+	forwarder.addThrottledTransaction(high)
+	assert.Equal(t, []Transaction{high, normal}, forwarder.throttledTransactions)
+	assert.Equal(t, int64(1), transactionsDropped.Value()-droppedBefore)
+
+	// the throttled transactions are sent to the workers again without going through
+	// the retry queue, the ones not fitting wait for the next retry
+	forwarder.lowPrio <- NewHTTPTransaction()
+	forwarder.retryThrottledTransactions()
+	requireLenForwarderRetryQueue(t, forwarder, 0)
+	assert.Equal(t, []Transaction{normal}, forwarder.throttledTransactions)
+	<-forwarder.lowPrio
+	assert.Equal(t, high, <-forwarder.lowPrio)
+
+	forwarder.retryThrottledTransactions()
+	assert.Len(t, forwarder.throttledTransactions, 0)
+	assert.Equal(t, normal, <-forwarder.lowPrio)
+}
+
 func TestForwarderRetry(t *testing.T) {
 	forwarder := newDomainForwarderForTest(0)
 	forwarder.Start()
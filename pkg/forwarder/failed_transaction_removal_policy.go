@@ -164,7 +164,8 @@ func (p *failedTransactionRemovalPolicy) getRetryFiles(folder string) ([]string,
 	}
 	var files []string
 	for _, entry := range entries {
-		if entry.Mode().IsRegular() && filepath.Ext(entry.Name()) == retryTransactionsExtension {
+		ext := filepath.Ext(entry.Name())
+		if entry.Mode().IsRegular() && (ext == retryTransactionsExtension || ext == quarantinedRetryFileExtension) {
 			files = append(files, path.Join(folder, entry.Name()))
 		}
 	}
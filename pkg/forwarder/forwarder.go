@@ -186,6 +186,10 @@ type Options struct {
 	KeysPerDomain                  map[string][]string
 	ConnectionResetInterval        time.Duration
 	CompletionHandler              HTTPCompletionHandler
+	// PriorityClasses are the priorities of the payload kinds, overriding the default ones
+	PriorityClasses map[string]TransactionPriority
+	// BandwidthLimits are the bandwidth limits in bytes per second of the domains
+	BandwidthLimits map[string]int64
 }
 
 // SetFeature sets forwarder features in a feature set
@@ -227,6 +231,8 @@ func NewOptions(keysPerDomain map[string][]string) *Options {
 		APIKeyValidationInterval:       time.Duration(validationInterval) * time.Minute,
 		KeysPerDomain:                  keysPerDomain,
 		ConnectionResetInterval:        time.Duration(config.Datadog.GetInt("forwarder_connection_reset_interval")) * time.Second,
+		PriorityClasses:                priorityClassesFromConfig(),
+		BandwidthLimits:                bandwidthLimitsFromConfig(),
 	}
 
 	if config.Datadog.IsSet(forwarderRetryQueueMaxSizeKey) {
@@ -266,6 +272,7 @@ type DefaultForwarder struct {
 	m                sync.Mutex // To control Start/Stop races
 
 	completionHandler HTTPCompletionHandler
+	priorityClasses   map[string]TransactionPriority
 }
 
 type sortByCreatedTimeAndPriority struct {
@@ -305,6 +312,7 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 			validationInterval:    options.APIKeyValidationInterval,
 		},
 		completionHandler: options.CompletionHandler,
+		priorityClasses:   options.PriorityClasses,
 	}
 	var optionalRemovalPolicy *failedTransactionRemovalPolicy
 	storageMaxSize := config.Datadog.GetInt64("forwarder_storage_max_size_in_bytes")
@@ -336,8 +344,8 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	domainForwarderSort := sortByCreatedTimeAndPriority{highPriorityFirst: true}
 	transactionContainerSort := sortByCreatedTimeAndPriority{highPriorityFirst: false}
 
-	for domain, keys := range options.KeysPerDomain {
-		domain, _ := config.AddAgentVersionToDomain(domain, "app")
+	for configDomain, keys := range options.KeysPerDomain {
+		domain, _ := config.AddAgentVersionToDomain(configDomain, "app")
 		if keys == nil || len(keys) == 0 {
 			log.Errorf("No API keys for domain '%s', dropping domain ", domain)
 		} else {
@@ -366,6 +374,10 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 				options.NumberOfWorkers,
 				options.ConnectionResetInterval,
 				domainForwarderSort)
+			if limit := getBandwidthLimit(options.BandwidthLimits, configDomain); limit > 0 {
+				log.Infof("Limiting the bandwidth used to send to '%s' to %d bytes per second", configDomain, limit)
+				f.domainForwarders[domain].bandwidthLimiter = newBandwidthLimiter(domain, limit)
+			}
 		}
 	}
 
@@ -477,6 +489,12 @@ func (f *DefaultForwarder) createHTTPTransactions(endpoint endpoint, payloads Pa
 	return f.createAdvancedHTTPTransactions(endpoint, payloads, apiKeyInQueryString, extra, TransactionPriorityNormal, true)
 }
 
+// createHTTPTransactionsOfKind creates the transactions of a payload kind, with the
+// priority class of the kind.
+func (f *DefaultForwarder) createHTTPTransactionsOfKind(kind string, endpoint endpoint, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
+	return f.createAdvancedHTTPTransactions(endpoint, payloads, apiKeyInQueryString, extra, f.priorityOf(kind), true)
+}
+
 func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint endpoint, payloads Payloads, apiKeyInQueryString bool, extra http.Header, priority TransactionPriority, storableOnDisk bool) []*HTTPTransaction {
 	transactions := make([]*HTTPTransaction, 0, len(payloads)*len(f.keysPerDomains))
 	allowArbitraryTags := config.Datadog.GetBool("allow_arbitrary_tags")
@@ -534,25 +552,25 @@ func (f *DefaultForwarder) sendHTTPTransactions(transactions []*HTTPTransaction)
 
 // SubmitSeries will send a series type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitSeries(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(seriesEndpoint, payload, false, extra)
+	transactions := f.createHTTPTransactionsOfKind(payloadKindSeries, seriesEndpoint, payload, false, extra)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitEvents will send an event type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitEvents(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(eventsEndpoint, payload, false, extra)
+	transactions := f.createHTTPTransactionsOfKind(payloadKindEvents, eventsEndpoint, payload, false, extra)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitServiceChecks will send a service check type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitServiceChecks(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(serviceChecksEndpoint, payload, false, extra)
+	transactions := f.createHTTPTransactionsOfKind(payloadKindCheckRuns, serviceChecksEndpoint, payload, false, extra)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitSketchSeries will send payloads to Datadog backend - PROTOTYPE FOR PERCENTILE
 func (f *DefaultForwarder) SubmitSketchSeries(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(sketchSeriesEndpoint, payload, true, extra)
+	transactions := f.createHTTPTransactionsOfKind(payloadKindSketches, sketchSeriesEndpoint, payload, true, extra)
 	return f.sendHTTPTransactions(transactions)
 }
 
@@ -562,7 +580,7 @@ func (f *DefaultForwarder) SubmitHostMetadata(payload Payloads, extra http.Heade
 		func(endpoint endpoint, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
 			// Host metadata contains the API KEY and should not be stored on disk.
 			storableOnDisk := false
-			return f.createAdvancedHTTPTransactions(endpoint, payloads, apiKeyInQueryString, extra, TransactionPriorityHigh, storableOnDisk)
+			return f.createAdvancedHTTPTransactions(endpoint, payloads, apiKeyInQueryString, extra, f.priorityOf(payloadKindHostMetadata), storableOnDisk)
 		})
 }
 
@@ -572,32 +590,38 @@ func (f *DefaultForwarder) SubmitAgentChecksMetadata(payload Payloads, extra htt
 		func(endpoint endpoint, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
 			// Agentchecks metadata contains the API KEY and should not be stored on disk.
 			storableOnDisk := false
-			return f.createAdvancedHTTPTransactions(endpoint, payloads, apiKeyInQueryString, extra, TransactionPriorityNormal, storableOnDisk)
+			return f.createAdvancedHTTPTransactions(endpoint, payloads, apiKeyInQueryString, extra, f.priorityOf(payloadKindMetadata), storableOnDisk)
 		})
 }
 
 // SubmitMetadata will send a metadata type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitMetadata(payload Payloads, extra http.Header) error {
-	return f.submitV1IntakeWithTransactionsFactory(payload, extra, f.createHTTPTransactions)
+	return f.submitV1IntakeWithTransactionsFactory(payload, extra,
+		func(endpoint endpoint, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
+			return f.createHTTPTransactionsOfKind(payloadKindMetadata, endpoint, payloads, apiKeyInQueryString, extra)
+		})
 }
 
 // SubmitV1Series will send timeserie to v1 endpoint (this will be remove once
 // the backend handles v2 endpoints).
 func (f *DefaultForwarder) SubmitV1Series(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(v1SeriesEndpoint, payload, true, extra)
+	transactions := f.createHTTPTransactionsOfKind(payloadKindSeries, v1SeriesEndpoint, payload, true, extra)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitV1CheckRuns will send service checks to v1 endpoint (this will be removed once
 // the backend handles v2 endpoints).
 func (f *DefaultForwarder) SubmitV1CheckRuns(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(v1CheckRunsEndpoint, payload, true, extra)
+	transactions := f.createHTTPTransactionsOfKind(payloadKindCheckRuns, v1CheckRunsEndpoint, payload, true, extra)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitV1Intake will send payloads to the universal `/intake/` endpoint used by Agent v.5
 func (f *DefaultForwarder) SubmitV1Intake(payload Payloads, extra http.Header) error {
-	return f.submitV1IntakeWithTransactionsFactory(payload, extra, f.createHTTPTransactions)
+	return f.submitV1IntakeWithTransactionsFactory(payload, extra,
+		func(endpoint endpoint, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
+			return f.createHTTPTransactionsOfKind(payloadKindMetadata, endpoint, payloads, apiKeyInQueryString, extra)
+		})
 }
 
 func (f *DefaultForwarder) submitV1IntakeWithTransactionsFactory(
@@ -616,27 +640,27 @@ func (f *DefaultForwarder) submitV1IntakeWithTransactionsFactory(
 
 // SubmitProcessChecks sends process checks
 func (f *DefaultForwarder) SubmitProcessChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.submitProcessLikePayload(processesEndpoint, payload, extra, true)
+	return f.submitProcessLikePayload(payloadKindProcesses, processesEndpoint, payload, extra, true)
 }
 
 // SubmitRTProcessChecks sends real time process checks
 func (f *DefaultForwarder) SubmitRTProcessChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.submitProcessLikePayload(rtProcessesEndpoint, payload, extra, false)
+	return f.submitProcessLikePayload(payloadKindProcesses, rtProcessesEndpoint, payload, extra, false)
 }
 
 // SubmitContainerChecks sends container checks
 func (f *DefaultForwarder) SubmitContainerChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.submitProcessLikePayload(containerEndpoint, payload, extra, true)
+	return f.submitProcessLikePayload(payloadKindProcesses, containerEndpoint, payload, extra, true)
 }
 
 // SubmitRTContainerChecks sends real time container checks
 func (f *DefaultForwarder) SubmitRTContainerChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.submitProcessLikePayload(rtContainerEndpoint, payload, extra, false)
+	return f.submitProcessLikePayload(payloadKindProcesses, rtContainerEndpoint, payload, extra, false)
 }
 
 // SubmitConnectionChecks sends connection checks
 func (f *DefaultForwarder) SubmitConnectionChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.submitProcessLikePayload(connectionsEndpoint, payload, extra, true)
+	return f.submitProcessLikePayload(payloadKindProcesses, connectionsEndpoint, payload, extra, true)
 }
 
 // SubmitOrchestratorChecks sends orchestrator checks
@@ -654,11 +678,11 @@ func (f *DefaultForwarder) SubmitOrchestratorChecks(payload Payloads, extra http
 		transactionsIntakeNode.Add(1)
 	}
 
-	return f.submitProcessLikePayload(orchestratorEndpoint, payload, extra, true)
+	return f.submitProcessLikePayload(payloadKindOrchestrator, orchestratorEndpoint, payload, extra, true)
 }
 
-func (f *DefaultForwarder) submitProcessLikePayload(ep endpoint, payload Payloads, extra http.Header, retryable bool) (chan Response, error) {
-	transactions := f.createHTTPTransactions(ep, payload, false, extra)
+func (f *DefaultForwarder) submitProcessLikePayload(kind string, ep endpoint, payload Payloads, extra http.Header, retryable bool) (chan Response, error) {
+	transactions := f.createHTTPTransactionsOfKind(kind, ep, payload, false, extra)
 	results := make(chan Response, len(transactions))
 	internalResults := make(chan Response, len(transactions))
 	expectedResponses := len(transactions)
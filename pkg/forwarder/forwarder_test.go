@@ -54,6 +54,46 @@ func TestNewDefaultForwarder(t *testing.T) {
 	assert.Equal(t, forwarder.State(), forwarder.internalState)
 }
 
+func TestNewOptionsPriorityClassesAndBandwidthLimits(t *testing.T) {
+	config.Datadog.Set("forwarder_priority_classes", map[string]interface{}{
+		"series":       "high",
+		"orchestrator": "low",
+		"unknown":      "high",
+		"events":       "invalid",
+	})
+	config.Datadog.Set("forwarder_bandwidth_limits", map[string]interface{}{
+		testDomain:    1000,
+		"datadog.bar": "invalid",
+	})
+	defer config.Datadog.Set("forwarder_priority_classes", map[string]string{})
+	defer config.Datadog.Set("forwarder_bandwidth_limits", map[string]string{})
+
+	options := NewOptions(keysWithMultipleDomains)
+	assert.Equal(t, map[string]TransactionPriority{
+		payloadKindSeries:       TransactionPriorityHigh,
+		payloadKindOrchestrator: TransactionPriorityLow,
+	}, options.PriorityClasses)
+	assert.Equal(t, map[string]int64{testDomain: 1000}, options.BandwidthLimits)
+
+	forwarder := NewDefaultForwarder(options)
+	assert.Equal(t, TransactionPriorityHigh, forwarder.priorityOf(payloadKindSeries))
+	assert.Equal(t, TransactionPriorityLow, forwarder.priorityOf(payloadKindOrchestrator))
+	assert.Equal(t, TransactionPriorityHigh, forwarder.priorityOf(payloadKindHostMetadata))
+	assert.Equal(t, TransactionPriorityNormal, forwarder.priorityOf(payloadKindEvents))
+
+	require.Len(t, forwarder.domainForwarders, 2)
+	limiter := forwarder.domainForwarders[testVersionDomain].bandwidthLimiter
+	require.NotNil(t, limiter)
+	assert.Equal(t, float64(1000), limiter.bytesPerSecond)
+	assert.Nil(t, forwarder.domainForwarders["datadog.bar"].bandwidthLimiter)
+
+	transactions := forwarder.createHTTPTransactionsOfKind(payloadKindSeries, seriesEndpoint, Payloads{&[]byte{}}, false, nil)
+	require.Len(t, transactions, 3)
+	for _, transaction := range transactions {
+		assert.Equal(t, TransactionPriorityHigh, transaction.GetPriority())
+	}
+}
+
 func TestFeature(t *testing.T) {
 	var featureSet Features
 
@@ -512,7 +552,7 @@ func TestProcessLikePayloadResponseTimeout(t *testing.T) {
 	transactions := f.createHTTPTransactions(metadataEndpoint, payload, false, headers)
 	require.Len(t, transactions, 1)
 
-	responses, err := f.submitProcessLikePayload(metadataEndpoint, payload, headers, true)
+	responses, err := f.submitProcessLikePayload(payloadKindProcesses, metadataEndpoint, payload, headers, true)
 	require.NoError(t, err)
 
 	_, ok := <-responses
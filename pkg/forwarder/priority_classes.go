@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Kinds of payloads whose priority class can be configured with `forwarder_priority_classes`
+const (
+	payloadKindSeries       = "series"
+	payloadKindSketches     = "sketches"
+	payloadKindCheckRuns    = "check_runs"
+	payloadKindEvents       = "events"
+	payloadKindMetadata     = "metadata"
+	payloadKindHostMetadata = "host_metadata"
+	payloadKindProcesses    = "processes"
+	payloadKindOrchestrator = "orchestrator"
+)
+
+// defaultPriorityClasses are the priority classes of the payload kinds not set in
+// the configuration, the other kinds have a normal priority.
+var defaultPriorityClasses = map[string]TransactionPriority{
+	payloadKindHostMetadata: TransactionPriorityHigh,
+}
+
+func isPayloadKind(kind string) bool {
+	switch kind {
+	case payloadKindSeries, payloadKindSketches, payloadKindCheckRuns, payloadKindEvents,
+		payloadKindMetadata, payloadKindHostMetadata, payloadKindProcesses, payloadKindOrchestrator:
+		return true
+	}
+	return false
+}
+
+// priorityClassesFromConfig returns the priority classes of the payload kinds set in
+// `forwarder_priority_classes`, the invalid entries are skipped.
+func priorityClassesFromConfig() map[string]TransactionPriority {
+	classes := make(map[string]TransactionPriority)
+	for kind, name := range config.Datadog.GetStringMapString("forwarder_priority_classes") {
+		if !isPayloadKind(kind) {
+			log.Warnf("Unknown payload kind %q in 'forwarder_priority_classes', ignoring it", kind)
+			continue
+		}
+		priority, err := parseTransactionPriority(name)
+		if err != nil {
+			log.Warnf("Invalid priority class for %q in 'forwarder_priority_classes': %v", kind, err)
+			continue
+		}
+		classes[kind] = priority
+	}
+	return classes
+}
+
+// bandwidthLimitsFromConfig returns the bandwidth limits in bytes per second of the
+// domains set in `forwarder_bandwidth_limits`, the invalid entries are skipped.
+func bandwidthLimitsFromConfig() map[string]int64 {
+	limits := make(map[string]int64)
+	for domain, value := range config.Datadog.GetStringMapString("forwarder_bandwidth_limits") {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit <= 0 {
+			log.Warnf("Invalid bandwidth limit %q for %q in 'forwarder_bandwidth_limits', it must be a positive number of bytes per second", value, domain)
+			continue
+		}
+		limits[strings.TrimSuffix(strings.ToLower(domain), "/")] = limit
+	}
+	return limits
+}
+
+// getBandwidthLimit returns the bandwidth limit of a domain, 0 when it is not limited.
+func getBandwidthLimit(limits map[string]int64, domain string) int64 {
+	return limits[strings.TrimSuffix(strings.ToLower(domain), "/")]
+}
+
+// priorityOf returns the priority class of a payload kind.
+func (f *DefaultForwarder) priorityOf(kind string) TransactionPriority {
+	if priority, ok := f.priorityClasses[kind]; ok {
+		return priority
+	}
+	return defaultPriorityClasses[kind]
+}
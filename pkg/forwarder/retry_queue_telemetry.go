@@ -63,6 +63,13 @@ var (
 	transactionsDroppedCountTelemetry *counterExpvar
 	errorsCountTelemetry              *counterExpvar
 
+	transactionsDroppedByPriorityExpvar    = expvar.Map{}
+	transactionsDroppedByPriorityTelemetry = telemetry.NewCounter(
+		"transaction_container",
+		"transactions_dropped_by_priority",
+		[]string{"priority"},
+		"The number of transactions dropped because the retry queue is full, by priority")
+
 	fileStorageExpvar                     = expvar.Map{}
 	serializeCountTelemetry               *counterExpvar
 	deserializeCountTelemetry             *counterExpvar
@@ -73,6 +80,7 @@ var (
 	filesRemovedCountTelemetry            *counterExpvar
 	deserializeErrorsCountTelemetry       *counterExpvar
 	deserializeTransactionsCountTelemetry *counterExpvar
+	quarantinedFilesCountTelemetry        *counterExpvar
 )
 
 func init() {
@@ -119,6 +127,7 @@ func init() {
 		"errors_count",
 		"The number of errors",
 		&transactionContainerExpvar)
+	transactionContainerExpvar.Set("TransactionsDroppedByPriority", &transactionsDroppedByPriorityExpvar)
 
 	forwarderExpvars.Set("FileStorage", &fileStorageExpvar)
 	serializeCountTelemetry = newCounterExpvar(
@@ -166,6 +175,11 @@ func init() {
 		"deserialize_transactions_count",
 		"The number of transactions read from the disk",
 		&fileStorageExpvar)
+	quarantinedFilesCountTelemetry = newCounterExpvar(
+		"file_storage",
+		"quarantined_files_count",
+		"The number of encrypted files quarantined because they cannot be authenticated",
+		&fileStorageExpvar)
 }
 
 type failedTransactionRemovalPolicyTelemetry struct{}
@@ -199,6 +213,11 @@ func (transactionContainerTelemetry) addTransactionsDroppedCount(count int) {
 	transactionsDroppedCountTelemetry.add(float64(count))
 }
 
+func (transactionContainerTelemetry) addTransactionDroppedByPriority(priority TransactionPriority) {
+	transactionsDroppedByPriorityExpvar.Add(priority.String(), 1)
+	transactionsDroppedByPriorityTelemetry.Inc(priority.String())
+}
+
 func (transactionContainerTelemetry) incErrorsCount() {
 	errorsCountTelemetry.add(1)
 }
@@ -241,6 +260,10 @@ func (transactionsFileStorageTelemetry) addDeserializeTransactionsCount(count in
 	deserializeTransactionsCountTelemetry.add(float64(count))
 }
 
+func (transactionsFileStorageTelemetry) addQuarantinedFilesCount() {
+	quarantinedFilesCountTelemetry.add(1)
+}
+
 func toCamelCase(s string) string {
 	parts := strings.Split(s, "_")
 	var camelCase string
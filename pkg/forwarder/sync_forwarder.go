@@ -112,27 +112,27 @@ func (f *SyncForwarder) SubmitAgentChecksMetadata(payload Payloads, extra http.H
 
 // SubmitProcessChecks sends process checks
 func (f *SyncForwarder) SubmitProcessChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.defaultForwarder.submitProcessLikePayload(processesEndpoint, payload, extra, true)
+	return f.defaultForwarder.submitProcessLikePayload(payloadKindProcesses, processesEndpoint, payload, extra, true)
 }
 
 // SubmitRTProcessChecks sends real time process checks
 func (f *SyncForwarder) SubmitRTProcessChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.defaultForwarder.submitProcessLikePayload(rtProcessesEndpoint, payload, extra, false)
+	return f.defaultForwarder.submitProcessLikePayload(payloadKindProcesses, rtProcessesEndpoint, payload, extra, false)
 }
 
 // SubmitContainerChecks sends container checks
 func (f *SyncForwarder) SubmitContainerChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.defaultForwarder.submitProcessLikePayload(containerEndpoint, payload, extra, true)
+	return f.defaultForwarder.submitProcessLikePayload(payloadKindProcesses, containerEndpoint, payload, extra, true)
 }
 
 // SubmitRTContainerChecks sends real time container checks
 func (f *SyncForwarder) SubmitRTContainerChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.defaultForwarder.submitProcessLikePayload(rtContainerEndpoint, payload, extra, false)
+	return f.defaultForwarder.submitProcessLikePayload(payloadKindProcesses, rtContainerEndpoint, payload, extra, false)
 }
 
 // SubmitConnectionChecks sends connection checks
 func (f *SyncForwarder) SubmitConnectionChecks(payload Payloads, extra http.Header) (chan Response, error) {
-	return f.defaultForwarder.submitProcessLikePayload(connectionsEndpoint, payload, extra, true)
+	return f.defaultForwarder.submitProcessLikePayload(payloadKindProcesses, connectionsEndpoint, payload, extra, true)
 }
 
 // SubmitOrchestratorChecks sends orchestrator checks
@@ -19,6 +19,7 @@ type testTransaction struct {
 	mock.Mock
 	assertClient bool
 	processed    chan bool
+	nonRetryable bool
 }
 
 func newTestTransaction() *testTransaction {
@@ -64,6 +65,10 @@ func (t *testTransaction) GetPayloadSize() int {
 	return t.Called().Get(0).(int)
 }
 
+func (t *testTransaction) IsRetryable() bool {
+	return !t.nonRetryable
+}
+
 func (t *testTransaction) SerializeTo(serializer *TransactionsSerializer) error {
 	return nil
 }
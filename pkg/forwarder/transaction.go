@@ -15,6 +15,7 @@ import (
 	"net/http"
 	"net/http/httptrace"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -37,6 +38,7 @@ var (
 	transactionsRetried                = expvar.Int{}
 	transactionsRetriedByEndpoint      = expvar.Map{}
 	transactionsRetryQueueSize         = expvar.Int{}
+	transactionsThrottled              = expvar.Int{}
 	transactionsSuccessByEndpoint      = expvar.Map{}
 	transactionsSuccessBytesByEndpoint = expvar.Map{}
 	transactionsSuccess                = expvar.Int{}
@@ -64,6 +66,8 @@ var (
 		[]string{"domain", "endpoint"}, "Transaction requeue count")
 	tlmTxRetried = telemetry.NewCounter("transactions", "retries",
 		[]string{"domain", "endpoint"}, "Transaction retry count")
+	tlmTxThrottled = telemetry.NewCounter("transactions", "throttled",
+		[]string{"domain", "endpoint", "priority"}, "Count of transactions delayed by the bandwidth limit")
 	tlmTxRetryQueueSize = telemetry.NewGauge("transactions", "retry_queue_size",
 		[]string{"domain"}, "Retry queue size")
 	tlmTxSuccessCount = telemetry.NewCounter("transactions", "success",
@@ -151,6 +155,7 @@ func initTransactionExpvars() {
 	transactionsExpvars.Set("Retried", &transactionsRetried)
 	transactionsExpvars.Set("RetriedByEndpoint", &transactionsRetriedByEndpoint)
 	transactionsExpvars.Set("RetryQueueSize", &transactionsRetryQueueSize)
+	transactionsExpvars.Set("Throttled", &transactionsThrottled)
 	transactionsExpvars.Set("SuccessByEndpoint", &transactionsSuccessByEndpoint)
 	transactionsExpvars.Set("SuccessBytesByEndpoint", &transactionsSuccessBytesByEndpoint)
 	transactionsExpvars.Set("Success", &transactionsSuccess)
@@ -166,11 +171,15 @@ func initTransactionExpvars() {
 }
 
 // TransactionPriority defines the priority of a transaction
-// Transactions with priority `TransactionPriorityNormal` are dropped from the retry queue
-// before dropping transactions with priority `TransactionPriorityHigh`.
+// Transactions with priority `TransactionPriorityLow` are dropped from the retry queue
+// before dropping transactions with priority `TransactionPriorityNormal`, which are
+// dropped before transactions with priority `TransactionPriorityHigh`.
 type TransactionPriority int
 
 const (
+	// TransactionPriorityLow defines a transaction with a low priority
+	TransactionPriorityLow TransactionPriority = -1
+
 	// TransactionPriorityNormal defines a transaction with a normal priority
 	TransactionPriorityNormal TransactionPriority = 0
 
@@ -178,6 +187,33 @@ const (
 	TransactionPriorityHigh TransactionPriority = 1
 )
 
+func (p TransactionPriority) String() string {
+	switch p {
+	case TransactionPriorityLow:
+		return "low"
+	case TransactionPriorityNormal:
+		return "normal"
+	case TransactionPriorityHigh:
+		return "high"
+	default:
+		return strconv.Itoa(int(p))
+	}
+}
+
+// parseTransactionPriority returns the priority named `low`, `normal` or `high`
+func parseTransactionPriority(name string) (TransactionPriority, error) {
+	switch strings.ToLower(name) {
+	case "low":
+		return TransactionPriorityLow, nil
+	case "normal":
+		return TransactionPriorityNormal, nil
+	case "high":
+		return TransactionPriorityHigh, nil
+	default:
+		return TransactionPriorityNormal, fmt.Errorf("unknown priority %q, must be `low`, `normal` or `high`", name)
+	}
+}
+
 // HTTPTransaction represents one Payload for one Endpoint on one Domain.
 type HTTPTransaction struct {
 	// Domain represents the domain target by the HTTPTransaction.
@@ -216,6 +252,7 @@ type Transaction interface {
 	GetPriority() TransactionPriority
 	GetEndpointName() string
 	GetPayloadSize() int
+	IsRetryable() bool
 
 	// This method serializes the transaction to `TransactionsSerializer`.
 	// It forces a new implementation of `Transaction` to define how to
@@ -272,6 +309,11 @@ func (t *HTTPTransaction) GetPayloadSize() int {
 	return 0
 }
 
+// IsRetryable returns whether the transaction can be retried.
+func (t *HTTPTransaction) IsRetryable() bool {
+	return t.retryable
+}
+
 // Process sends the Payload of the transaction to the right Endpoint and Domain.
 func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) error {
 	t.attemptHandler(t)
@@ -53,7 +53,13 @@ func buildTransactionContainer(
 		diskRatio := config.Datadog.GetFloat64("forwarder_storage_max_disk_ratio")
 
 		maxStorage := newForwarderMaxStorage(optionalDomainFolderPath, filesystem.NewDisk(), storageMaxSize, diskRatio)
-		storage, err = newTransactionsFileStorage(serializer, optionalDomainFolderPath, maxStorage, transactionsFileStorageTelemetry{})
+		var cipher *retryFileCipher
+		cipher, err = retryFileCipherFromConfig()
+		if err != nil {
+			err = fmt.Errorf("cannot set up the encryption of the retry files: %v", err)
+		} else {
+			storage, err = newTransactionsFileStorage(serializer, optionalDomainFolderPath, maxStorage, cipher, transactionsFileStorageTelemetry{})
+		}
 
 		// If the storage on disk cannot be used, log the error and continue.
 		// Returning `nil, err` would mean not using `TransactionContainer` and so not using `forwarder_retry_queue_payloads_max_size` config.
@@ -89,7 +95,9 @@ func newTransactionContainer(
 // 100*0.6=60 bytes must be flushed on disk.
 // The first 3 transactions are flushed to the disk as 10 + 20 + 30 >= 60
 // If disk serialization failed or is not enabled, remove old transactions such as
-// `currentMemSizeInBytes` <= `maxMemSizeInBytes`
+// `currentMemSizeInBytes` <= `maxMemSizeInBytes`. The transactions are removed by
+// priority class, the lowest first: when this would require removing transactions
+// with a higher priority than `t`, `t` is dropped instead.
 func (tc *transactionContainer) add(t Transaction) (int, error) {
 	tc.mutex.Lock()
 	defer tc.mutex.Unlock()
@@ -113,9 +121,17 @@ func (tc *transactionContainer) add(t Transaction) (int, error) {
 	payloadSizeInBytesToDrop := (tc.currentMemSizeInBytes + payloadSize) - tc.maxMemSizeInBytes
 	inMemTransactionDroppedCount := 0
 	if payloadSizeInBytesToDrop > 0 {
+		if tc.requiresEvictingHigherPriority(t.GetPriority(), payloadSizeInBytesToDrop) {
+			tc.telemetry.addTransactionsDroppedCount(1)
+			tc.telemetry.addTransactionDroppedByPriority(t.GetPriority())
+			return 1, diskErr
+		}
 		transactions := tc.extractTransactionsFromMemory(payloadSizeInBytesToDrop)
 		inMemTransactionDroppedCount = len(transactions)
 		tc.telemetry.addTransactionsDroppedCount(inMemTransactionDroppedCount)
+		for _, transaction := range transactions {
+			tc.telemetry.addTransactionDroppedByPriority(transaction.GetPriority())
+		}
 	}
 
 	tc.transactions = append(tc.transactions, t)
@@ -176,6 +192,21 @@ func (tc *transactionContainer) getMaxMemSizeInBytes() int {
 	return tc.maxMemSizeInBytes
 }
 
+// requiresEvictingHigherPriority returns whether freeing `payloadSizeInBytesToDrop`
+// bytes requires removing transactions with a higher priority than `priority`.
+func (tc *transactionContainer) requiresEvictingHigherPriority(priority TransactionPriority, payloadSizeInBytesToDrop int) bool {
+	sizeUpToPriority := 0
+	hasHigherPriority := false
+	for _, t := range tc.transactions {
+		if t.GetPriority() <= priority {
+			sizeUpToPriority += t.GetPayloadSize()
+		} else {
+			hasHigherPriority = true
+		}
+	}
+	return hasHigherPriority && sizeUpToPriority < payloadSizeInBytesToDrop
+}
+
 func (tc *transactionContainer) extractTransactionsForDisk(payloadSize int) [][]Transaction {
 	sizeInBytesToFlush := int(float64(tc.maxMemSizeInBytes) * tc.flushToStorageRatio)
 	var payloadsGroupToFlush [][]Transaction
@@ -102,6 +102,12 @@ func createTransactionWithPayloadSize(payloadSize int) *HTTPTransaction {
 	return tr
 }
 
+func createTransactionWithPayloadSizeAndPriority(payloadSize int, priority TransactionPriority) *HTTPTransaction {
+	tr := createTransactionWithPayloadSize(payloadSize)
+	tr.priority = priority
+	return tr
+}
+
 func assertPayloadSizeFromExtractTransactions(
 	a *assert.Assertions,
 	container *transactionContainer,
@@ -130,7 +136,45 @@ func newTransactionsFileStorageTest(a *assert.Assertions) (*transactionsFileStor
 			Total:     10000,
 		}}
 	maxStorage := newForwarderMaxStorage("", disk, 1000, 1)
-	s, err := newTransactionsFileStorage(NewTransactionsSerializer("", nil), path, maxStorage, transactionsFileStorageTelemetry{})
+	s, err := newTransactionsFileStorage(NewTransactionsSerializer("", nil), path, maxStorage, nil, transactionsFileStorageTelemetry{})
 	a.NoError(err)
 	return s, clean
 }
+
+func TestTransactionContainerEvictByPriority(t *testing.T) {
+	a := assert.New(t)
+	container := newTransactionContainer(createDropPrioritySorter(), nil, 50, 0.1, transactionContainerTelemetry{})
+
+	for _, priority := range []TransactionPriority{TransactionPriorityHigh, TransactionPriorityLow, TransactionPriorityNormal} {
+		dropCount, err := container.add(createTransactionWithPayloadSizeAndPriority(15, priority))
+		a.Equal(0, dropCount)
+		a.NoError(err)
+	}
+
+	// The low priority transaction is dropped first
+	dropCount, err := container.add(createTransactionWithPayloadSizeAndPriority(15, TransactionPriorityNormal))
+	a.Equal(1, dropCount)
+	a.NoError(err)
+	a.Equal(45, container.getCurrentMemSizeInBytes())
+
+	// Dropping a normal or high priority transaction to add a low priority one
+	// drops the low priority one instead
+	dropCount, err = container.add(createTransactionWithPayloadSizeAndPriority(10, TransactionPriorityLow))
+	a.Equal(1, dropCount)
+	a.NoError(err)
+	a.Equal(3, container.getTransactionCount())
+	a.Equal(45, container.getCurrentMemSizeInBytes())
+
+	// The oldest normal priority transaction is dropped
+	dropCount, err = container.add(createTransactionWithPayloadSizeAndPriority(10, TransactionPriorityHigh))
+	a.Equal(1, dropCount)
+	a.NoError(err)
+
+	transactions, err := container.extractTransactions()
+	a.NoError(err)
+	var priorities []TransactionPriority
+	for _, t := range transactions {
+		priorities = append(priorities, t.GetPriority())
+	}
+	a.ElementsMatch([]TransactionPriority{TransactionPriorityHigh, TransactionPriorityNormal, TransactionPriorityHigh}, priorities)
+}
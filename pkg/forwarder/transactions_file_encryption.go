@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// retryFileEncryptionKeySize is the size of the AES-256 keys
+const retryFileEncryptionKeySize = 32
+
+// retryFileEncryptionHeader starts the encrypted retry files, it identifies the
+// format and is authenticated with the content of the file.
+var retryFileEncryptionHeader = []byte("DDRETRY\x01")
+
+var errRetryFileAuthentication = errors.New("the retry file is not encrypted or was tampered with")
+
+// retryFileCipher encrypts and authenticates the retry files with AES-256-GCM. An
+// encrypted file is made of the header, a random nonce and the sealed content.
+type retryFileCipher struct {
+	aead cipher.AEAD
+}
+
+func newRetryFileCipher(key []byte) (*retryFileCipher, error) {
+	if len(key) != retryFileEncryptionKeySize {
+		return nil, fmt.Errorf("the encryption key must be %d bytes long, got %d bytes", retryFileEncryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &retryFileCipher{aead: aead}, nil
+}
+
+// retryFileCipherFromConfig returns the cipher of the key set in
+// `forwarder_storage_encryption_key` or stored in `forwarder_storage_encryption_key_file`,
+// as a base64 string. It returns nil when the encryption is disabled.
+func retryFileCipherFromConfig() (*retryFileCipher, error) {
+	encodedKey := config.Datadog.GetString("forwarder_storage_encryption_key")
+	if keyFile := config.Datadog.GetString("forwarder_storage_encryption_key_file"); keyFile != "" {
+		if encodedKey != "" {
+			return nil, fmt.Errorf("'forwarder_storage_encryption_key' and 'forwarder_storage_encryption_key_file' cannot be both set")
+		}
+		content, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the encryption key file: %v", err)
+		}
+		encodedKey = string(content)
+	}
+	encodedKey = strings.TrimSpace(encodedKey)
+	if encodedKey == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("the encryption key is not a valid base64 string: %v", err)
+	}
+	return newRetryFileCipher(key)
+}
+
+func (c *retryFileCipher) encrypt(content []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, len(retryFileEncryptionHeader)+len(nonce)+len(content)+c.aead.Overhead())
+	data = append(data, retryFileEncryptionHeader...)
+	data = append(data, nonce...)
+	return c.aead.Seal(data, nonce, content, retryFileEncryptionHeader), nil
+}
+
+// decrypt returns the content of an encrypted file, it returns
+// errRetryFileAuthentication if the file cannot be authenticated.
+func (c *retryFileCipher) decrypt(data []byte) ([]byte, error) {
+	headerSize := len(retryFileEncryptionHeader) + c.aead.NonceSize()
+	if len(data) < headerSize+c.aead.Overhead() || !bytes.HasPrefix(data, retryFileEncryptionHeader) {
+		return nil, errRetryFileAuthentication
+	}
+	nonce := data[len(retryFileEncryptionHeader):headerSize]
+	content, err := c.aead.Open(nil, nonce, data[headerSize:], retryFileEncryptionHeader)
+	if err != nil {
+		return nil, errRetryFileAuthentication
+	}
+	return content, nil
+}
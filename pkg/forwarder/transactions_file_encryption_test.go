@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func newTestRetryFileCipher(t *testing.T, seed byte) *retryFileCipher {
+	cipher, err := newRetryFileCipher(bytes.Repeat([]byte{seed}, retryFileEncryptionKeySize))
+	require.NoError(t, err)
+	return cipher
+}
+
+func TestRetryFileCipher(t *testing.T) {
+	cipher := newTestRetryFileCipher(t, 1)
+
+	content := []byte("transactions")
+	data, err := cipher.encrypt(content)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, retryFileEncryptionHeader))
+	assert.NotContains(t, string(data), string(content))
+
+	decrypted, err := cipher.decrypt(data)
+	require.NoError(t, err)
+	assert.Equal(t, content, decrypted)
+
+	// tampered content
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = cipher.decrypt(tampered)
+	assert.Equal(t, errRetryFileAuthentication, err)
+
+	// another key
+	_, err = newTestRetryFileCipher(t, 2).decrypt(data)
+	assert.Equal(t, errRetryFileAuthentication, err)
+
+	// not encrypted
+	_, err = cipher.decrypt(content)
+	assert.Equal(t, errRetryFileAuthentication, err)
+}
+
+func TestNewRetryFileCipherInvalidKey(t *testing.T) {
+	_, err := newRetryFileCipher([]byte("too short"))
+	assert.Error(t, err)
+}
+
+func TestRetryFileCipherFromConfig(t *testing.T) {
+	defer config.Datadog.Set("forwarder_storage_encryption_key", "")
+	defer config.Datadog.Set("forwarder_storage_encryption_key_file", "")
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, retryFileEncryptionKeySize))
+
+	cipher, err := retryFileCipherFromConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, cipher)
+
+	config.Datadog.Set("forwarder_storage_encryption_key", key)
+	cipher, err = retryFileCipherFromConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, cipher)
+
+	config.Datadog.Set("forwarder_storage_encryption_key", "not base64")
+	_, err = retryFileCipherFromConfig()
+	assert.Error(t, err)
+
+	dir, err := ioutil.TempDir("", "tests")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "forwarder_storage.key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(key+"\n"), 0600))
+	config.Datadog.Set("forwarder_storage_encryption_key_file", keyFile)
+	_, err = retryFileCipherFromConfig()
+	assert.Error(t, err, "both the key and the key file are set")
+
+	config.Datadog.Set("forwarder_storage_encryption_key", "")
+	cipher, err = retryFileCipherFromConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, cipher)
+}
@@ -19,13 +19,27 @@ import (
 )
 
 const retryTransactionsExtension = ".retry"
+const quarantinedRetryFileExtension = ".quarantine"
 const retryFileFormat = "2006_01_02__15_04_05_"
 
+// maxQuarantinedFiles is the number of quarantined files kept for investigation,
+// the oldest ones are removed beyond.
+const maxQuarantinedFiles = 10
+
+// quarantinedFile is a retry file which cannot be authenticated. Its size is
+// counted in the disk space used until it is removed.
+type quarantinedFile struct {
+	name string
+	size int64
+}
+
 type transactionsFileStorage struct {
 	serializer         *TransactionsSerializer
 	storagePath        string
 	maxStorage         *forwarderMaxStorage
+	cipher             *retryFileCipher // nil when the files are not encrypted
 	filenames          []string
+	quarantinedFiles   []quarantinedFile
 	currentSizeInBytes int64
 	telemetry          transactionsFileStorageTelemetry
 }
@@ -34,6 +48,7 @@ func newTransactionsFileStorage(
 	serializer *TransactionsSerializer,
 	storagePath string,
 	maxStorage *forwarderMaxStorage,
+	cipher *retryFileCipher,
 	telemetry transactionsFileStorageTelemetry) (*transactionsFileStorage, error) {
 
 	if err := os.MkdirAll(storagePath, 0700); err != nil {
@@ -44,6 +59,7 @@ func newTransactionsFileStorage(
 		serializer:  serializer,
 		storagePath: storagePath,
 		maxStorage:  maxStorage,
+		cipher:      cipher,
 		telemetry:   telemetry,
 	}
 
@@ -76,6 +92,11 @@ func (s *transactionsFileStorage) Serialize(transactions []Transaction) error {
 	if err != nil {
 		return err
 	}
+	if s.cipher != nil {
+		if bytes, err = s.cipher.encrypt(bytes); err != nil {
+			return fmt.Errorf("cannot encrypt the transactions: %v", err)
+		}
+	}
 	bufferSize := int64(len(bytes))
 
 	if err := s.makeRoomFor(bufferSize); err != nil {
@@ -112,6 +133,18 @@ func (s *transactionsFileStorage) Deserialize() ([]Transaction, error) {
 	path := s.filenames[index]
 	bytes, err := ioutil.ReadFile(path)
 
+	if err == nil && s.cipher != nil {
+		if bytes, err = s.cipher.decrypt(bytes); err != nil {
+			// Keep the file which cannot be authenticated for investigation.
+			s.telemetry.addQuarantinedFilesCount()
+			if errQuarantine := s.quarantineFileAt(index); errQuarantine != nil {
+				return nil, errQuarantine
+			}
+			log.Errorf("Cannot decrypt the retry file %s, it is quarantined: %v", path, err)
+			return nil, err
+		}
+	}
+
 	// Remove the file even in case of a read failure.
 	if errRemoveFile := s.removeFileAt(index); errRemoveFile != nil {
 		return nil, errRemoveFile
@@ -152,6 +185,14 @@ func (s *transactionsFileStorage) makeRoomFor(bufferSize int64) error {
 	if err != nil {
 		return err
 	}
+	// the quarantined files are removed before the transactions which can be sent
+	for len(s.quarantinedFiles) > 0 && s.currentSizeInBytes+bufferSize > maxStorageInBytes {
+		log.Infof("Maximum disk space for retry transactions is reached. Removing %s", s.quarantinedFiles[0].name)
+		if err := s.removeQuarantinedFileAt(0); err != nil {
+			return err
+		}
+		s.telemetry.addFilesRemovedCount()
+	}
 	for len(s.filenames) > 0 && s.currentSizeInBytes+bufferSize > maxStorageInBytes {
 		index := 0
 		filename := s.filenames[index]
@@ -185,38 +226,106 @@ func (s *transactionsFileStorage) removeFileAt(index int) error {
 	return nil
 }
 
+// quarantineFileAt renames the file with the quarantine extension so that it is not
+// reloaded. It is still counted in the disk space used, and removed once more than
+// maxQuarantinedFiles files are quarantined or when space is needed.
+func (s *transactionsFileStorage) quarantineFileAt(index int) error {
+	filename := s.filenames[index]
+	size, err := util.GetFileSize(filename)
+	if err != nil {
+		return err
+	}
+
+	// Remove the file from s.filenames also in case of error to not
+	// fail on the next call.
+	s.filenames = append(s.filenames[:index], s.filenames[index+1:]...)
+	quarantinedFilename := filename + quarantinedRetryFileExtension
+	if err := os.Rename(filename, quarantinedFilename); err != nil {
+		if os.Remove(filename) == nil {
+			s.currentSizeInBytes -= size
+		}
+		return err
+	}
+
+	s.quarantinedFiles = append(s.quarantinedFiles, quarantinedFile{name: quarantinedFilename, size: size})
+	for len(s.quarantinedFiles) > maxQuarantinedFiles {
+		if err := s.removeQuarantinedFileAt(0); err != nil {
+			log.Errorf("Cannot remove the quarantined file %s: %v", s.quarantinedFiles[0].name, err)
+		}
+	}
+	s.telemetry.setCurrentSizeInBytes(s.getCurrentSizeInBytes())
+	s.telemetry.setFilesCount(s.getFilesCount())
+	return nil
+}
+
+func (s *transactionsFileStorage) removeQuarantinedFileAt(index int) error {
+	file := s.quarantinedFiles[index]
+
+	// Remove the file from s.quarantinedFiles also in case of error to not
+	// fail on the next call.
+	s.quarantinedFiles = append(s.quarantinedFiles[:index], s.quarantinedFiles[index+1:]...)
+	if err := os.Remove(file.name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	s.currentSizeInBytes -= file.size
+	return nil
+}
+
 func (s *transactionsFileStorage) reloadExistingRetryFiles() error {
-	files, sizeInBytes, err := s.getExistingRetryFiles()
+	files, quarantinedFiles, sizeInBytes, err := s.getExistingRetryFiles()
 	if err != nil {
 		return err
 	}
 	s.currentSizeInBytes = sizeInBytes
 
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].ModTime().Before(files[j].ModTime())
-	})
+	byModTime := func(files []os.FileInfo) {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].ModTime().Before(files[j].ModTime())
+		})
+	}
+	byModTime(files)
+	byModTime(quarantinedFiles)
 	var filenames []string
 	for _, file := range files {
 		fullPath := path.Join(s.storagePath, file.Name())
 		filenames = append(filenames, fullPath)
 	}
+	for _, file := range quarantinedFiles {
+		fullPath := path.Join(s.storagePath, file.Name())
+		s.quarantinedFiles = append(s.quarantinedFiles, quarantinedFile{name: fullPath, size: file.Size()})
+	}
+	for len(s.quarantinedFiles) > maxQuarantinedFiles {
+		if err := s.removeQuarantinedFileAt(0); err != nil {
+			log.Errorf("Cannot remove the quarantined file: %v", err)
+		}
+	}
 	s.telemetry.addReloadedRetryFilesCount(len(filenames))
 	s.filenames = append(s.filenames, filenames...)
 	return nil
 }
 
-func (s *transactionsFileStorage) getExistingRetryFiles() ([]os.FileInfo, int64, error) {
+// getExistingRetryFiles returns the retry files, the quarantined files and their total size.
+func (s *transactionsFileStorage) getExistingRetryFiles() ([]os.FileInfo, []os.FileInfo, int64, error) {
 	entries, err := ioutil.ReadDir(s.storagePath)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, 0, err
 	}
-	var files []os.FileInfo
+	var files, quarantinedFiles []os.FileInfo
 	currentSizeInBytes := int64(0)
 	for _, entry := range entries {
-		if entry.Mode().IsRegular() && filepath.Ext(entry.Name()) == retryTransactionsExtension {
-			currentSizeInBytes += entry.Size()
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		switch filepath.Ext(entry.Name()) {
+		case retryTransactionsExtension:
 			files = append(files, entry)
+		case quarantinedRetryFileExtension:
+			quarantinedFiles = append(quarantinedFiles, entry)
+		default:
+			continue
 		}
+		currentSizeInBytes += entry.Size()
 	}
-	return files, currentSizeInBytes, nil
+	return files, quarantinedFiles, currentSizeInBytes, nil
 }
@@ -3,8 +3,10 @@ package forwarder
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
 	"github.com/stretchr/testify/assert"
@@ -84,6 +86,80 @@ func TestTransactionsFileStorageReloadExistingRetryFiles(t *testing.T) {
 	a.Equal([]string{"endpoint1", "endpoint2"}, getEndpointsFromTransactions(transactions))
 }
 
+func TestTransactionsFileStorageEncryption(t *testing.T) {
+	a := assert.New(t)
+	path, clean := createTmpFolder(a)
+	defer os.RemoveAll(path)
+	defer clean()
+
+	storage := newTestTransactionsFileStorageWithCipher(a, path, 1000, newTestRetryFileCipher(t, 1))
+	a.NoError(storage.Serialize(createHTTPTransactionCollectionTests("endpoint1", "endpoint2")))
+	a.NoError(storage.Serialize(createHTTPTransactionCollectionTests("endpoint3")))
+	a.Equal(2, storage.getFilesCount())
+
+	// tamper with the most recent file
+	content, err := ioutil.ReadFile(storage.filenames[1])
+	a.NoError(err)
+	a.NotContains(string(content), "endpoint3")
+	content[len(content)-1] ^= 1
+	a.NoError(ioutil.WriteFile(storage.filenames[1], content, 0600))
+
+	tamperedFile := storage.filenames[1]
+	sizeInBytes := storage.getCurrentSizeInBytes()
+	_, err = storage.Deserialize()
+	a.Equal(errRetryFileAuthentication, err)
+	a.FileExists(tamperedFile + quarantinedRetryFileExtension)
+	a.Equal(1, storage.getFilesCount())
+	a.Len(storage.quarantinedFiles, 1)
+	// the quarantined file is still counted in the disk space used
+	a.Equal(sizeInBytes, storage.getCurrentSizeInBytes())
+
+	// the quarantined file is not reloaded
+	newStorage := newTestTransactionsFileStorageWithCipher(a, path, 1000, newTestRetryFileCipher(t, 1))
+	a.Equal(storage.getCurrentSizeInBytes(), newStorage.getCurrentSizeInBytes())
+	a.Equal(1, newStorage.getFilesCount())
+	transactions, err := newStorage.Deserialize()
+	a.NoError(err)
+	a.Equal([]string{"endpoint1", "endpoint2"}, getEndpointsFromTransactions(transactions))
+}
+
+func TestTransactionsFileStorageQuarantinedFiles(t *testing.T) {
+	a := assert.New(t)
+	path, clean := createTmpFolder(a)
+	defer os.RemoveAll(path)
+	defer clean()
+
+	// only the most recent quarantined files are kept, they are counted in the disk space used
+	var quarantinedFiles []string
+	for i := 0; i < maxQuarantinedFiles+2; i++ {
+		file := filepath.Join(path, strconv.Itoa(i)+retryTransactionsExtension+quarantinedRetryFileExtension)
+		a.NoError(ioutil.WriteFile(file, make([]byte, 9), 0600))
+		modTime := time.Now().Add(time.Duration(i-maxQuarantinedFiles) * time.Hour)
+		a.NoError(os.Chtimes(file, modTime, modTime))
+		quarantinedFiles = append(quarantinedFiles, file)
+	}
+	storage := newTestTransactionsFileStorage(a, path, 100)
+	a.Equal(0, storage.getFilesCount())
+	a.Len(storage.quarantinedFiles, maxQuarantinedFiles)
+	a.Equal(int64(9*maxQuarantinedFiles), storage.getCurrentSizeInBytes())
+	a.NoFileExists(quarantinedFiles[0])
+	a.NoFileExists(quarantinedFiles[1])
+	a.FileExists(quarantinedFiles[2])
+
+	// they are removed first when space is needed
+	a.NoError(storage.Serialize(createHTTPTransactionCollectionTests("endpoint1")))
+	a.Equal(1, storage.getFilesCount())
+	a.Less(len(storage.quarantinedFiles), maxQuarantinedFiles)
+	a.NoFileExists(quarantinedFiles[2])
+	a.FileExists(quarantinedFiles[len(quarantinedFiles)-1])
+	a.LessOrEqual(storage.getCurrentSizeInBytes(), int64(100))
+
+	transactions, err := storage.Deserialize()
+	a.NoError(err)
+	a.Equal([]string{"endpoint1"}, getEndpointsFromTransactions(transactions))
+	a.Equal(int64(9*len(storage.quarantinedFiles)), storage.getCurrentSizeInBytes())
+}
+
 func createHTTPTransactionCollectionTests(endpoints ...string) []Transaction {
 	var transactions []Transaction
 
@@ -112,6 +188,10 @@ func getEndpointsFromTransactions(transactions []Transaction) []string {
 }
 
 func newTestTransactionsFileStorage(a *assert.Assertions, path string, maxSizeInBytes int64) *transactionsFileStorage {
+	return newTestTransactionsFileStorageWithCipher(a, path, maxSizeInBytes, nil)
+}
+
+func newTestTransactionsFileStorageWithCipher(a *assert.Assertions, path string, maxSizeInBytes int64, cipher *retryFileCipher) *transactionsFileStorage {
 	telemetry := transactionsFileStorageTelemetry{}
 	disk := diskUsageRetrieverMock{
 		diskUsage: &filesystem.DiskUsage{
@@ -119,7 +199,7 @@ func newTestTransactionsFileStorage(a *assert.Assertions, path string, maxSizeIn
 			Total:     10000,
 		}}
 	maxStorage := newForwarderMaxStorage("", disk, maxSizeInBytes, 1)
-	storage, err := newTransactionsFileStorage(NewTransactionsSerializer(domainName, nil), path, maxStorage, telemetry)
+	storage, err := newTransactionsFileStorage(NewTransactionsSerializer(domainName, nil), path, maxStorage, cipher, telemetry)
 	a.NoError(err)
 	return storage
 }
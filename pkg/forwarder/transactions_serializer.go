@@ -172,6 +172,8 @@ func (s *TransactionsSerializer) fromHeaderProto(headersProto map[string]*Header
 
 func fromTransactionPriorityProto(priority TransactionPriorityProto) (TransactionPriority, error) {
 	switch priority {
+	case TransactionPriorityProto_LOW:
+		return TransactionPriorityLow, nil
 	case TransactionPriorityProto_NORMAL:
 		return TransactionPriorityNormal, nil
 	case TransactionPriorityProto_HIGH:
@@ -192,6 +194,8 @@ func (s *TransactionsSerializer) toHeaderProto(headers http.Header) map[string]*
 
 func toTransactionPriorityProto(priority TransactionPriority) (TransactionPriorityProto, error) {
 	switch priority {
+	case TransactionPriorityLow:
+		return TransactionPriorityProto_LOW, nil
 	case TransactionPriorityNormal:
 		return TransactionPriorityProto_NORMAL, nil
 	case TransactionPriorityHigh:
@@ -39,6 +39,28 @@ func TestSerializeDeserialize(t *testing.T) {
 	a.Len(transactions, 0)
 }
 
+func TestSerializeDeserializePriorities(t *testing.T) {
+	a := assert.New(t)
+	serializer := NewTransactionsSerializer(domain, []string{apiKey1, apiKey2})
+
+	priorities := []TransactionPriority{TransactionPriorityLow, TransactionPriorityNormal, TransactionPriorityHigh}
+	for _, priority := range priorities {
+		tr := createHTTPTransactionTests()
+		tr.priority = priority
+		a.NoError(serializer.Add(tr))
+	}
+	bytes, err := serializer.GetBytesAndReset()
+	a.NoError(err)
+
+	transactions, errorCount, err := serializer.Deserialize(bytes)
+	a.NoError(err)
+	a.Equal(0, errorCount)
+	a.Len(transactions, len(priorities))
+	for i, priority := range priorities {
+		a.Equal(priority, transactions[i].GetPriority())
+	}
+}
+
 func TestPartialDeserialize(t *testing.T) {
 	a := assert.New(t)
 	transaction := createHTTPTransactionTests()
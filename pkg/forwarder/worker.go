@@ -35,6 +35,10 @@ type Worker struct {
 	stopChan            chan struct{}
 	stopped             chan struct{}
 	blockedList         *blockedEndpoints
+	bandwidthLimiter    *bandwidthLimiter
+	// throttledChan is the channel used to send the transactions throttled by the
+	// bandwidthLimiter back to the Forwarder, to be sent once bandwidth is available.
+	throttledChan chan<- Transaction
 }
 
 // NewWorker returns a new worker to consume Transaction from inputChan
@@ -170,8 +174,25 @@ func (w *Worker) process(ctx context.Context, t Transaction) {
 	// Run the endpoint through our blockedEndpoints circuit breaker
 	target := t.GetTarget()
 	if w.blockedList.isBlock(target) {
-		requeue()
-		log.Errorf("Too many errors for endpoint '%s': retrying later", target)
+		if t.IsRetryable() {
+			requeue()
+			log.Errorf("Too many errors for endpoint '%s': retrying later", target)
+		} else {
+			log.Errorf("Too many errors for endpoint '%s': dropping transaction", target)
+		}
+	} else if w.bandwidthLimiter != nil && !w.bandwidthLimiter.allow(t.GetPayloadSize(), t.GetPriority()) {
+		transactionsThrottled.Add(1)
+		tlmTxThrottled.Inc(w.bandwidthLimiter.domain, t.GetEndpointName(), t.GetPriority().String())
+		if !t.IsRetryable() {
+			log.Debugf("Bandwidth limit reached for endpoint '%s': dropping transaction", target)
+			return
+		}
+		select {
+		case w.throttledChan <- t:
+			log.Debugf("Bandwidth limit reached for endpoint '%s': sending later", target)
+		default:
+			log.Errorf("dropping transaction because the bandwidth limit is reached and too many transactions are waiting for it")
+		}
 	} else if err := t.Process(ctx, w.Client); err != nil {
 		w.blockedList.close(target)
 		requeue()
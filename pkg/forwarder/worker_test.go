@@ -112,6 +112,62 @@ func TestWorkerRetryBlockedTransaction(t *testing.T) {
 	assert.True(t, w.blockedList.isBlock("error_url"))
 }
 
+func TestWorkerRetryThrottledTransaction(t *testing.T) {
+	highPrio := make(chan Transaction)
+	lowPrio := make(chan Transaction)
+	requeue := make(chan Transaction, 1)
+	throttled := make(chan Transaction, 1)
+	w := NewWorker(highPrio, lowPrio, requeue, newBlockedEndpoints())
+	w.bandwidthLimiter, _ = newTestBandwidthLimiter(10)
+	w.bandwidthLimiter.tokens = 0
+	w.throttledChan = throttled
+
+	mock := newTestTransaction()
+	mock.On("GetTarget").Return("throttled_url").Times(1)
+	mock.On("GetPayloadSize").Return(10).Times(1)
+
+	w.Start()
+	highPrio <- mock
+	throttledTransaction := <-throttled
+	w.Stop(false)
+	mock.AssertExpectations(t)
+	mock.AssertNumberOfCalls(t, "Process", 0)
+	assert.Equal(t, mock, throttledTransaction)
+	assert.Len(t, requeue, 0)
+	assert.False(t, w.blockedList.isBlock("throttled_url"))
+}
+
+func TestWorkerDropNonRetryableTransactions(t *testing.T) {
+	highPrio := make(chan Transaction)
+	lowPrio := make(chan Transaction)
+	requeue := make(chan Transaction, 1)
+	throttled := make(chan Transaction, 1)
+	w := NewWorker(highPrio, lowPrio, requeue, newBlockedEndpoints())
+	w.bandwidthLimiter, _ = newTestBandwidthLimiter(10)
+	w.bandwidthLimiter.tokens = 0
+	w.throttledChan = throttled
+
+	blockedMock := newTestTransaction()
+	blockedMock.nonRetryable = true
+	blockedMock.On("GetTarget").Return("error_url").Times(1)
+	w.blockedList.close("error_url")
+	throttledMock := newTestTransaction()
+	throttledMock.nonRetryable = true
+	throttledMock.On("GetTarget").Return("throttled_url").Times(1)
+	throttledMock.On("GetPayloadSize").Return(10).Times(1)
+
+	w.Start()
+	highPrio <- blockedMock
+	highPrio <- throttledMock
+	w.Stop(false)
+	blockedMock.AssertExpectations(t)
+	throttledMock.AssertExpectations(t)
+	blockedMock.AssertNumberOfCalls(t, "Process", 0)
+	throttledMock.AssertNumberOfCalls(t, "Process", 0)
+	assert.Len(t, requeue, 0)
+	assert.Len(t, throttled, 0)
+}
+
 func TestWorkerResetConnections(t *testing.T) {
 	highPrio := make(chan Transaction)
 	lowPrio := make(chan Transaction)
@@ -20,7 +20,8 @@ func TestAuditorUnmarshalRegistryV2(t *testing.T) {
 	        },
 	        "path2.log": {
 	            "Offset": "2006-01-12T01:01:03.000000001Z",
-	            "LastUpdated": "2006-01-12T01:01:02.000000001Z"
+	            "LastUpdated": "2006-01-12T01:01:02.000000001Z",
+	            "TailingMode": "beginning"
 	        }
 	    },
 	    "Version": 2
@@ -33,4 +34,5 @@ func TestAuditorUnmarshalRegistryV2(t *testing.T) {
 
 	assert.Equal(t, "2006-01-12T01:01:03.000000001Z", r["path2.log"].Offset)
 	assert.Equal(t, 2, r["path2.log"].LastUpdated.Second())
+	assert.Equal(t, "beginning", r["path2.log"].TailingMode)
 }
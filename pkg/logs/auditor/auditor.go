@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,7 +29,11 @@ const defaultFlushPeriod = 1 * time.Second
 const defaultCleanupPeriod = 300 * time.Second
 
 // latest version of the API used by the auditor to retrieve the registry from disk.
-const registryAPIVersion = 2
+const registryAPIVersion = 3
+
+// fingerprintIdentifierPrefix prefixes the identifiers of the files identified by their fingerprint,
+// which require the version 3 of the registry.
+const fingerprintIdentifierPrefix = "fingerprint:"
 
 // Registry holds a list of offsets.
 type Registry interface {
@@ -255,12 +260,19 @@ func (a *RegistryAuditor) flushRegistry() error {
 	return ioutil.WriteFile(a.registryPath, mr, 0644)
 }
 
-// marshalRegistry marshals a registry
+// marshalRegistry marshals a registry, the version 2 is kept while no file is identified
+// by its fingerprint so that older agents can still read the registry.
 func (a *RegistryAuditor) marshalRegistry(registry map[string]RegistryEntry) ([]byte, error) {
 	r := JSONRegistry{
-		Version:  registryAPIVersion,
+		Version:  2,
 		Registry: registry,
 	}
+	for identifier := range registry {
+		if strings.HasPrefix(identifier, fingerprintIdentifierPrefix) {
+			r.Version = registryAPIVersion
+			break
+		}
+	}
 	return json.Marshal(r)
 }
 
@@ -277,6 +289,8 @@ func (a *RegistryAuditor) unmarshalRegistry(b []byte) (map[string]*RegistryEntry
 	}
 	// ensure backward compatibility
 	switch int(version) {
+	case 3:
+		return unmarshalRegistryV3(b)
 	case 2:
 		return unmarshalRegistryV2(b)
 	case 1:
@@ -89,6 +89,23 @@ func (suite *AuditorTestSuite) TestAuditorFlushesAndRecoversRegistry() {
 	suite.Equal("42", suite.a.registry[suite.source.Config.Path].Offset)
 }
 
+func (suite *AuditorTestSuite) TestAuditorFlushesRegistryV3WithFingerprints() {
+	suite.a.registry = make(map[string]*RegistryEntry)
+	suite.a.registry["fingerprint:0f1e2d3c4b5a6978"] = &RegistryEntry{
+		LastUpdated: time.Date(2006, time.January, 12, 1, 1, 1, 1, time.UTC),
+		Offset:      "42",
+		TailingMode: "end",
+	}
+	suite.a.flushRegistry()
+	r, err := ioutil.ReadFile(suite.testPath)
+	suite.Nil(err)
+	suite.Equal("{\"Version\":3,\"Registry\":{\"fingerprint:0f1e2d3c4b5a6978\":{\"LastUpdated\":\"2006-01-12T01:01:01.000000001Z\",\"Offset\":\"42\",\"TailingMode\":\"end\"}}}", string(r))
+
+	suite.a.registry = make(map[string]*RegistryEntry)
+	suite.a.registry = suite.a.recoverRegistry()
+	suite.Equal("42", suite.a.registry["fingerprint:0f1e2d3c4b5a6978"].Offset)
+}
+
 func (suite *AuditorTestSuite) TestAuditorRecoversRegistryForOffset() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.a.registry[suite.source.Config.Path] = &RegistryEntry{
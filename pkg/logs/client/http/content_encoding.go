@@ -8,6 +8,9 @@ package http
 import (
 	"bytes"
 	"compress/gzip"
+	"compress/zlib"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 // ContentEncoding encodes the payload
@@ -71,3 +74,70 @@ func (c *GzipContentEncoding) encode(payload []byte) ([]byte, error) {
 	}
 	return compressedPayload.Bytes(), nil
 }
+
+// DeflateContentEncoding encodes the payload using the zlib format of the deflate algorithm,
+// as expected by the deflate HTTP content encoding
+type DeflateContentEncoding struct {
+	level int
+}
+
+// NewDeflateContentEncoding creates a new Deflate content type
+func NewDeflateContentEncoding(level int) *DeflateContentEncoding {
+	if level < zlib.NoCompression {
+		level = zlib.NoCompression
+	} else if level > zlib.BestCompression {
+		level = zlib.BestCompression
+	}
+
+	return &DeflateContentEncoding{
+		level,
+	}
+}
+
+func (c *DeflateContentEncoding) name() string {
+	return "deflate"
+}
+
+func (c *DeflateContentEncoding) encode(payload []byte) ([]byte, error) {
+	var compressedPayload bytes.Buffer
+	zlibWriter, err := zlib.NewWriterLevel(&compressedPayload, c.level)
+	if err != nil {
+		return nil, err
+	}
+	_, err = zlibWriter.Write(payload)
+	if err != nil {
+		return nil, err
+	}
+	err = zlibWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return compressedPayload.Bytes(), nil
+}
+
+// ZstdContentEncoding encodes the payload using zstd algorithm
+type ZstdContentEncoding struct {
+	encoder *zstd.Encoder
+}
+
+// NewZstdContentEncoding creates a new Zstd content type,
+// the level follows the zstd levels from 1 (fastest) to 22 (best compression)
+func NewZstdContentEncoding(level int) (*ZstdContentEncoding, error) {
+	// a nil writer is allowed as the encoder is only used through EncodeAll,
+	// which is safe for concurrent use
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	if err != nil {
+		return nil, err
+	}
+	return &ZstdContentEncoding{
+		encoder: encoder,
+	}, nil
+}
+
+func (c *ZstdContentEncoding) name() string {
+	return "zstd"
+}
+
+func (c *ZstdContentEncoding) encode(payload []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(payload, make([]byte, 0, len(payload))), nil
+}
@@ -8,8 +8,10 @@ package http
 import (
 	"bytes"
 	"compress/gzip"
+	"compress/zlib"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +44,48 @@ func TestGzipContentEncodingName(t *testing.T) {
 	assert.Equal(t, NewGzipContentEncoding(gzip.BestCompression).name(), "gzip")
 }
 
+func TestDeflateContentEncoding(t *testing.T) {
+	payload := []byte("my payload")
+
+	encodedPayload, err := NewDeflateContentEncoding(zlib.BestCompression).encode(payload)
+	assert.Nil(t, err)
+
+	reader, err := zlib.NewReader(bytes.NewReader(encodedPayload))
+	assert.Nil(t, err)
+	var buffer bytes.Buffer
+	_, err = buffer.ReadFrom(reader)
+	assert.Nil(t, err)
+
+	assert.Equal(t, payload, buffer.Bytes())
+}
+
+func TestDeflateContentEncodingName(t *testing.T) {
+	assert.Equal(t, NewDeflateContentEncoding(zlib.BestCompression).name(), "deflate")
+}
+
+func TestZstdContentEncoding(t *testing.T) {
+	payload := []byte("my payload")
+
+	encoding, err := NewZstdContentEncoding(3)
+	assert.Nil(t, err)
+	encodedPayload, err := encoding.encode(payload)
+	assert.Nil(t, err)
+
+	decoder, err := zstd.NewReader(nil)
+	assert.Nil(t, err)
+	defer decoder.Close()
+	decompressedPayload, err := decoder.DecodeAll(encodedPayload, nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, payload, decompressedPayload)
+}
+
+func TestZstdContentEncodingName(t *testing.T) {
+	encoding, err := NewZstdContentEncoding(3)
+	assert.Nil(t, err)
+	assert.Equal(t, encoding.name(), "zstd")
+}
+
 func decompress(payload []byte) ([]byte, error) {
 	reader, err := gzip.NewReader(bytes.NewReader(payload))
 	if err != nil {
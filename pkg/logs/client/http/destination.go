@@ -65,12 +65,14 @@ func newDestination(endpoint config.Endpoint, contentType string, destinationsCo
 func (d *Destination) Send(payload []byte) error {
 	ctx := d.destinationsContext.Context()
 
-	encodedPayload, err := d.contentEncoding.encode(payload)
+	encodedPayload, err := encode(d.contentEncoding, payload)
 	if err != nil {
 		return err
 	}
 	metrics.BytesSent.Add(int64(len(payload)))
+	metrics.TlmBytesSent.Add(float64(len(payload)))
 	metrics.EncodedBytesSent.Add(int64(len(encodedPayload)))
+	metrics.TlmEncodedBytesSent.Add(float64(len(encodedPayload)))
 
 	req, err := http.NewRequest("POST", d.url, bytes.NewReader(encodedPayload))
 	if err != nil {
@@ -165,10 +167,39 @@ func buildURL(endpoint config.Endpoint) string {
 }
 
 func buildContentEncoding(endpoint config.Endpoint) ContentEncoding {
-	if endpoint.UseCompression {
-		return NewGzipContentEncoding(endpoint.CompressionLevel)
+	if !endpoint.UseCompression {
+		return IdentityContentType
 	}
-	return IdentityContentType
+	switch endpoint.CompressionKind {
+	case config.DeflateCompressionKind:
+		return NewDeflateContentEncoding(endpoint.CompressionLevel)
+	case config.ZstdCompressionKind:
+		encoding, err := NewZstdContentEncoding(endpoint.CompressionLevel)
+		if err == nil {
+			return encoding
+		}
+		log.Warnf("Could not create the zstd encoder, fallback on gzip: %v", err)
+	}
+	return NewGzipContentEncoding(endpoint.CompressionLevel)
+}
+
+// encode encodes the payload and tracks the compression ratio and the time spent.
+func encode(contentEncoding ContentEncoding, payload []byte) ([]byte, error) {
+	start := time.Now()
+	encodedPayload, err := contentEncoding.encode(payload)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+	name := contentEncoding.name()
+	metrics.EncodingDuration.Add(elapsed.Nanoseconds())
+	metrics.TlmEncodingDuration.Add(elapsed.Seconds(), name)
+	metrics.TlmEncodingInputBytes.Add(float64(len(payload)), name)
+	metrics.TlmEncodingOutputBytes.Add(float64(len(encodedPayload)), name)
+	if len(encodedPayload) > 0 {
+		metrics.TlmEncodingRatio.Set(float64(len(payload))/float64(len(encodedPayload)), name)
+	}
+	return encodedPayload, nil
 }
 
 // CheckConnectivity check if sending logs through HTTP works
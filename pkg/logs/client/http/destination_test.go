@@ -81,6 +81,14 @@ func TestBuildURLShouldReturnAddressWithPortWhenDefined(t *testing.T) {
 	assert.Equal(t, "http://foo:1234/v1/input/bar", url)
 }
 
+func TestBuildContentEncoding(t *testing.T) {
+	assert.Equal(t, IdentityContentType, buildContentEncoding(config.Endpoint{CompressionKind: config.ZstdCompressionKind}))
+	assert.IsType(t, &GzipContentEncoding{}, buildContentEncoding(config.Endpoint{UseCompression: true}))
+	assert.IsType(t, &GzipContentEncoding{}, buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: config.GzipCompressionKind}))
+	assert.IsType(t, &DeflateContentEncoding{}, buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: config.DeflateCompressionKind}))
+	assert.IsType(t, &ZstdContentEncoding{}, buildContentEncoding(config.Endpoint{UseCompression: true, CompressionKind: config.ZstdCompressionKind}))
+}
+
 func TestDestinationSend200(t *testing.T) {
 	server := NewHTTPServerTest(200)
 	err := server.destination.Send([]byte("yo"))
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"expvar"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/tcp"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	warningPeriod  = 1000
+	requestTimeout = 30 * time.Second
+)
+
+// Destination produces logs to a partition of a Kafka topic, each log of a payload
+// is sent as its own record. The cluster metadata is not fetched, so the endpoint must be
+// the leader broker of the partition, the broker rejects the records otherwise.
+type Destination struct {
+	host                string
+	topic               string
+	partition           int32
+	connManager         *tcp.ConnectionManager
+	destinationsContext *client.DestinationsContext
+	conn                net.Conn
+	connCreationTime    time.Time
+	correlationID       int32
+	inputChan           chan []byte
+	once                sync.Once
+}
+
+// NewDestination returns a new Kafka destination.
+func NewDestination(endpoint config.Endpoint, destinationsContext *client.DestinationsContext) *Destination {
+	return &Destination{
+		host:                endpoint.Host,
+		topic:               endpoint.Topic,
+		partition:           endpoint.Partition,
+		connManager:         tcp.NewRequestResponseConnectionManager(endpoint),
+		destinationsContext: destinationsContext,
+	}
+}
+
+// Send produces the logs of the payload and waits for the acknowledgement of the broker,
+// the error returned can be retryable and it is the responsibility of the callee to retry.
+func (d *Destination) Send(payload []byte) error {
+	if d.conn == nil {
+		var err error
+
+		ctx := d.destinationsContext.Context()
+		if d.conn, err = d.connManager.NewConnection(ctx); err != nil {
+			// the connection manager is not meant to fail,
+			// this can happen only when the context is cancelled.
+			return err
+		}
+		d.connCreationTime = time.Now()
+	}
+
+	d.correlationID++
+	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	request := encodeProduceRequest(d.correlationID, d.topic, d.partition, client.SplitPayload(payload), timestamp)
+
+	metrics.BytesSent.Add(int64(len(payload)))
+	metrics.TlmBytesSent.Add(float64(len(payload)))
+	metrics.EncodedBytesSent.Add(int64(len(request)))
+	metrics.TlmEncodedBytesSent.Add(float64(len(request)))
+
+	d.conn.SetDeadline(time.Now().Add(requestTimeout)) //nolint:errcheck
+	if _, err := d.conn.Write(request); err != nil {
+		d.closeConnection()
+		return client.NewRetryableError(err)
+	}
+	if err := readProduceResponse(d.conn, d.correlationID); err != nil {
+		if brokerErr, ok := err.(*brokerError); ok {
+			if brokerErr.code == notLeaderForPartition {
+				log.Warnf("Kafka destination %v is not the leader of partition %d of topic %s", d.host, d.partition, d.topic)
+			}
+			if brokerErr.retryable() {
+				return client.NewRetryableError(err)
+			}
+			return err
+		}
+		// the connection is in an unknown state, start over with a new one
+		d.closeConnection()
+		return client.NewRetryableError(err)
+	}
+
+	if d.connManager.ShouldReset(d.connCreationTime) {
+		log.Debug("Resetting Kafka connection")
+		d.closeConnection()
+	}
+
+	return nil
+}
+
+// SendAsync sends a payload to the destination without blocking. If the channel is full, the incoming payloads will be
+// dropped
+func (d *Destination) SendAsync(payload []byte) {
+	d.once.Do(func() {
+		inputChan := make(chan []byte, config.ChanSize)
+		d.inputChan = inputChan
+		metrics.DestinationLogsDropped.Set(d.host, &expvar.Int{})
+		go d.runAsync()
+	})
+
+	select {
+	case d.inputChan <- payload:
+	default:
+		if metrics.DestinationLogsDropped.Get(d.host).(*expvar.Int).Value()%warningPeriod == 0 {
+			log.Warnf("Some logs sent to Kafka destination %v were dropped", d.host)
+		}
+		metrics.DestinationLogsDropped.Add(d.host, 1)
+		metrics.TlmLogsDropped.Inc(d.host)
+	}
+}
+
+// runAsync reads the payloads from the channel and sends them
+func (d *Destination) runAsync() {
+	ctx := d.destinationsContext.Context()
+	for {
+		select {
+		case payload := <-d.inputChan:
+			if err := d.Send(payload); err != nil {
+				log.Debugf("Could not send payload to Kafka destination %v: %v", d.host, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *Destination) closeConnection() {
+	d.connManager.CloseConnection(d.conn)
+	d.conn = nil
+}
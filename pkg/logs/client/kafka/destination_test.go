@@ -0,0 +1,202 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/tcp"
+)
+
+// produceRequest is a produce request received by the fake broker.
+type produceRequest struct {
+	correlationID int32
+	topic         string
+	partition     int32
+	records       []string
+}
+
+// fakeBroker accepts produce requests and answers them with errorCode.
+type fakeBroker struct {
+	listener  net.Listener
+	requests  chan produceRequest
+	errorCode int16
+}
+
+func newFakeBroker(t *testing.T, errorCode int16) *fakeBroker {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	b := &fakeBroker{
+		listener:  l,
+		requests:  make(chan produceRequest, 10),
+		errorCode: errorCode,
+	}
+	go b.run(t)
+	return b
+}
+
+func (b *fakeBroker) run(t *testing.T) {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.serve(t, conn)
+	}
+}
+
+func (b *fakeBroker) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size int32
+		if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+			return
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return
+		}
+		request := decodeProduceRequest(t, buf)
+		b.requests <- request
+
+		response := &encoder{buf: make([]byte, 4)}
+		response.putInt32(request.correlationID)
+		response.putInt32(1)
+		response.putString(request.topic)
+		response.putInt32(1)
+		response.putInt32(request.partition)
+		response.putInt16(b.errorCode)
+		response.putInt64(0)  // base offset
+		response.putInt64(-1) // log append time
+		response.putInt32(0)  // throttle time
+		binary.BigEndian.PutUint32(response.buf[:4], uint32(len(response.buf)-4))
+		if _, err := conn.Write(response.buf); err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) Close() {
+	b.listener.Close()
+}
+
+// decodeProduceRequest decodes a produce request v3 and checks its record batch.
+func decodeProduceRequest(t *testing.T, buf []byte) produceRequest {
+	var request produceRequest
+	d := &decoder{buf: buf}
+	assert.Equal(t, produceAPIKey, d.int16())
+	assert.Equal(t, produceAPIVersion, d.int16())
+	request.correlationID = d.int32()
+	d.skip(int(d.int16())) // client id
+	assert.Equal(t, nullStringLength, d.int16())
+	assert.Equal(t, requiredAcks, d.int16())
+	d.int32() // timeout
+	assert.Equal(t, int32(1), d.int32())
+	topic := d.int16()
+	request.topic = string(d.buf[:topic])
+	d.skip(int(topic))
+	assert.Equal(t, int32(1), d.int32())
+	request.partition = d.int32()
+	batchSize := d.int32()
+	require.Nil(t, d.err)
+	batch := d.buf[:batchSize]
+
+	assert.Equal(t, int32(len(batch)-12), int32(binary.BigEndian.Uint32(batch[8:12])))
+	assert.Equal(t, byte(recordBatchMagic), batch[16])
+	assert.Equal(t, binary.BigEndian.Uint32(batch[17:21]), crc32.Checksum(batch[21:], castagnoliTable))
+	count := int(binary.BigEndian.Uint32(batch[57:61]))
+
+	records := batch[61:]
+	for i := 0; i < count; i++ {
+		length, n := binary.Varint(records)
+		record := records[n : n+int(length)]
+		records = records[n+int(length):]
+
+		record = record[1:] // attributes
+		for j := 0; j < 2; j++ {
+			_, n = binary.Varint(record) // timestamp and offset deltas
+			record = record[n:]
+		}
+		key, n := binary.Varint(record)
+		assert.Equal(t, int64(-1), key)
+		record = record[n:]
+		valueLength, n := binary.Varint(record)
+		request.records = append(request.records, string(record[n:n+int(valueLength)]))
+	}
+	return request
+}
+
+func TestDestinationProducesRecords(t *testing.T) {
+	broker := newFakeBroker(t, 0)
+	defer broker.Close()
+
+	ctx := client.NewDestinationsContext()
+	ctx.Start()
+	defer ctx.Stop()
+
+	endpoint := tcp.AddrToEndPoint(broker.listener.Addr())
+	endpoint.Topic = "logs"
+	endpoint.Partition = 2
+	destination := NewDestination(endpoint, ctx)
+
+	assert.Nil(t, destination.Send([]byte(`[{"message":"first"},{"message":"second"}]`)))
+	request := <-broker.requests
+	assert.Equal(t, int32(1), request.correlationID)
+	assert.Equal(t, "logs", request.topic)
+	assert.Equal(t, int32(2), request.partition)
+	assert.Equal(t, []string{`{"message":"first"}`, `{"message":"second"}`}, request.records)
+
+	assert.Nil(t, destination.Send([]byte("raw log")))
+	request = <-broker.requests
+	assert.Equal(t, int32(2), request.correlationID)
+	assert.Equal(t, []string{"raw log"}, request.records)
+}
+
+func TestDestinationBrokerErrors(t *testing.T) {
+	ctx := client.NewDestinationsContext()
+	ctx.Start()
+	defer ctx.Stop()
+
+	// NOT_LEADER_FOR_PARTITION
+	broker := newFakeBroker(t, 6)
+	defer broker.Close()
+	err := NewDestination(tcp.AddrToEndPoint(broker.listener.Addr()), ctx).Send([]byte("log"))
+	assert.IsType(t, &client.RetryableError{}, err)
+
+	// UNKNOWN_TOPIC_OR_PARTITION
+	broker = newFakeBroker(t, 3)
+	defer broker.Close()
+	err = NewDestination(tcp.AddrToEndPoint(broker.listener.Addr()), ctx).Send([]byte("log"))
+	assert.Equal(t, &brokerError{code: 3}, err)
+}
+
+func TestDestinationBrokerClosesConnection(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	ctx := client.NewDestinationsContext()
+	ctx.Start()
+	defer ctx.Stop()
+
+	destination := NewDestination(tcp.AddrToEndPoint(l.Addr()), ctx)
+	assert.IsType(t, &client.RetryableError{}, destination.Send([]byte("log")))
+	assert.Nil(t, destination.conn)
+}
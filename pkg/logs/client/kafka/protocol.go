@@ -0,0 +1,234 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Produce request settings, see https://kafka.apache.org/protocol#The_Messages_Produce
+const (
+	produceAPIKey      int16 = 0
+	produceAPIVersion  int16 = 3
+	recordBatchMagic   int8  = 2
+	requiredAcks       int16 = 1
+	maxResponseSize          = 1 << 20
+	recordBatchHeader        = 61
+	clientID                 = "datadog-agent"
+	noProducerID       int64 = -1
+	noPartitionEpoch   int32 = -1
+	noSequence         int32 = -1
+	noProducerEpoch    int16 = -1
+	nullStringLength   int16 = -1
+	nullBytesLength          = -1
+	timeoutMillisecond int32 = 10000
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// notLeaderForPartition is returned when the broker is not the leader of the partition.
+const notLeaderForPartition int16 = 6
+
+// retryableErrorCodes are the errors returned by the broker which may succeed on retry.
+var retryableErrorCodes = map[int16]string{
+	5:  "LEADER_NOT_AVAILABLE",
+	6:  "NOT_LEADER_FOR_PARTITION",
+	7:  "REQUEST_TIMED_OUT",
+	13: "NETWORK_EXCEPTION",
+	14: "COORDINATOR_LOAD_IN_PROGRESS",
+	15: "COORDINATOR_NOT_AVAILABLE",
+	19: "NOT_ENOUGH_REPLICAS",
+	20: "NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+}
+
+// brokerError is an error code returned by the broker.
+type brokerError struct {
+	code int16
+}
+
+func (e *brokerError) Error() string {
+	if name, found := retryableErrorCodes[e.code]; found {
+		return fmt.Sprintf("kafka broker error %d (%s)", e.code, name)
+	}
+	return fmt.Sprintf("kafka broker error %d", e.code)
+}
+
+// retryable returns true if the request may succeed on retry.
+func (e *brokerError) retryable() bool {
+	_, found := retryableErrorCodes[e.code]
+	return found
+}
+
+// encoder appends big-endian values to a buffer.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) putInt8(v int8) {
+	e.buf = append(e.buf, byte(v))
+}
+
+func (e *encoder) putInt16(v int16) {
+	e.buf = append(e.buf, byte(uint16(v)>>8), byte(v))
+}
+
+func (e *encoder) putInt32(v int32) {
+	e.buf = append(e.buf, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(e.buf[len(e.buf)-4:], uint32(v))
+}
+
+func (e *encoder) putInt64(v int64) {
+	e.buf = append(e.buf, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(e.buf[len(e.buf)-8:], uint64(v))
+}
+
+func (e *encoder) putVarint(v int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	e.buf = append(e.buf, tmp[:n]...)
+}
+
+func (e *encoder) putString(s string) {
+	e.putInt16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// encodeProduceRequest returns a size delimited produce request for the records of a topic partition.
+func encodeProduceRequest(correlationID int32, topic string, partition int32, records [][]byte, timestamp int64) []byte {
+	batch := encodeRecordBatch(records, timestamp)
+
+	e := &encoder{buf: make([]byte, 4, len(batch)+len(topic)+64)}
+	// request header
+	e.putInt16(produceAPIKey)
+	e.putInt16(produceAPIVersion)
+	e.putInt32(correlationID)
+	e.putString(clientID)
+	// produce request
+	e.putInt16(nullStringLength) // transactional_id
+	e.putInt16(requiredAcks)
+	e.putInt32(timeoutMillisecond)
+	e.putInt32(1) // topics
+	e.putString(topic)
+	e.putInt32(1) // partitions
+	e.putInt32(partition)
+	e.putInt32(int32(len(batch)))
+	e.buf = append(e.buf, batch...)
+
+	binary.BigEndian.PutUint32(e.buf[:4], uint32(len(e.buf)-4))
+	return e.buf
+}
+
+// encodeRecordBatch returns a record batch (magic v2) holding the records without keys nor headers.
+func encodeRecordBatch(records [][]byte, timestamp int64) []byte {
+	e := &encoder{buf: make([]byte, 0, recordBatchHeader)}
+	e.putInt64(0) // base offset
+	e.putInt32(0) // batch length, set below
+	e.putInt32(noPartitionEpoch)
+	e.putInt8(recordBatchMagic)
+	e.putInt32(0) // crc, set below
+	crcStart := len(e.buf)
+	e.putInt16(0) // attributes: no compression, create time
+	e.putInt32(int32(len(records) - 1))
+	e.putInt64(timestamp) // first timestamp
+	e.putInt64(timestamp) // max timestamp
+	e.putInt64(noProducerID)
+	e.putInt16(noProducerEpoch)
+	e.putInt32(noSequence)
+	e.putInt32(int32(len(records)))
+
+	for i, value := range records {
+		record := &encoder{}
+		record.putInt8(0)                 // attributes
+		record.putVarint(0)               // timestamp delta
+		record.putVarint(int64(i))        // offset delta
+		record.putVarint(nullBytesLength) // key
+		record.putVarint(int64(len(value)))
+		record.buf = append(record.buf, value...)
+		record.putVarint(0) // headers
+
+		e.putVarint(int64(len(record.buf)))
+		e.buf = append(e.buf, record.buf...)
+	}
+
+	binary.BigEndian.PutUint32(e.buf[8:12], uint32(len(e.buf)-12))
+	binary.BigEndian.PutUint32(e.buf[crcStart-4:crcStart], crc32.Checksum(e.buf[crcStart:], castagnoliTable))
+	return e.buf
+}
+
+// readProduceResponse reads a produce response and returns the error reported by the broker if any.
+func readProduceResponse(r io.Reader, correlationID int32) error {
+	var size int32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return err
+	}
+	if size < 4 || size > maxResponseSize {
+		return fmt.Errorf("invalid kafka response size %d", size)
+	}
+	response := make([]byte, size)
+	if _, err := io.ReadFull(r, response); err != nil {
+		return err
+	}
+
+	d := &decoder{buf: response}
+	if id := d.int32(); id != correlationID {
+		return fmt.Errorf("unexpected kafka correlation id %d, expected %d", id, correlationID)
+	}
+	for topics := d.int32(); topics > 0; topics-- {
+		d.skip(int(d.int16())) // topic name
+		for partitions := d.int32(); partitions > 0; partitions-- {
+			d.int32() // partition
+			code := d.int16()
+			d.skip(16) // base offset, log append time
+			if d.err == nil && code != 0 {
+				return &brokerError{code: code}
+			}
+		}
+	}
+	return d.err
+}
+
+var errShortResponse = errors.New("kafka response too short")
+
+// decoder reads big-endian values from a buffer, the first error is kept in err.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) skip(n int) {
+	if d.err != nil {
+		return
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = errShortResponse
+		return
+	}
+	d.buf = d.buf[n:]
+}
+
+func (d *decoder) int16() int16 {
+	if d.err != nil || len(d.buf) < 2 {
+		d.err = errShortResponse
+		return 0
+	}
+	v := int16(binary.BigEndian.Uint16(d.buf))
+	d.buf = d.buf[2:]
+	return v
+}
+
+func (d *decoder) int32() int32 {
+	if d.err != nil || len(d.buf) < 4 {
+		d.err = errShortResponse
+		return 0
+	}
+	v := int32(binary.BigEndian.Uint32(d.buf))
+	d.buf = d.buf[4:]
+	return v
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// LogEntry is a log decoded from a JSON payload.
+type LogEntry struct {
+	Message   string `json:"message"`
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+	Hostname  string `json:"hostname"`
+	Service   string `json:"service"`
+	Source    string `json:"ddsource"`
+	Tags      string `json:"ddtags"`
+	// Attributes holds the other fields of the log
+	Attributes map[string]interface{} `json:"-"`
+}
+
+// reservedFields are the fields of a log decoded in the LogEntry fields.
+var reservedFields = []string{"message", "status", "timestamp", "hostname", "service", "ddsource", "ddtags"}
+
+// SplitPayload returns the logs contained in a payload: the elements of a JSON array
+// when the logs are sent in batches, otherwise the payload itself.
+func SplitPayload(payload []byte) [][]byte {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return [][]byte{payload}
+	}
+	var elements []json.RawMessage
+	if err := json.Unmarshal(trimmed, &elements); err != nil {
+		return [][]byte{payload}
+	}
+	entries := make([][]byte, 0, len(elements))
+	for _, element := range elements {
+		entries = append(entries, element)
+	}
+	return entries
+}
+
+// DecodeLogEntry decodes a log encoded in JSON, returns false if the log is not a JSON object.
+func DecodeLogEntry(entry []byte) (*LogEntry, bool) {
+	trimmed := bytes.TrimSpace(entry)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	var logEntry LogEntry
+	if err := json.Unmarshal(trimmed, &logEntry); err != nil {
+		return nil, false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return nil, false
+	}
+	for _, field := range reservedFields {
+		delete(fields, field)
+	}
+	if len(fields) > 0 {
+		logEntry.Attributes = fields
+	}
+	return &logEntry, true
+}
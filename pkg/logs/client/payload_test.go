@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPayload(t *testing.T) {
+	entries := SplitPayload([]byte(`[{"message":"a"}, {"message":"b"}]`))
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, `{"message":"a"}`, string(entries[0]))
+	assert.Equal(t, `{"message":"b"}`, string(entries[1]))
+
+	assert.Equal(t, [][]byte{[]byte("raw log")}, SplitPayload([]byte("raw log")))
+	assert.Equal(t, [][]byte{[]byte("[not json")}, SplitPayload([]byte("[not json")))
+}
+
+func TestDecodeLogEntry(t *testing.T) {
+	entry, ok := DecodeLogEntry([]byte(`{"message":"hello","status":"error","timestamp":1600000000000,"ddtags":"env:prod"}`))
+	assert.True(t, ok)
+	assert.Equal(t, &LogEntry{Message: "hello", Status: "error", Timestamp: 1600000000000, Tags: "env:prod"}, entry)
+
+	entry, ok = DecodeLogEntry([]byte(`{"message":"hello","user":"bob"}`))
+	assert.True(t, ok)
+	assert.Equal(t, &LogEntry{Message: "hello", Attributes: map[string]interface{}{"user": "bob"}}, entry)
+
+	_, ok = DecodeLogEntry([]byte("raw log"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"expvar"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/tcp"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	warningPeriod = 1000
+)
+
+// Destination sends logs to a syslog server as RFC5424 messages framed with
+// octet counting (RFC6587) over TCP, or TLS when the endpoint uses SSL.
+type Destination struct {
+	host                string
+	connManager         *tcp.ConnectionManager
+	destinationsContext *client.DestinationsContext
+	conn                net.Conn
+	connCreationTime    time.Time
+	inputChan           chan []byte
+	once                sync.Once
+}
+
+// NewDestination returns a new syslog destination.
+func NewDestination(endpoint config.Endpoint, destinationsContext *client.DestinationsContext) *Destination {
+	return &Destination{
+		host:                endpoint.Host,
+		connManager:         tcp.NewConnectionManager(endpoint),
+		destinationsContext: destinationsContext,
+	}
+}
+
+// Send converts each log of the payload into a syslog message and sends them to the server,
+// returns an error if the operation failed.
+func (d *Destination) Send(payload []byte) error {
+	if d.conn == nil {
+		var err error
+
+		ctx := d.destinationsContext.Context()
+		if d.conn, err = d.connManager.NewConnection(ctx); err != nil {
+			// the connection manager is not meant to fail,
+			// this can happen only when the context is cancelled.
+			return err
+		}
+		d.connCreationTime = time.Now()
+	}
+
+	var frames []byte
+	for _, entry := range client.SplitPayload(payload) {
+		frames = appendFrame(frames, formatMessage(entry))
+	}
+
+	metrics.BytesSent.Add(int64(len(payload)))
+	metrics.TlmBytesSent.Add(float64(len(payload)))
+	metrics.EncodedBytesSent.Add(int64(len(frames)))
+	metrics.TlmEncodedBytesSent.Add(float64(len(frames)))
+
+	_, err := d.conn.Write(frames)
+	if err != nil {
+		d.connManager.CloseConnection(d.conn)
+		d.conn = nil
+		return client.NewRetryableError(err)
+	}
+
+	if d.connManager.ShouldReset(d.connCreationTime) {
+		log.Debug("Resetting syslog connection")
+		d.connManager.CloseConnection(d.conn)
+		d.conn = nil
+	}
+
+	return nil
+}
+
+// SendAsync sends a payload to the destination without blocking. If the channel is full, the incoming payloads will be
+// dropped
+func (d *Destination) SendAsync(payload []byte) {
+	d.once.Do(func() {
+		inputChan := make(chan []byte, config.ChanSize)
+		d.inputChan = inputChan
+		metrics.DestinationLogsDropped.Set(d.host, &expvar.Int{})
+		go d.runAsync()
+	})
+
+	select {
+	case d.inputChan <- payload:
+	default:
+		if metrics.DestinationLogsDropped.Get(d.host).(*expvar.Int).Value()%warningPeriod == 0 {
+			log.Warnf("Some logs sent to syslog destination %v were dropped", d.host)
+		}
+		metrics.DestinationLogsDropped.Add(d.host, 1)
+		metrics.TlmLogsDropped.Inc(d.host)
+	}
+}
+
+// runAsync reads the payloads from the channel and sends them
+func (d *Destination) runAsync() {
+	ctx := d.destinationsContext.Context()
+	for {
+		select {
+		case payload := <-d.inputChan:
+			d.Send(payload) //nolint:errcheck
+		case <-ctx.Done():
+			return
+		}
+	}
+}
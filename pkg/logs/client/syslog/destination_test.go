@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/tcp"
+)
+
+// newFakeSyslogServer returns a TCP server sending the octet counted messages it receives to messages.
+func newFakeSyslogServer(t *testing.T, messages chan string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(length[:len(length)-1])
+			if err != nil {
+				return
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				return
+			}
+			messages <- string(msg)
+		}
+	}()
+	return l
+}
+
+func TestDestinationSendsOctetCountedMessages(t *testing.T) {
+	messages := make(chan string, 10)
+	l := newFakeSyslogServer(t, messages)
+	defer l.Close()
+
+	ctx := client.NewDestinationsContext()
+	ctx.Start()
+	defer ctx.Stop()
+
+	destination := NewDestination(tcp.AddrToEndPoint(l.Addr()), ctx)
+	assert.Nil(t, destination.Send([]byte(`[{"message":"first","status":"warn","service":"api"},{"message":"second","status":"info","service":"api"}]`)))
+
+	assert.Equal(t, "<44>1 - - api - - - first", <-messages)
+	assert.Equal(t, "<46>1 - - api - - - second", <-messages)
+
+	destination.SendAsync([]byte("raw log"))
+	assert.Equal(t, "<46>1 - - - - - - raw log", <-messages)
+}
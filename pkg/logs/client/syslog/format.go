@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// Maximum lengths of the header fields defined by RFC5424.
+const (
+	maxHostnameLength = 255
+	maxAppNameLength  = 48
+	maxProcIDLength   = 128
+	maxMsgIDLength    = 32
+	maxSDNameLength   = 32
+)
+
+const nilValue = "-"
+
+// sdValueEscaper escapes the characters that are not allowed in a structured data value.
+var sdValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// formatMessage returns a RFC5424 message for a log:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [dd ddsource="..." ddtags="..."][attrs ...] MSG
+// Logs that are not JSON objects are wrapped as is, unless they are already syslog messages.
+func formatMessage(entry []byte) []byte {
+	logEntry, ok := client.DecodeLogEntry(entry)
+	if !ok {
+		if isRFC5424Message(entry) {
+			return entry
+		}
+		msg := make([]byte, 0, len(entry)+20)
+		msg = append(msg, message.SevInfo...)
+		msg = append(msg, "1 - - - - - - "...)
+		return append(msg, entry...)
+	}
+
+	msg := make([]byte, 0, len(logEntry.Message)+128)
+	msg = append(msg, message.StatusToSeverity(logEntry.Status)...)
+	msg = append(msg, '1', ' ')
+	if logEntry.Timestamp > 0 {
+		msg = time.Unix(0, logEntry.Timestamp*int64(time.Millisecond)).UTC().AppendFormat(msg, "2006-01-02T15:04:05.000Z")
+	} else {
+		msg = append(msg, nilValue...)
+	}
+	msg = append(msg, ' ')
+	msg = append(msg, headerField(logEntry.Hostname, maxHostnameLength)...)
+	msg = append(msg, ' ')
+	msg = append(msg, headerField(logEntry.Service, maxAppNameLength)...)
+	// PROCID and MSGID are not known
+	msg = append(msg, " - - "...)
+	msg = append(msg, structuredData(logEntry)...)
+	if logEntry.Message != "" {
+		msg = append(msg, ' ')
+		msg = append(msg, logEntry.Message...)
+	}
+	return msg
+}
+
+// isRFC5424Message returns true if the header of the message follows RFC5424:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func isRFC5424Message(msg []byte) bool {
+	if len(msg) < 3 || msg[0] != '<' {
+		return false
+	}
+	end := bytes.IndexByte(msg, '>')
+	if end < 2 || end > 4 {
+		return false
+	}
+	pri, err := strconv.Atoi(string(msg[1:end]))
+	if err != nil || pri < 0 || pri > 191 || !isDigits(msg[1:end]) {
+		return false
+	}
+	msg = msg[end+1:]
+	if !bytes.HasPrefix(msg, []byte("1 ")) {
+		return false
+	}
+	msg = msg[2:]
+
+	var field []byte
+	field, msg = nextHeaderField(msg)
+	if string(field) != nilValue {
+		if _, err := time.Parse(time.RFC3339Nano, string(field)); err != nil {
+			return false
+		}
+	}
+	for _, maxLength := range []int{maxHostnameLength, maxAppNameLength, maxProcIDLength, maxMsgIDLength} {
+		field, msg = nextHeaderField(msg)
+		if !isPrintableField(field, maxLength) {
+			return false
+		}
+	}
+
+	msg, ok := skipStructuredData(msg)
+	return ok && (len(msg) == 0 || msg[0] == ' ')
+}
+
+// skipStructuredData returns the data following the structured data,
+// or false if the structured data is invalid.
+func skipStructuredData(msg []byte) ([]byte, bool) {
+	if len(msg) > 0 && msg[0] == '-' {
+		return msg[1:], true
+	}
+	if len(msg) == 0 || msg[0] != '[' {
+		return nil, false
+	}
+	for len(msg) > 0 && msg[0] == '[' {
+		end := bytes.IndexAny(msg, " ]")
+		if end < 0 || !isSDName(msg[1:end]) {
+			return nil, false
+		}
+		msg = msg[end:]
+		for len(msg) > 0 && msg[0] == ' ' {
+			msg = msg[1:]
+			eq := bytes.IndexByte(msg, '=')
+			if eq < 0 || !isSDName(msg[:eq]) || len(msg) < eq+2 || msg[eq+1] != '"' {
+				return nil, false
+			}
+			msg = msg[eq+2:]
+			closed := false
+			for i := 0; i < len(msg); i++ {
+				if msg[i] == '\\' {
+					i++
+					continue
+				}
+				if msg[i] == '"' {
+					msg, closed = msg[i+1:], true
+					break
+				}
+			}
+			if !closed {
+				return nil, false
+			}
+		}
+		if len(msg) == 0 || msg[0] != ']' {
+			return nil, false
+		}
+		msg = msg[1:]
+	}
+	return msg, true
+}
+
+// nextHeaderField returns the data until the next space and the data following it.
+func nextHeaderField(msg []byte) ([]byte, []byte) {
+	end := bytes.IndexByte(msg, ' ')
+	if end < 0 {
+		return msg, nil
+	}
+	return msg[:end], msg[end+1:]
+}
+
+// isPrintableField returns true if the field is made of 1 to maxLength printable characters.
+func isPrintableField(field []byte, maxLength int) bool {
+	if len(field) == 0 || len(field) > maxLength {
+		return false
+	}
+	for _, c := range field {
+		if c < 33 || c > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+// isSDName returns true if name is a valid structured data name, which is a printable field
+// without '=', ' ', ']' and '"'.
+func isSDName(name []byte) bool {
+	return isPrintableField(name, maxSDNameLength) && !bytes.ContainsAny(name, `= ]"`)
+}
+
+func isDigits(value []byte) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// headerField returns a value made of printable characters only, as required
+// for the header fields, truncated to the maximum length of the field.
+func headerField(value string, maxLength int) string {
+	if value == "" {
+		return nilValue
+	}
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(field) > maxLength {
+		field = field[:maxLength]
+	}
+	return field
+}
+
+// structuredData returns the source and the tags of the log as a structured data element,
+// followed by the other fields of the log.
+func structuredData(logEntry *client.LogEntry) string {
+	var params []string
+	if logEntry.Source != "" {
+		params = append(params, `ddsource="`+sdValueEscaper.Replace(logEntry.Source)+`"`)
+	}
+	if logEntry.Tags != "" {
+		params = append(params, `ddtags="`+sdValueEscaper.Replace(logEntry.Tags)+`"`)
+	}
+	var data string
+	if len(params) > 0 {
+		data = "[dd " + strings.Join(params, " ") + "]"
+	}
+	data += string(message.AttributesPayload(logEntry.Attributes))
+	if data == "" {
+		return nilValue
+	}
+	return data
+}
+
+// appendFrame appends a message to frames using the octet counting framing.
+func appendFrame(frames []byte, msg []byte) []byte {
+	frames = strconv.AppendInt(frames, int64(len(msg)), 10)
+	frames = append(frames, ' ')
+	return append(frames, msg...)
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMessage(t *testing.T) {
+	entry := []byte(`{"message":"hello world","status":"error","timestamp":1600000000123,"hostname":"my host","service":"api","ddsource":"go","ddtags":"env:prod,team:\"logs\""}`)
+	assert.Equal(t, `<43>1 2020-09-13T12:26:40.123Z my_host api - - [dd ddsource="go" ddtags="env:prod,team:\"logs\""] hello world`, string(formatMessage(entry)))
+
+	entry = []byte(`{"message":"","status":"info"}`)
+	assert.Equal(t, `<46>1 - - - - - -`, string(formatMessage(entry)))
+
+	entry = []byte(`{"message":"hello","status":"info","ddsource":"go","user":"bob"}`)
+	assert.Equal(t, `<46>1 - - - - - [dd ddsource="go"][attrs user="bob"] hello`, string(formatMessage(entry)))
+}
+
+func TestFormatMessageNotJSON(t *testing.T) {
+	assert.Equal(t, "<46>1 - - - - - - raw log", string(formatMessage([]byte("raw log"))))
+
+	syslogMessage := "<43>1 2020-09-13T12:26:40.000000000Z host api - - - already formatted"
+	assert.Equal(t, syslogMessage, string(formatMessage([]byte(syslogMessage))))
+
+	// only the lines whose header is valid are passed through
+	assert.Equal(t, "<46>1 - - - - - - <43>0 raw log", string(formatMessage([]byte("<43>0 raw log"))))
+}
+
+func TestIsRFC5424Message(t *testing.T) {
+	for _, msg := range []string{
+		"<43>1 2020-09-13T12:26:40.000000000Z host api - - - already formatted",
+		"<0>1 - - - - - -",
+		"<191>1 2020-09-13T12:26:40Z host api 1234 ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Appli\\\"cation\\]\"][dd ddsource=\"go\"] msg",
+	} {
+		assert.True(t, isRFC5424Message([]byte(msg)), msg)
+	}
+	for _, msg := range []string{
+		"<43>0 raw log",
+		"<43>1 raw log",
+		"<192>1 - - - - - -",
+		"<-1>1 - - - - - -",
+		"<>1 - - - - - -",
+		"<43>1 yesterday host api - - -",
+		"<43>1 - host api - -",
+		"<43>1 - host api - - -msg",
+		"<43>1 - host api - - [unterminated",
+		"<43>1 - host api - - [dd key=\"unterminated]",
+		"<43>1 - host api - - [dd key=unquoted]",
+	} {
+		assert.False(t, isRFC5424Message([]byte(msg)), msg)
+	}
+}
+
+func TestHeaderField(t *testing.T) {
+	assert.Equal(t, "-", headerField("", maxAppNameLength))
+	assert.Equal(t, "my_app", headerField("my app", maxAppNameLength))
+	assert.Equal(t, 48, len(headerField(string(make([]byte, 100)), maxAppNameLength)))
+}
+
+func TestAppendFrame(t *testing.T) {
+	frames := appendFrame(nil, []byte("first"))
+	frames = appendFrame(frames, []byte("second"))
+	assert.Equal(t, "5 first6 second", string(frames))
+}
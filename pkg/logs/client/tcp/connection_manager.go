@@ -35,6 +35,8 @@ type ConnectionManager struct {
 	endpoint  config.Endpoint
 	mutex     sync.Mutex
 	firstConn sync.Once
+	// readsResponses is true when the caller reads the responses of the server itself
+	readsResponses bool
 }
 
 // NewConnectionManager returns an initialized ConnectionManager
@@ -44,6 +46,15 @@ func NewConnectionManager(endpoint config.Endpoint) *ConnectionManager {
 	}
 }
 
+// NewRequestResponseConnectionManager returns an initialized ConnectionManager for protocols
+// where the server answers each request, the caller is responsible for detecting server closes.
+func NewRequestResponseConnectionManager(endpoint config.Endpoint) *ConnectionManager {
+	return &ConnectionManager{
+		endpoint:       endpoint,
+		readsResponses: true,
+	}
+}
+
 type tlsTimeoutError struct{}
 
 func (tlsTimeoutError) Error() string {
@@ -120,7 +131,9 @@ func (cm *ConnectionManager) NewConnection(ctx context.Context) (net.Conn, error
 			conn = sslConn
 		}
 
-		go cm.handleServerClose(conn)
+		if !cm.readsResponses {
+			go cm.handleServerClose(conn)
+		}
 		status.RemoveGlobalWarning(statusConnectionError)
 		return conn, nil
 	}
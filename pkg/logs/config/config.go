@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -115,6 +116,23 @@ func BuildEndpoints(httpConnectivity HTTPConnectivity) (*Endpoints, error) {
 	return buildTCPEndpoints()
 }
 
+// BuildSpillBuffer returns the settings of the disk buffer of the logs agent,
+// returns nil if the disk buffer is disabled.
+func BuildSpillBuffer() *SpillBuffer {
+	maxSize := coreConfig.Datadog.GetInt64("logs_config.spill_buffer_max_size_in_bytes")
+	if maxSize <= 0 {
+		return nil
+	}
+	path := coreConfig.Datadog.GetString("logs_config.spill_buffer_path")
+	if path == "" {
+		path = filepath.Join(coreConfig.Datadog.GetString("logs_config.run_path"), "spill_buffer")
+	}
+	return &SpillBuffer{
+		Path:           path,
+		MaxSizeInBytes: maxSize,
+	}
+}
+
 // ExpectedTagsDuration returns a duration of the time expected tags will be submitted for.
 func ExpectedTagsDuration() time.Duration {
 	return coreConfig.Datadog.GetDuration("logs_config.expected_tags_duration")
@@ -179,7 +197,7 @@ func buildTCPEndpoints() (*Endpoints, error) {
 
 	additionals := getAdditionalEndpoints()
 	for i := 0; i < len(additionals); i++ {
-		additionals[i].UseSSL = main.UseSSL
+		additionals[i].UseSSL = useSSL(additionals[i], main)
 		additionals[i].ProxyAddress = proxyAddress
 		additionals[i].APIKey = coreConfig.SanitizeAPIKey(additionals[i].APIKey)
 	}
@@ -190,6 +208,7 @@ func buildTCPEndpoints() (*Endpoints, error) {
 type LogsConfigKeys struct {
 	UseCompression          string
 	CompressionLevel        string
+	CompressionKind         string
 	ConnectionResetInterval string
 	LogsDDURL               string
 	LogsNoSSL               string
@@ -203,6 +222,7 @@ type LogsConfigKeys struct {
 var logsConfigDefaultKeys = LogsConfigKeys{
 	UseCompression:          "logs_config.use_compression",
 	CompressionLevel:        "logs_config.compression_level",
+	CompressionKind:         "logs_config.compression_kind",
 	ConnectionResetInterval: "logs_config.connection_reset_interval",
 	LogsDDURL:               "logs_config.logs_dd_url",
 	LogsNoSSL:               "logs_config.logs_no_ssl",
@@ -230,10 +250,16 @@ func BuildHTTPEndpointsWithConfig(logsConfig LogsConfigKeys, endpointPrefix stri
 		defaultUseCompression = coreConfig.Datadog.GetBool(logsConfig.UseCompression)
 	}
 
+	defaultCompressionKind := GzipCompressionKind
+	if len(logsConfig.CompressionKind) != 0 {
+		defaultCompressionKind = validCompressionKind(coreConfig.Datadog.GetString(logsConfig.CompressionKind), GzipCompressionKind)
+	}
+
 	main := Endpoint{
 		APIKey:                  getLogsAPIKey(coreConfig.Datadog),
 		UseCompression:          defaultUseCompression,
 		CompressionLevel:        coreConfig.Datadog.GetInt(logsConfig.CompressionLevel),
+		CompressionKind:         defaultCompressionKind,
 		ConnectionResetInterval: time.Duration(coreConfig.Datadog.GetInt(logsConfig.ConnectionResetInterval)) * time.Second,
 	}
 
@@ -253,8 +279,9 @@ func BuildHTTPEndpointsWithConfig(logsConfig LogsConfigKeys, endpointPrefix stri
 
 	additionals := getAdditionalEndpointsFromKey(logsConfig.AdditionalEndpoints)
 	for i := 0; i < len(additionals); i++ {
-		additionals[i].UseSSL = main.UseSSL
+		additionals[i].UseSSL = useSSL(additionals[i], main)
 		additionals[i].APIKey = coreConfig.SanitizeAPIKey(additionals[i].APIKey)
+		additionals[i].CompressionKind = validCompressionKind(additionals[i].CompressionKind, main.CompressionKind)
 	}
 
 	batchWait := batchWaitFromKey(coreConfig.Datadog, logsConfig.BatchWait)
@@ -284,6 +311,20 @@ func getAdditionalEndpointsFromKey(additionalEndpointsParameter string) []Endpoi
 	return endpoints
 }
 
+// useSSL returns whether an additional endpoint uses SSL, endpoints with their own protocol
+// use SSL unless disabled, the other ones use the same setting as the main endpoint.
+func useSSL(additional Endpoint, main Endpoint) bool {
+	switch additional.Protocol {
+	case SyslogProtocol, KafkaProtocol:
+		return !additional.NoSSL
+	case "":
+		return main.UseSSL
+	default:
+		log.Warnf("Unknown protocol %s for additional endpoint %s, using the protocol of the main endpoint", additional.Protocol, additional.Host)
+		return main.UseSSL
+	}
+}
+
 func isSetAndNotEmpty(config coreConfig.Config, key string) bool {
 	return config.IsSet(key) && len(config.GetString(key)) > 0
 }
@@ -309,6 +350,19 @@ func parseAddress(address string) (string, int, error) {
 	return host, port, nil
 }
 
+// validCompressionKind returns the given compression kind if it is supported,
+// the fallback otherwise.
+func validCompressionKind(kind string, fallback string) string {
+	switch kind {
+	case GzipCompressionKind, DeflateCompressionKind, ZstdCompressionKind:
+		return kind
+	case "":
+		return fallback
+	}
+	log.Warnf("Invalid compression_kind: %v should be one of %v, %v or %v, fallback on %v", kind, GzipCompressionKind, DeflateCompressionKind, ZstdCompressionKind, fallback)
+	return fallback
+}
+
 func batchWaitFromKey(config coreConfig.Config, batchWaitKey string) time.Duration {
 	batchWait := coreConfig.Datadog.GetInt(batchWaitKey)
 	if batchWait < 1 || 10 < batchWait {
@@ -327,3 +381,8 @@ func TaggerWarmupDuration() time.Duration {
 func AggregationTimeout() time.Duration {
 	return coreConfig.Datadog.GetDuration("logs_config.aggregation_timeout") * time.Millisecond
 }
+
+// AutoMultiLineMatchTimeout returns the maximum duration of the multi-line pattern detection.
+func AutoMultiLineMatchTimeout() time.Duration {
+	return coreConfig.Datadog.GetDuration("logs_config.auto_multi_line_default_match_timeout") * time.Second
+}
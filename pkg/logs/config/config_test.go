@@ -162,21 +162,24 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsEnvVar() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 6}
+		CompressionLevel: 6,
+		CompressionKind:  "gzip"}
 	expectedAdditionalEndpoint1 := Endpoint{
 		APIKey:           "456",
 		Host:             "additional.endpoint.1",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  "gzip"}
 	expectedAdditionalEndpoint2 := Endpoint{
 		APIKey:           "789",
 		Host:             "additional.endpoint.2",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  "gzip"}
 
 	expectedEndpoints := NewEndpoints(expectedMainEndpoint, []Endpoint{expectedAdditionalEndpoint1, expectedAdditionalEndpoint2}, false, true, time.Second)
 	endpoints, err := BuildHTTPEndpoints()
@@ -248,21 +251,24 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 		Port:             443,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 6}
+		CompressionLevel: 6,
+		CompressionKind:  "gzip"}
 	expectedAdditionalEndpoint1 := Endpoint{
 		APIKey:           "456",
 		Host:             "additional.endpoint.1",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  "gzip"}
 	expectedAdditionalEndpoint2 := Endpoint{
 		APIKey:           "789",
 		Host:             "additional.endpoint.2",
 		Port:             1234,
 		UseSSL:           true,
 		UseCompression:   true,
-		CompressionLevel: 2}
+		CompressionLevel: 2,
+		CompressionKind:  "gzip"}
 
 	expectedEndpoints := NewEndpoints(expectedMainEndpoint, []Endpoint{expectedAdditionalEndpoint1, expectedAdditionalEndpoint2}, false, true, time.Second)
 	endpoints, err := BuildHTTPEndpoints()
@@ -309,3 +315,84 @@ func (suite *ConfigTestSuite) TestMultipleTCPEndpointsInConf() {
 	suite.Nil(err)
 	suite.Equal(expectedEndpoints, endpoints)
 }
+
+func (suite *ConfigTestSuite) TestHTTPEndpointsCompressionKind() {
+	suite.config.Set("api_key", "123")
+	suite.config.Set("logs_config.logs_dd_url", "agent-http-intake.logs.datadoghq.com:443")
+	suite.config.Set("logs_config.compression_kind", "zstd")
+	endpointsInConfig := []map[string]interface{}{
+		{
+			"api_key":          "456",
+			"host":             "additional.endpoint.1",
+			"use_compression":  true,
+			"compression_kind": "deflate"},
+		{
+			"api_key":         "789",
+			"host":            "additional.endpoint.2",
+			"use_compression": true},
+		{
+			"api_key":          "012",
+			"host":             "additional.endpoint.3",
+			"use_compression":  true,
+			"compression_kind": "lz4"},
+	}
+	suite.config.Set("logs_config.additional_endpoints", endpointsInConfig)
+
+	endpoints, err := BuildHTTPEndpoints()
+
+	suite.Nil(err)
+	suite.Equal(ZstdCompressionKind, endpoints.Main.CompressionKind)
+	suite.Equal(DeflateCompressionKind, endpoints.Additionals[0].CompressionKind)
+	suite.Equal(ZstdCompressionKind, endpoints.Additionals[1].CompressionKind)
+	suite.Equal(ZstdCompressionKind, endpoints.Additionals[2].CompressionKind)
+}
+
+func (suite *ConfigTestSuite) TestHTTPEndpointsInvalidCompressionKind() {
+	suite.config.Set("api_key", "123")
+	suite.config.Set("logs_config.compression_kind", "lz4")
+
+	endpoints, err := BuildHTTPEndpoints()
+
+	suite.Nil(err)
+	suite.Equal(GzipCompressionKind, endpoints.Main.CompressionKind)
+}
+
+func (suite *ConfigTestSuite) TestEndpointsWithProtocol() {
+	suite.config.Set("api_key", "123")
+	suite.config.Set("logs_config.logs_dd_url", "agent-http-intake.logs.datadoghq.com:443")
+	suite.config.Set("logs_config.logs_no_ssl", true)
+	endpointsInConfig := []map[string]interface{}{
+		{
+			"host":     "syslog.local",
+			"port":     6514,
+			"protocol": "syslog"},
+		{
+			"host":      "kafka.local",
+			"port":      9092,
+			"protocol":  "kafka",
+			"no_ssl":    true,
+			"topic":     "logs",
+			"partition": 3},
+		{
+			"api_key": "456",
+			"host":    "additional.endpoint"},
+	}
+	suite.config.Set("logs_config.additional_endpoints", endpointsInConfig)
+
+	endpoints, err := BuildHTTPEndpoints()
+
+	suite.Nil(err)
+	suite.False(endpoints.Main.UseSSL)
+
+	suite.Equal(SyslogProtocol, endpoints.Additionals[0].Protocol)
+	suite.Equal(6514, endpoints.Additionals[0].Port)
+	suite.True(endpoints.Additionals[0].UseSSL)
+
+	suite.Equal(KafkaProtocol, endpoints.Additionals[1].Protocol)
+	suite.False(endpoints.Additionals[1].UseSSL)
+	suite.Equal("logs", endpoints.Additionals[1].Topic)
+	suite.Equal(int32(3), endpoints.Additionals[1].Partition)
+
+	suite.Equal("", endpoints.Additionals[2].Protocol)
+	suite.False(endpoints.Additionals[2].UseSSL)
+}
@@ -15,3 +15,16 @@ const (
 	// DateFormat is the default date format.
 	DateFormat = "2006-01-02T15:04:05.000000000Z"
 )
+
+// Compression kinds supported by the HTTP destinations
+const (
+	GzipCompressionKind    = "gzip"
+	DeflateCompressionKind = "deflate"
+	ZstdCompressionKind    = "zstd"
+)
+
+// Protocols supported by the additional endpoints in addition to the protocol of the main endpoint
+const (
+	SyslogProtocol = "syslog"
+	KafkaProtocol  = "kafka"
+)
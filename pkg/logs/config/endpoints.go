@@ -15,10 +15,20 @@ type Endpoint struct {
 	Host                    string
 	Port                    int
 	UseSSL                  bool
-	UseCompression          bool `mapstructure:"use_compression" json:"use_compression"`
-	CompressionLevel        int  `mapstructure:"compression_level" json:"compression_level"`
+	UseCompression          bool   `mapstructure:"use_compression" json:"use_compression"`
+	CompressionLevel        int    `mapstructure:"compression_level" json:"compression_level"`
+	CompressionKind         string `mapstructure:"compression_kind" json:"compression_kind"`
 	ProxyAddress            string
 	ConnectionResetInterval time.Duration
+
+	// Protocol overrides the protocol of an additional endpoint, either "syslog" or "kafka",
+	// by default additional endpoints use the same protocol as the main one.
+	Protocol string `mapstructure:"protocol" json:"protocol"`
+	// NoSSL disables TLS for an additional endpoint with its own protocol.
+	NoSSL bool `mapstructure:"no_ssl" json:"no_ssl"`
+	// Topic and Partition are the destination of the logs sent to a Kafka endpoint.
+	Topic     string `mapstructure:"topic" json:"topic"`
+	Partition int32  `mapstructure:"partition" json:"partition"`
 }
 
 // Endpoints holds the main endpoint and additional ones to dualship logs.
@@ -28,6 +38,14 @@ type Endpoints struct {
 	UseProto    bool
 	UseHTTP     bool
 	BatchWait   time.Duration
+	SpillBuffer *SpillBuffer
+}
+
+// SpillBuffer holds the settings of the disk buffer storing the payloads
+// that could not be sent to the main endpoint.
+type SpillBuffer struct {
+	Path           string
+	MaxSizeInBytes int64
 }
 
 // NewEndpoints returns a new endpoints composite.
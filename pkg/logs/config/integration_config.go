@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serverless/aws"
 )
 
@@ -22,6 +23,12 @@ const (
 	WindowsEventType  = "windows_event"
 	SnmpTrapsType     = "snmp_traps"
 	StringChannelType = "string_channel"
+	SyslogType        = "syslog"
+
+	// WildcardByName selects the files of a wildcard path by reverse lexicographical order
+	WildcardByName = "by_name"
+	// WildcardByModificationTime selects the most recently modified files of a wildcard path first
+	WildcardByModificationTime = "by_modification_time"
 
 	// UTF16BE for UTF-16 Big endian encoding
 	UTF16BE string = "utf-16-be"
@@ -37,9 +44,15 @@ type LogsConfig struct {
 	Port int    // Network
 	Path string // File, Journald
 
+	Protocol    string `mapstructure:"protocol" json:"protocol"`           // Syslog
+	TLSCertFile string `mapstructure:"tls_cert_file" json:"tls_cert_file"` // Syslog
+	TLSKeyFile  string `mapstructure:"tls_key_file" json:"tls_key_file"`   // Syslog
+
 	Encoding     string   `mapstructure:"encoding" json:"encoding"`             // File
 	ExcludePaths []string `mapstructure:"exclude_paths" json:"exclude_paths"`   // File
 	TailingMode  string   `mapstructure:"start_position" json:"start_position"` // File
+	// WildcardSelection overrides logs_config.file_wildcard_selection_mode for this source
+	WildcardSelection string `mapstructure:"wildcard_selection_mode" json:"wildcard_selection_mode"` // File
 
 	IncludeUnits  []string `mapstructure:"include_units" json:"include_units"`   // Journald
 	ExcludeUnits  []string `mapstructure:"exclude_units" json:"exclude_units"`   // Journald
@@ -66,6 +79,15 @@ type LogsConfig struct {
 	SourceCategory  string
 	Tags            []string
 	ProcessingRules []*ProcessingRule `mapstructure:"log_processing_rules" json:"log_processing_rules"`
+
+	// RateLimit is the maximum number of messages per second of the source, the messages above it are dropped
+	RateLimit      float64 `mapstructure:"rate_limit" json:"rate_limit"`
+	RateLimitBurst int     `mapstructure:"rate_limit_burst" json:"rate_limit_burst"`
+
+	// AutoMultiLine overrides logs_config.auto_multi_line_detection for this source
+	AutoMultiLine           *bool   `mapstructure:"auto_multi_line_detection" json:"auto_multi_line_detection"`             // File, Docker
+	AutoMultiLineSamples    int     `mapstructure:"auto_multi_line_sample_size" json:"auto_multi_line_sample_size"`         // File, Docker
+	AutoMultiLineMatchRatio float64 `mapstructure:"auto_multi_line_match_threshold" json:"auto_multi_line_match_threshold"` // File, Docker
 }
 
 // TailingMode type
@@ -125,10 +147,21 @@ func (c *LogsConfig) Validate() error {
 		if err != nil {
 			return err
 		}
+		if mode := c.WildcardSelection; mode != "" && mode != WildcardByName && mode != WildcardByModificationTime {
+			return fmt.Errorf("invalid wildcard selection mode '%v' for %v", mode, c.Path)
+		}
 	case c.Type == TCPType && c.Port == 0:
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == SyslogType:
+		err := c.validateSyslog()
+		if err != nil {
+			return err
+		}
+	}
+	if c.RateLimit < 0 || c.RateLimitBurst < 0 {
+		return fmt.Errorf("rate_limit and rate_limit_burst must be positive")
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
@@ -148,6 +181,60 @@ func (c *LogsConfig) validateTailingMode() error {
 	return nil
 }
 
+func (c *LogsConfig) validateSyslog() error {
+	switch {
+	case c.Port == 0:
+		return fmt.Errorf("syslog source must have a port")
+	case c.Protocol != "" && c.Protocol != TCPType && c.Protocol != UDPType:
+		return fmt.Errorf("invalid syslog protocol '%v', must be tcp or udp", c.Protocol)
+	case (c.TLSCertFile == "") != (c.TLSKeyFile == ""):
+		return fmt.Errorf("syslog source must have both a tls_cert_file and a tls_key_file to use TLS")
+	case c.TLSCertFile != "" && c.Protocol == UDPType:
+		return fmt.Errorf("TLS is not supported for syslog over udp")
+	}
+	return nil
+}
+
+// AutoMultiLineEnabled returns true if the multi-line aggregation pattern of the source
+// should be detected automatically, only file and docker sources support it.
+func (c *LogsConfig) AutoMultiLineEnabled() bool {
+	if c.Type != FileType && c.Type != DockerType {
+		return false
+	}
+	if c.AutoMultiLine != nil {
+		return *c.AutoMultiLine
+	}
+	return coreConfig.Datadog.GetBool("logs_config.auto_multi_line_detection")
+}
+
+// AutoMultiLineSampleSize returns the number of lines sampled to detect the multi-line pattern.
+func (c *LogsConfig) AutoMultiLineSampleSize() int {
+	if c.AutoMultiLineSamples > 0 {
+		return c.AutoMultiLineSamples
+	}
+	return coreConfig.Datadog.GetInt("logs_config.auto_multi_line_default_sample_size")
+}
+
+// AutoMultiLineMatchThreshold returns the ratio of sampled lines a pattern must match to be selected.
+func (c *LogsConfig) AutoMultiLineMatchThreshold() float64 {
+	if c.AutoMultiLineMatchRatio > 0 {
+		return c.AutoMultiLineMatchRatio
+	}
+	return coreConfig.Datadog.GetFloat64("logs_config.auto_multi_line_default_match_threshold")
+}
+
+// WildcardSelectionMode returns the order in which the files matching a wildcard path are selected
+// to be tailed when there are more files than the open files limit.
+func (c *LogsConfig) WildcardSelectionMode() string {
+	if c.WildcardSelection != "" {
+		return c.WildcardSelection
+	}
+	if mode := coreConfig.Datadog.GetString("logs_config.file_wildcard_selection_mode"); mode == WildcardByModificationTime {
+		return mode
+	}
+	return WildcardByName
+}
+
 // ContainsWildcard returns true if the path contains any wildcard character
 func ContainsWildcard(path string) bool {
 	return strings.ContainsAny(path, "*?[")
@@ -21,6 +21,17 @@ func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: SnmpTrapsType},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONParsing}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: LogfmtParsing}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: RegexParsing, Pattern: "(?P<level>\\w+)"}}},
+		{Type: SyslogType, Port: 514},
+		{Type: SyslogType, Port: 514, Protocol: UDPType},
+		{Type: SyslogType, Port: 6514, Protocol: TCPType, TLSCertFile: "/etc/cert.pem", TLSKeyFile: "/etc/key.pem"},
+		{Type: DockerType, RateLimit: 100, RateLimitBurst: 500},
+		{Type: FileType, Path: "/var/log/**/*.log", WildcardSelection: WildcardByModificationTime},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: Sample, Pattern: "DEBUG", SampleRate: 0.1}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GenerateMetric, Pattern: "ERROR", MetricName: "app.errors"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GenerateMetric, Pattern: "(?P<code>\\d+) in (?P<ms>\\d+)ms", MetricName: "app.latency", MetricType: HistogramMetricType, ValueField: "ms", TagFields: []string{"code"}}}},
 	}
 
 	for _, config := range validConfigs {
@@ -42,6 +53,22 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Pattern: ".*"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: JSONParsing}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: RegexParsing}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: RegexParsing, Pattern: "(\\w+)"}}},
+		{Type: SyslogType},
+		{Type: SyslogType, Port: 514, Protocol: "sctp"},
+		{Type: SyslogType, Port: 6514, TLSCertFile: "/etc/cert.pem"},
+		{Type: SyslogType, Port: 6514, Protocol: UDPType, TLSCertFile: "/etc/cert.pem", TLSKeyFile: "/etc/key.pem"},
+		{Type: DockerType, RateLimit: -1},
+		{Type: FileType, Path: "/var/log/**/*.log", WildcardSelection: "by_size"},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: Sample, Pattern: "DEBUG"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: Sample, Pattern: "DEBUG", SampleRate: 1.5}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: Sample, SampleRate: 0.5}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GenerateMetric, Pattern: "ERROR"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GenerateMetric, Pattern: "ERROR", MetricName: "app.errors", MetricType: "set"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GenerateMetric, Pattern: "(?P<ms>\\d+)ms", MetricName: "app.latency", MetricType: GaugeMetricType}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GenerateMetric, Pattern: "(\\d+)ms", MetricName: "app.latency", ValueField: "ms"}}},
 	}
 
 	for _, config := range invalidConfigs {
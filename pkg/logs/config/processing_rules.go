@@ -16,6 +16,18 @@ const (
 	IncludeAtMatch = "include_at_match"
 	MaskSequences  = "mask_sequences"
 	MultiLine      = "multi_line"
+	JSONParsing    = "parse_json"
+	LogfmtParsing  = "parse_logfmt"
+	RegexParsing   = "parse_regex"
+	Sample         = "sample"
+	GenerateMetric = "generate_metric"
+)
+
+// Metric types of the generate_metric rules
+const (
+	CountMetricType     = "count"
+	GaugeMetricType     = "gauge"
+	HistogramMetricType = "histogram"
 )
 
 // ProcessingRule defines an exclusion or a masking rule to
@@ -25,6 +37,19 @@ type ProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
 	Pattern            string
+	// Parsing rules only, the fields to promote out of the parsed attributes
+	MessageField    string   `mapstructure:"message_field" json:"message_field"`
+	StatusField     string   `mapstructure:"status_field" json:"status_field"`
+	TimestampField  string   `mapstructure:"timestamp_field" json:"timestamp_field"`
+	TimestampFormat string   `mapstructure:"timestamp_format" json:"timestamp_format"`
+	TagFields       []string `mapstructure:"tag_fields" json:"tag_fields"`
+	// Sampling rules only, the ratio of matching messages to keep
+	SampleRate float64 `mapstructure:"sample_rate" json:"sample_rate"`
+	// Metric generation rules only, the value is parsed from the ValueField named group,
+	// the TagFields named groups are added as tags
+	MetricName string `mapstructure:"metric_name" json:"metric_name"`
+	MetricType string `mapstructure:"metric_type" json:"metric_type"`
+	ValueField string `mapstructure:"value_field" json:"value_field"`
 	// TODO: should be moved out
 	Regex       *regexp.Regexp
 	Placeholder []byte
@@ -34,7 +59,9 @@ type ProcessingRule struct {
 // Each processing rule must have:
 // - a valid name
 // - a valid type
-// - a valid pattern that compiles
+// - a valid pattern that compiles, parse_json and parse_logfmt rules do not need one
+// - a sample rate between 0 excluded and 1 for sampling rules
+// - a metric name and type for metric generation rules, the value and tag fields must be named groups of the pattern
 func ValidateProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
 		if rule.Name == "" {
@@ -42,8 +69,14 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		}
 
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
+		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine, RegexParsing, GenerateMetric:
 			break
+		case Sample:
+			if rule.SampleRate <= 0 || rule.SampleRate > 1 {
+				return fmt.Errorf("sample rate must be greater than 0 and lower or equal to 1 for processing rule: %s", rule.Name)
+			}
+		case JSONParsing, LogfmtParsing:
+			continue
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
 		default:
@@ -53,10 +86,18 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		if rule.Pattern == "" {
 			return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
 		}
-		_, err := regexp.Compile(rule.Pattern)
+		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return fmt.Errorf("invalid pattern %s for processing rule: %s", rule.Pattern, rule.Name)
 		}
+		if rule.Type == RegexParsing && !hasNamedCapture(re) {
+			return fmt.Errorf("pattern %s must contain at least one named capture group for processing rule: %s", rule.Pattern, rule.Name)
+		}
+		if rule.Type == GenerateMetric {
+			if err := validateMetricRule(rule, re); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -64,12 +105,15 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 // CompileProcessingRules compiles all processing rule regular expressions.
 func CompileProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
+		if rule.Type == JSONParsing || rule.Type == LogfmtParsing {
+			continue
+		}
 		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return err
 		}
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch:
+		case ExcludeAtMatch, IncludeAtMatch, RegexParsing, Sample, GenerateMetric:
 			rule.Regex = re
 		case MaskSequences:
 			rule.Regex = re
@@ -83,3 +127,59 @@ func CompileProcessingRules(rules []*ProcessingRule) error {
 	}
 	return nil
 }
+
+// IsParsingRule returns true if the rule extracts structured attributes from the content.
+func (r *ProcessingRule) IsParsingRule() bool {
+	switch r.Type {
+	case JSONParsing, LogfmtParsing, RegexParsing:
+		return true
+	}
+	return false
+}
+
+// validateMetricRule checks the metric settings of a generate_metric rule.
+func validateMetricRule(rule *ProcessingRule, re *regexp.Regexp) error {
+	if rule.MetricName == "" {
+		return fmt.Errorf("no metric name provided for processing rule: %s", rule.Name)
+	}
+	switch rule.MetricType {
+	case "", CountMetricType:
+		break
+	case GaugeMetricType, HistogramMetricType:
+		if rule.ValueField == "" {
+			return fmt.Errorf("%s metrics need a value_field for processing rule: %s", rule.MetricType, rule.Name)
+		}
+	default:
+		return fmt.Errorf("metric type %s is not supported for processing rule: %s", rule.MetricType, rule.Name)
+	}
+	fields := rule.TagFields
+	if rule.ValueField != "" {
+		fields = append([]string{rule.ValueField}, fields...)
+	}
+	for _, field := range fields {
+		if SubexpIndex(re, field) < 0 {
+			return fmt.Errorf("pattern %s must contain the named capture group %s for processing rule: %s", rule.Pattern, field, rule.Name)
+		}
+	}
+	return nil
+}
+
+// hasNamedCapture returns true if the regular expression defines at least one named group.
+func hasNamedCapture(re *regexp.Regexp) bool {
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// SubexpIndex returns the index of the named group of the regular expression, -1 if not found.
+func SubexpIndex(re *regexp.Regexp, name string) int {
+	for i, subexpName := range re.SubexpNames() {
+		if subexpName == name && name != "" {
+			return i
+		}
+	}
+	return -1
+}
@@ -7,9 +7,12 @@ package config
 
 import (
 	"expvar"
+	"math"
 	"sync"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"github.com/DataDog/datadog-agent/pkg/util"
 )
 
@@ -48,6 +51,12 @@ type LogSource struct {
 	// LatencyStats tracks internal stats on the time spent by messages from this source in a processing pipeline, i.e.
 	// the duration between when a message is decoded by the tailer/listener/decoder and when the message is handled by a sender
 	LatencyStats *util.StatsTracker
+	// rateLimiter caps the number of messages per second of this source, nil when unlimited
+	rateLimiter     *rate.Limiter
+	rateLimited     *CountInfo
+	rateLimitedOnce sync.Once
+	sampledOut      *CountInfo
+	sampledOutOnce  sync.Once
 }
 
 // NewLogSource creates a new log source.
@@ -62,7 +71,49 @@ func NewLogSource(name string, config *LogsConfig) *LogSource {
 		BytesRead:    expvar.Int{},
 		info:         make(map[string]InfoProvider),
 		LatencyStats: util.NewStatsTracker(time.Hour*24, time.Hour),
+		rateLimiter:  newRateLimiter(config),
+		rateLimited:  NewCountInfo("Rate limited messages"),
+		sampledOut:   NewCountInfo("Sampled out messages"),
+	}
+}
+
+// newRateLimiter returns a token bucket refilled at the rate limit of the config,
+// the burst defaults to one second of messages.
+func newRateLimiter(config *LogsConfig) *rate.Limiter {
+	if config == nil || config.RateLimit <= 0 {
+		return nil
+	}
+	burst := config.RateLimitBurst
+	if burst <= 0 {
+		burst = int(math.Ceil(config.RateLimit))
+	}
+	return rate.NewLimiter(rate.Limit(config.RateLimit), burst)
+}
+
+// AllowMessage returns false if a new message exceeds the rate limit of the source,
+// the rejected messages are counted and reported on the status page. It is called by
+// the processor, so the rate limit caps the messages sent, not the ones read and queued
+// in the pipeline.
+func (s *LogSource) AllowMessage() bool {
+	if s.rateLimiter == nil || s.rateLimiter.Allow() {
+		return true
+	}
+	s.rateLimitedOnce.Do(func() {
+		s.RegisterInfo(s.rateLimited)
+	})
+	s.rateLimited.Add(1)
+	return false
+}
+
+// RecordSampledOut counts a message dropped by a sampling rule and reports it on the status page.
+func (s *LogSource) RecordSampledOut() {
+	if s.sampledOut == nil {
+		return
 	}
+	s.sampledOutOnce.Do(func() {
+		s.RegisterInfo(s.sampledOut)
+	})
+	s.sampledOut.Add(1)
 }
 
 // AddInput registers an input as being handled by this source.
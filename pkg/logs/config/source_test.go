@@ -28,6 +28,30 @@ func (s *LogSourceSuite) TestInputs() {
 
 }
 
+func (s *LogSourceSuite) TestAllowMessageWithoutRateLimit() {
+	s.source = NewLogSource("", &LogsConfig{})
+	for i := 0; i < 1000; i++ {
+		s.True(s.source.AllowMessage())
+	}
+	s.Empty(s.source.GetInfoStatus())
+}
+
+func (s *LogSourceSuite) TestAllowMessageWithRateLimit() {
+	s.source = NewLogSource("", &LogsConfig{RateLimit: 0.001, RateLimitBurst: 3})
+	for i := 0; i < 3; i++ {
+		s.True(s.source.AllowMessage())
+	}
+	s.False(s.source.AllowMessage())
+	s.False(s.source.AllowMessage())
+	s.Equal(map[string][]string{"Rate limited messages": {"2"}}, s.source.GetInfoStatus())
+}
+
+func (s *LogSourceSuite) TestRecordSampledOut() {
+	s.source = NewLogSource("", &LogsConfig{})
+	s.source.RecordSampledOut()
+	s.Equal(map[string][]string{"Sampled out messages": {"1"}}, s.source.GetInfoStatus())
+}
+
 func TestTrackerSuite(t *testing.T) {
 	suite.Run(t, new(LogSourceSuite))
 }
@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package decoder
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// autoMultiLineInfoKey is the key of the info displayed on the status page.
+const autoMultiLineInfoKey = "Auto multi-line detection"
+
+// scoredPattern is a built-in pattern matching the first line of a log entry,
+// along with the number of sampled lines it matched.
+type scoredPattern struct {
+	name   string
+	score  int
+	regexp *regexp.Regexp
+}
+
+// newAutoMultiLinePatterns returns the built-in start of entry patterns, ordered from the most to the
+// least specific so that the first one wins when several patterns have the same score.
+func newAutoMultiLinePatterns() []*scoredPattern {
+	return []*scoredPattern{
+		// 2021-03-28T13:45:30.123456Z, 2021-03-28 13:45:30,123 (ISO 8601, Java and Python loggers)
+		{name: "ISO 8601 timestamp", regexp: regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`)},
+		// [2021-03-28 13:45:30]
+		{name: "bracketed ISO 8601 timestamp", regexp: regexp.MustCompile(`^\[\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`)},
+		// 2021/03/28 13:45:30 (Go standard logger)
+		{name: "slash separated timestamp", regexp: regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`)},
+		// Mar 28 13:45:30 (syslog)
+		{name: "syslog timestamp", regexp: regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}`)},
+		// 28/Mar/2021:13:45:30 (common log format)
+		{name: "common log format timestamp", regexp: regexp.MustCompile(`^\[?\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2}`)},
+		// Sun, 28 Mar 2021 13:45:30 (RFC 1123)
+		{name: "RFC 1123 timestamp", regexp: regexp.MustCompile(`^[A-Z][a-z]{2}, \d{2} [A-Z][a-z]{2} \d{4} \d{2}:\d{2}:\d{2}`)},
+		// I0328 13:45:30.123456 (glog and klog)
+		{name: "glog header", regexp: regexp.MustCompile(`^[IWEF]\d{4} \d{2}:\d{2}:\d{2}`)},
+		// 13:45:30.123
+		{name: "time of day", regexp: regexp.MustCompile(`^\d{2}:\d{2}:\d{2}[.,]\d{3}`)},
+		// ERROR something went wrong, [ERROR] something went wrong
+		{name: "log level", regexp: regexp.MustCompile(`^\[?(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|CRITICAL|FATAL)\b`)},
+	}
+}
+
+// AutoMultiLineHandler sends lines as single lines while it samples the first lines of a source,
+// once enough lines have been seen or the detection times out, it switches to a MultiLineHandler
+// if one of the built-in patterns matches enough of the sampled lines.
+type AutoMultiLineHandler struct {
+	inputChan         chan *Message
+	outputChan        chan *Message
+	singleLineHandler *SingleLineHandler
+	patterns          []*scoredPattern
+	sampleSize        int
+	linesTested       int
+	matchThreshold    float64
+	matchTimeout      time.Duration
+	flushTimeout      time.Duration
+	lineLimit         int
+	source            *config.LogSource
+	detectedPattern   *config.MappedInfo
+	timeoutTimer      *time.Timer
+}
+
+// NewAutoMultilineHandler returns a new AutoMultiLineHandler.
+func NewAutoMultilineHandler(outputChan chan *Message, lineLimit, sampleSize int, matchThreshold float64, matchTimeout, flushTimeout time.Duration, source *config.LogSource) *AutoMultiLineHandler {
+	h := &AutoMultiLineHandler{
+		inputChan:         make(chan *Message),
+		outputChan:        outputChan,
+		singleLineHandler: NewSingleLineHandler(outputChan, lineLimit),
+		patterns:          newAutoMultiLinePatterns(),
+		sampleSize:        sampleSize,
+		matchThreshold:    matchThreshold,
+		matchTimeout:      matchTimeout,
+		flushTimeout:      flushTimeout,
+		lineLimit:         lineLimit,
+		source:            source,
+	}
+
+	// Since a single source can have multiple file tailers - each with their own decoder instance,
+	// share the detection info between all of the decoders of the source.
+	if existingInfo, ok := source.GetInfo(autoMultiLineInfoKey).(*config.MappedInfo); ok {
+		h.detectedPattern = existingInfo
+	} else {
+		h.detectedPattern = config.NewMappedInfo(autoMultiLineInfoKey)
+		source.RegisterInfo(h.detectedPattern)
+	}
+	return h
+}
+
+// Handle forwards lines to inputChan to process them.
+func (h *AutoMultiLineHandler) Handle(input *Message) {
+	h.inputChan <- input
+}
+
+// Stop stops the handler.
+func (h *AutoMultiLineHandler) Stop() {
+	close(h.inputChan)
+}
+
+// Start starts the handler.
+func (h *AutoMultiLineHandler) Start() {
+	go h.run()
+}
+
+// run samples the first lines until a decision is made, then hands over the processing
+// of the remaining lines to the selected handler.
+func (h *AutoMultiLineHandler) run() {
+	for {
+		var timeout <-chan time.Time
+		if h.timeoutTimer != nil {
+			timeout = h.timeoutTimer.C
+		}
+		select {
+		case message, isOpen := <-h.inputChan:
+			if !isOpen {
+				h.stopTimer()
+				close(h.outputChan)
+				return
+			}
+			if h.timeoutTimer == nil {
+				// the detection window starts with the first line
+				h.timeoutTimer = time.NewTimer(h.matchTimeout)
+			}
+			h.singleLineHandler.process(message)
+			h.score(message)
+			if h.linesTested >= h.sampleSize {
+				h.decide()
+				return
+			}
+		case <-timeout:
+			h.decide()
+			return
+		}
+	}
+}
+
+// score counts the patterns matching the line.
+func (h *AutoMultiLineHandler) score(message *Message) {
+	h.linesTested++
+	for _, pattern := range h.patterns {
+		if pattern.regexp.Match(message.Content) {
+			pattern.score++
+		}
+	}
+}
+
+// bestPattern returns the pattern with the highest score if it matched enough lines.
+func (h *AutoMultiLineHandler) bestPattern() *scoredPattern {
+	var best *scoredPattern
+	for _, pattern := range h.patterns {
+		if best == nil || pattern.score > best.score {
+			best = pattern
+		}
+	}
+	if h.linesTested == 0 || float64(best.score)/float64(h.linesTested) < h.matchThreshold {
+		return nil
+	}
+	return best
+}
+
+// decide reports the result of the detection and keeps processing the lines accordingly,
+// it returns once the input channel is closed.
+func (h *AutoMultiLineHandler) decide() {
+	h.stopTimer()
+	best := h.bestPattern()
+	if best == nil {
+		log.Debugf("No multi-line pattern detected for source %s after %d lines, logs are processed as single lines", h.source.Name, h.linesTested)
+		h.detectedPattern.SetMessage("none", "No pattern detected, logs are processed as single lines")
+		h.runSingleLine()
+		return
+	}
+	log.Debugf("Multi-line pattern %s detected for source %s, matched %d lines out of %d", best.regexp, h.source.Name, best.score, h.linesTested)
+	h.detectedPattern.SetMessage(best.name, fmt.Sprintf("Detected %s pattern: %s", best.name, best.regexp))
+
+	lh := NewMultiLineHandler(h.outputChan, best.regexp, h.flushTimeout, h.lineLimit)
+	lh.inputChan = h.inputChan
+	shareCountInfo(h.source, lh)
+	lh.run()
+}
+
+// runSingleLine processes the remaining lines as single lines.
+func (h *AutoMultiLineHandler) runSingleLine() {
+	for message := range h.inputChan {
+		h.singleLineHandler.process(message)
+	}
+	close(h.outputChan)
+}
+
+func (h *AutoMultiLineHandler) stopTimer() {
+	if h.timeoutTimer != nil {
+		h.timeoutTimer.Stop()
+	}
+}
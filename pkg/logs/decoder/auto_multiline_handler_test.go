@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package decoder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/parser"
+)
+
+func newAutoMultiLineSource() *config.LogSource {
+	return config.NewLogSource("auto", &config.LogsConfig{Type: config.FileType})
+}
+
+func TestAutoMultiLineHandlerDetectsPattern(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	source := newAutoMultiLineSource()
+	h := NewAutoMultilineHandler(outputChan, 100, 2, 0.5, time.Minute, 10*time.Millisecond, source)
+	h.Start()
+
+	// sampled lines are sent as single lines
+	h.Handle(getDummyMessageWithLF("2021-03-28 13:45:30 ERROR first"))
+	assert.Equal(t, "2021-03-28 13:45:30 ERROR first", string((<-outputChan).Content))
+	h.Handle(getDummyMessageWithLF("2021-03-28 13:45:31 INFO second"))
+	assert.Equal(t, "2021-03-28 13:45:31 INFO second", string((<-outputChan).Content))
+
+	// the following lines are aggregated with the detected pattern
+	h.Handle(getDummyMessageWithLF("2021-03-28 13:45:32 ERROR Exception"))
+	h.Handle(getDummyMessageWithLF("\tat com.example.Main"))
+	h.Handle(getDummyMessageWithLF("2021-03-28 13:45:33 INFO third"))
+
+	output := <-outputChan
+	assert.Equal(t, "2021-03-28 13:45:32 ERROR Exception\\n\tat com.example.Main", string(output.Content))
+
+	h.Stop()
+	output = <-outputChan
+	assert.Equal(t, "2021-03-28 13:45:33 INFO third", string(output.Content))
+
+	info := source.GetInfoStatus()[autoMultiLineInfoKey]
+	assert.Equal(t, 1, len(info))
+	assert.Contains(t, info[0], "ISO 8601 timestamp")
+}
+
+func TestAutoMultiLineHandlerNoPatternDetected(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	source := newAutoMultiLineSource()
+	h := NewAutoMultilineHandler(outputChan, 100, 2, 0.5, time.Minute, 10*time.Millisecond, source)
+	h.Start()
+
+	for _, line := range []string{"foo", "bar", "baz", "qux"} {
+		h.Handle(getDummyMessageWithLF(line))
+		assert.Equal(t, line, string((<-outputChan).Content))
+	}
+	h.Stop()
+
+	assert.Equal(t, []string{"No pattern detected, logs are processed as single lines"}, source.GetInfoStatus()[autoMultiLineInfoKey])
+}
+
+func TestAutoMultiLineHandlerDetectionTimeout(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	source := newAutoMultiLineSource()
+	h := NewAutoMultilineHandler(outputChan, 100, 500, 0.5, 10*time.Millisecond, 10*time.Millisecond, source)
+	h.Start()
+
+	h.Handle(getDummyMessageWithLF("Mar 28 13:45:30 host app: first"))
+	assert.Equal(t, "Mar 28 13:45:30 host app: first", string((<-outputChan).Content))
+
+	// lines are aggregated once the detection timed out
+	assert.Eventually(t, func() bool {
+		return len(source.GetInfoStatus()[autoMultiLineInfoKey]) == 1
+	}, time.Second, 5*time.Millisecond)
+	h.Handle(getDummyMessageWithLF("Mar 28 13:45:31 host app: second"))
+	h.Handle(getDummyMessageWithLF("continued"))
+
+	output := <-outputChan
+	assert.Equal(t, "Mar 28 13:45:31 host app: second\\ncontinued", string(output.Content))
+	h.Stop()
+}
+
+func TestAutoMultiLineHandlerClosesOutputBeforeDetection(t *testing.T) {
+	outputChan := make(chan *Message, 10)
+	h := NewAutoMultilineHandler(outputChan, 100, 500, 0.5, time.Minute, 10*time.Millisecond, newAutoMultiLineSource())
+	h.Start()
+	h.Stop()
+
+	_, isOpen := <-outputChan
+	assert.False(t, isOpen)
+}
+
+func TestAutoMultiLineDecoder(t *testing.T) {
+	enabled := true
+	source := config.NewLogSource("auto", &config.LogsConfig{Type: config.FileType, AutoMultiLine: &enabled})
+	d := InitializeDecoder(source, parser.NoopParser)
+	assert.IsType(t, &AutoMultiLineHandler{}, d.lineParser.(*SingleLineParser).lineHandler)
+
+	disabled := false
+	source = config.NewLogSource("auto", &config.LogsConfig{Type: config.FileType, AutoMultiLine: &disabled})
+	d = InitializeDecoder(source, parser.NoopParser)
+	assert.IsType(t, &SingleLineHandler{}, d.lineParser.(*SingleLineParser).lineHandler)
+
+	source = config.NewLogSource("auto", &config.LogsConfig{Type: config.TCPType, AutoMultiLine: &enabled})
+	d = InitializeDecoder(source, parser.NoopParser)
+	assert.IsType(t, &SingleLineHandler{}, d.lineParser.(*SingleLineParser).lineHandler)
+}
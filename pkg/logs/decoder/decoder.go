@@ -92,20 +92,18 @@ func NewDecoderWithEndLineMatcher(source *config.LogSource, parser parser.Parser
 	for _, rule := range source.Config.ProcessingRules {
 		if rule.Type == config.MultiLine {
 			lh := NewMultiLineHandler(outputChan, rule.Regex, config.AggregationTimeout(), lineLimit)
-
-			// Since a single source can have multiple file tailers - each with their own decoder instance,
-			// Make sure we keep track of the multiline match count info from all of the decoders so the
-			// status page displays it correctly.
-			if existingInfo, ok := source.GetInfo(lh.countInfo.InfoKey()).(*config.CountInfo); ok {
-				// override the new decoders info to the instance we are already using
-				lh.countInfo = existingInfo
-			} else {
-				// this is the first decoder we have seen for this source - use it's count info
-				source.RegisterInfo(lh.countInfo)
-			}
+			shareCountInfo(source, lh)
 			lineHandler = lh
 		}
 	}
+	if lineHandler == nil && source.Config.AutoMultiLineEnabled() {
+		lineHandler = NewAutoMultilineHandler(outputChan, lineLimit,
+			source.Config.AutoMultiLineSampleSize(),
+			source.Config.AutoMultiLineMatchThreshold(),
+			config.AutoMultiLineMatchTimeout(),
+			config.AggregationTimeout(),
+			source)
+	}
 	if lineHandler == nil {
 		lineHandler = NewSingleLineHandler(outputChan, lineLimit)
 	}
@@ -119,6 +117,19 @@ func NewDecoderWithEndLineMatcher(source *config.LogSource, parser parser.Parser
 	return New(inputChan, outputChan, lineParser, lineLimit, matcher)
 }
 
+// shareCountInfo makes sure we keep track of the multiline match count info from all of the decoders
+// so the status page displays it correctly, since a single source can have multiple file tailers -
+// each with their own decoder instance.
+func shareCountInfo(source *config.LogSource, lh *MultiLineHandler) {
+	if existingInfo, ok := source.GetInfo(lh.countInfo.InfoKey()).(*config.CountInfo); ok {
+		// override the new decoders info to the instance we are already using
+		lh.countInfo = existingInfo
+	} else {
+		// this is the first decoder we have seen for this source - use it's count info
+		source.RegisterInfo(lh.countInfo)
+	}
+}
+
 // New returns an initialized Decoder
 func New(InputChan chan *Input, OutputChan chan *Message, lineParser LineParser, contentLenLimit int, matcher EndLineMatcher) *Decoder {
 	var lineBuffer bytes.Buffer
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/status"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -65,8 +66,8 @@ func NewProvider(filesLimit int) *Provider {
 
 // FilesToTail returns all the Files matching paths in sources,
 // it cannot return more than filesLimit Files.
-// For now, there is no way to prioritize specific Files over others,
-// they are just returned in reverse lexicographical order, see `searchFiles`
+// The Files of a wildcard path are returned in reverse lexicographical order or
+// most recently modified first depending on the source selection mode, see `searchFiles`
 func (p *Provider) FilesToTail(sources []*config.LogSource) []*File {
 	var filesToTail []*File
 	shouldLogErrors := p.shouldLogErrors
@@ -137,7 +138,7 @@ func (p *Provider) CollectFiles(source *config.LogSource) ([]*File, error) {
 
 // searchFiles returns all the files matching the source path pattern.
 func (p *Provider) searchFiles(pattern string, source *config.LogSource) ([]*File, error) {
-	paths, err := filepath.Glob(pattern)
+	paths, err := glob(pattern)
 	if err != nil {
 		return nil, fmt.Errorf("malformed pattern, could not find any file: %s", pattern)
 	}
@@ -162,10 +163,14 @@ func (p *Provider) searchFiles(pattern string, source *config.LogSource) ([]*Fil
 		return filepath.Base(paths[i]) > filepath.Base(paths[j])
 	})
 
-	// Resolve excluded path(s)
+	if source.Config.WildcardSelectionMode() == config.WildcardByModificationTime {
+		sortByModificationTime(paths)
+	}
+
+	// Resolve excluded path(s), the exclusion patterns support `**` like the path
 	excludedPaths := make(map[string]int)
 	for _, excludePattern := range source.Config.ExcludePaths {
-		excludedGlob, err := filepath.Glob(excludePattern)
+		excludedGlob, err := glob(excludePattern)
 		if err != nil {
 			return nil, fmt.Errorf("malformed exclusion pattern: %s, %s", excludePattern, err)
 		}
@@ -186,6 +191,20 @@ func (p *Provider) searchFiles(pattern string, source *config.LogSource) ([]*Fil
 	return files, nil
 }
 
+// sortByModificationTime sorts the paths from the most recently modified,
+// the paths that can not be stated are kept last.
+func sortByModificationTime(paths []string) {
+	modTimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return modTimes[paths[i]].After(modTimes[paths[j]])
+	})
+}
+
 // exists returns true if the file at path filePath exists
 // Note: we can't rely on os.IsNotExist for windows, so we check error nullity.
 // As we're tailing with *, the error is related to the path being malformed.
@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -272,6 +273,51 @@ func (suite *ProviderTestSuite) TestExcludePath() {
 	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[2].Path)
 }
 
+func (suite *ProviderTestSuite) TestRecursiveWildcardPath() {
+	path := fmt.Sprintf("%s/3/a/b", suite.testDir)
+	suite.Nil(os.MkdirAll(path, os.ModePerm))
+	_, err := os.Create(fmt.Sprintf("%s/3/a/b/4.log", suite.testDir))
+	suite.Nil(err)
+	_, err = os.Create(fmt.Sprintf("%s/3/a/b/4.txt", suite.testDir))
+	suite.Nil(err)
+
+	fileProvider := NewProvider(10)
+	logSources := []*config.LogSource{
+		config.NewLogSource("", &config.LogsConfig{
+			Type:         config.FileType,
+			Path:         fmt.Sprintf("%s/**/*.log", suite.testDir),
+			ExcludePaths: []string{fmt.Sprintf("%s/1/**", suite.testDir)},
+		}),
+	}
+	files := fileProvider.FilesToTail(logSources)
+	suite.Equal(3, len(files))
+	suite.Equal(fmt.Sprintf("%s/3/a/b/4.log", suite.testDir), files[0].Path)
+	suite.Equal(fmt.Sprintf("%s/2/2.log", suite.testDir), files[1].Path)
+	suite.Equal(fmt.Sprintf("%s/2/1.log", suite.testDir), files[2].Path)
+}
+
+func (suite *ProviderTestSuite) TestWildcardPathsAreSortedByModificationTime() {
+	now := time.Now()
+	for i, name := range []string{"1/1.log", "2/2.log", "1/3.log", "2/1.log", "1/2.log"} {
+		modTime := now.Add(time.Duration(-i) * time.Hour)
+		suite.Nil(os.Chtimes(fmt.Sprintf("%s/%s", suite.testDir, name), modTime, modTime))
+	}
+
+	fileProvider := NewProvider(suite.filesLimit)
+	logSources := []*config.LogSource{
+		config.NewLogSource("", &config.LogsConfig{
+			Type:              config.FileType,
+			Path:              fmt.Sprintf("%s/*/*.log", suite.testDir),
+			WildcardSelection: config.WildcardByModificationTime,
+		}),
+	}
+	files := fileProvider.FilesToTail(logSources)
+	suite.Equal(3, len(files))
+	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[0].Path)
+	suite.Equal(fmt.Sprintf("%s/2/2.log", suite.testDir), files[1].Path)
+	suite.Equal(fmt.Sprintf("%s/1/3.log", suite.testDir), files[2].Path)
+}
+
 func TestProviderTestSuite(t *testing.T) {
 	suite.Run(t, new(ProviderTestSuite))
 }
@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"fmt"
+	"hash/crc64"
+	"io"
+)
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// computeFingerprint returns a checksum of the first size bytes of the file at path,
+// it returns an empty fingerprint when the file is shorter than size bytes as its
+// content may not be distinctive enough yet.
+func computeFingerprint(path string, size int) (string, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return "", nil
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%016x", crc64.Checksum(buf, crc64Table)), nil
+}
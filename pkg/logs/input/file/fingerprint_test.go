@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-fingerprint-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	require.NoError(t, ioutil.WriteFile(first, []byte("2021-10-11 first line\nsecond line\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(second, []byte("2021-10-11 first line\nanother line\n"), 0644))
+
+	// only the first bytes are fingerprinted
+	fingerprint, err := computeFingerprint(first, 16)
+	assert.NoError(t, err)
+	assert.Len(t, fingerprint, 16)
+	secondFingerprint, err := computeFingerprint(second, 16)
+	assert.NoError(t, err)
+	assert.Equal(t, fingerprint, secondFingerprint)
+
+	secondFingerprint, err = computeFingerprint(second, 30)
+	assert.NoError(t, err)
+	assert.NotEqual(t, fingerprint, secondFingerprint)
+
+	// files shorter than the fingerprint size are not fingerprinted
+	fingerprint, err = computeFingerprint(first, 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, "", fingerprint)
+
+	_, err = computeFingerprint(filepath.Join(dir, "missing.log"), 16)
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// recursiveWildcard is the path element matching any number of directories.
+const recursiveWildcard = "**"
+
+// maxRecursiveWildcardDepth is the maximum number of directories the search descends into
+// below the directories matching the pattern elements preceding a `**` element.
+const maxRecursiveWildcardDepth = 16
+
+// glob returns the paths matching the pattern like filepath.Glob, when the pattern contains
+// a `**` element, the matching files are searched recursively in the directories matching
+// the pattern elements preceding it, up to maxRecursiveWildcardDepth directories deep.
+// Symlinked directories are followed like filepath.Glob does.
+func glob(pattern string) ([]string, error) {
+	elements := splitPath(pattern)
+	index := indexOf(elements, recursiveWildcard)
+	if index < 0 {
+		return filepath.Glob(pattern)
+	}
+	for _, element := range elements {
+		if _, err := filepath.Match(element, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	roots := []string{"."}
+	prefix := strings.Join(elements[:index], "/")
+	if strings.HasPrefix(filepath.ToSlash(pattern), "/") {
+		prefix = "/" + prefix
+	}
+	if prefix != "" {
+		var err error
+		if roots, err = filepath.Glob(filepath.FromSlash(prefix)); err != nil {
+			return nil, err
+		}
+	}
+
+	var paths []string
+	visited := make(map[string]struct{})
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			continue
+		}
+		walkDir(root, info, 0, visited, func(path string) {
+			if matched, _ := matchPath(pattern, path); matched {
+				paths = append(paths, path)
+			}
+		})
+	}
+	return paths, nil
+}
+
+// walkDir calls visit for each file below path, in lexical order, following the symlinks.
+// The directories already visited through another link are skipped, like the unreadable ones.
+func walkDir(path string, info os.FileInfo, depth int, visited map[string]struct{}, visit func(path string)) {
+	if !info.IsDir() {
+		visit(path)
+		return
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return
+	}
+	if _, found := visited[realPath]; found || depth > maxRecursiveWildcardDepth {
+		return
+	}
+	visited[realPath] = struct{}{}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		entryPath := filepath.Join(path, entry.Name())
+		if entry.Mode()&os.ModeSymlink != 0 {
+			if entry, err = os.Stat(entryPath); err != nil {
+				continue
+			}
+		}
+		walkDir(entryPath, entry, depth+1, visited, visit)
+	}
+}
+
+// matchPath reports whether the path matches the pattern, the pattern elements are matched
+// with filepath.Match and a `**` element matches any number of path elements.
+func matchPath(pattern, path string) (bool, error) {
+	return matchElements(splitPath(pattern), splitPath(path))
+}
+
+func matchElements(pattern, path []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == recursiveWildcard {
+			// consecutive `**` are equivalent to a single one
+			for len(pattern) > 0 && pattern[0] == recursiveWildcard {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true, nil
+			}
+			for i := 0; i <= len(path); i++ {
+				matched, err := matchElements(pattern, path[i:])
+				if matched || err != nil {
+					return matched, err
+				}
+			}
+			return false, nil
+		}
+		if len(path) == 0 {
+			return false, nil
+		}
+		matched, err := filepath.Match(pattern[0], path[0])
+		if !matched || err != nil {
+			return false, err
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0, nil
+}
+
+// splitPath returns the non-empty elements of the path.
+func splitPath(path string) []string {
+	var elements []string
+	for _, element := range strings.Split(filepath.ToSlash(path), "/") {
+		if element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
+
+func indexOf(elements []string, value string) int {
+	for i, element := range elements {
+		if element == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !windows
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/var/log/*.log", "/var/log/foo.log", true},
+		{"/var/log/*.log", "/var/log/app/foo.log", false},
+		{"/var/log/**/*.log", "/var/log/foo.log", true},
+		{"/var/log/**/*.log", "/var/log/app/foo.log", true},
+		{"/var/log/**/*.log", "/var/log/app/tenant/1/foo.log", true},
+		{"/var/log/**/*.log", "/var/log/app/foo.txt", false},
+		{"/var/log/**/app/*.log", "/var/log/a/b/app/foo.log", true},
+		{"/var/log/**/app/*.log", "/var/log/a/b/other/foo.log", false},
+		{"/var/log/**", "/var/log/a/b/foo.log", true},
+		{"/var/log/**/**/*.log", "/var/log/foo.log", true},
+		{"/**/foo.log", "/var/log/foo.log", true},
+		{"/var/*/**/foo.log", "/var/log/foo.log", true},
+		{"/var/*/**/foo.log", "/opt/log/foo.log", false},
+	}
+	for _, test := range tests {
+		match, err := matchPath(test.pattern, test.path)
+		assert.NoError(t, err)
+		assert.Equal(t, test.match, match, "%s %s", test.pattern, test.path)
+	}
+
+	_, err := matchPath("/var/log/[/*.log", "/var/log/[/foo.log")
+	assert.Error(t, err)
+}
+
+func TestGlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-glob-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, path := range []string{"a.log", "x/b.log", "x/y/c.log", "x/y/d.txt", "z/e.log"} {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, nil, 0644))
+	}
+
+	paths, err := glob(filepath.Join(dir, "**", "*.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.log"),
+		filepath.Join(dir, "x/b.log"),
+		filepath.Join(dir, "x/y/c.log"),
+		filepath.Join(dir, "z/e.log"),
+	}, paths)
+
+	paths, err = glob(filepath.Join(dir, "x", "**"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "x/b.log"),
+		filepath.Join(dir, "x/y/c.log"),
+		filepath.Join(dir, "x/y/d.txt"),
+	}, paths)
+
+	// the pattern elements preceding the recursive wildcard are globbed
+	paths, err = glob(filepath.Join(dir, "?", "**", "c.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "x/y/c.log")}, paths)
+
+	_, err = glob(filepath.Join(dir, "**", "[.log"))
+	assert.Error(t, err)
+}
+
+func TestGlobFollowsSymlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-glob-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "target", "app"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "target", "app", "a.log"), nil, 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "logs"), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "target"), filepath.Join(dir, "logs", "link")))
+	// a symlink to a parent directory is not followed twice
+	require.NoError(t, os.Symlink(filepath.Join(dir, "logs"), filepath.Join(dir, "logs", "loop")))
+
+	paths, err := glob(filepath.Join(dir, "logs", "**", "*.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "logs/link/app/a.log")}, paths)
+}
+
+func TestGlobMaxDepth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-glob-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	deepest := dir
+	for i := 0; i <= maxRecursiveWildcardDepth; i++ {
+		deepest = filepath.Join(deepest, "d")
+	}
+	require.NoError(t, os.MkdirAll(deepest, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(filepath.Dir(deepest), "a.log"), nil, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(deepest, "b.log"), nil, 0644))
+
+	paths, err := glob(filepath.Join(dir, "**", "*.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(filepath.Dir(deepest), "a.log")}, paths)
+}
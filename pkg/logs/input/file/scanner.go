@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
@@ -33,7 +34,9 @@ type Scanner struct {
 	tailers             map[string]*Tailer
 	registry            auditor.Registry
 	tailerSleepDuration time.Duration
-	stop                chan struct{}
+	// fingerprintSize is the number of bytes used to fingerprint the files, 0 to identify files by their path
+	fingerprintSize int
+	stop            chan struct{}
 }
 
 // NewScanner returns a new scanner.
@@ -47,6 +50,7 @@ func NewScanner(sources *config.LogSources, tailingLimit int, pipelineProvider p
 		tailers:             make(map[string]*Tailer),
 		registry:            registry,
 		tailerSleepDuration: tailerSleepDuration,
+		fingerprintSize:     coreConfig.Datadog.GetInt("logs_config.file_fingerprint_size"),
 		stop:                make(chan struct{}),
 	}
 }
@@ -142,7 +146,7 @@ func (s *Scanner) scan() {
 		if err != nil {
 			continue
 		}
-		if didRotate {
+		if didRotate || s.didFingerprintChange(tailer) {
 			// restart tailer because of file-rotation on file
 			succeeded := s.restartTailerAfterFileRotation(tailer, file)
 			if !succeeded {
@@ -215,9 +219,10 @@ func (s *Scanner) startNewTailer(file *File, m config.TailingMode) bool {
 
 	var offset int64
 	var whence int
-	mode := s.handleTailingModeChange(tailer.Identifier(), m)
+	identifier := s.registryIdentifier(tailer)
+	mode := s.handleTailingModeChange(identifier, m)
 
-	offset, whence, err := Position(s.registry, tailer.Identifier(), mode)
+	offset, whence, err := Position(s.registry, identifier, mode)
 	if err != nil {
 		log.Warnf("Could not recover offset for file with path %v: %v", file.Path, err)
 	}
@@ -278,7 +283,62 @@ func (s *Scanner) restartTailerAfterFileRotation(tailer *Tailer, file *File) boo
 	return true
 }
 
-// createTailer returns a new initialized tailer
+// registryIdentifier returns the identifier of the registry entry to resume the tailer from,
+// the path of a fingerprinted file is used when no offset was registered for its fingerprint
+// to migrate the entries registered before the file could be fingerprinted.
+func (s *Scanner) registryIdentifier(tailer *Tailer) string {
+	identifier := tailer.Identifier()
+	if tailer.fingerprint != "" && s.registry.GetOffset(identifier) == "" && s.registry.GetOffset(tailer.pathIdentifier()) != "" {
+		return tailer.pathIdentifier()
+	}
+	return identifier
+}
+
+// didFingerprintChange returns true if the beginning of the tailed file changed,
+// which happens when the file has been truncated and written again between two scans.
+func (s *Scanner) didFingerprintChange(tailer *Tailer) bool {
+	if tailer.fingerprint == "" {
+		return false
+	}
+	fingerprint := s.fingerprint(tailer.file)
+	return fingerprint != "" && fingerprint != tailer.fingerprint
+}
+
+// fingerprint returns the fingerprint of the file, empty if fingerprinting is disabled or failed.
+func (s *Scanner) fingerprint(file *File) string {
+	if s.fingerprintSize <= 0 {
+		return ""
+	}
+	fingerprint, err := computeFingerprint(file.Path, s.fingerprintSize)
+	if err != nil {
+		log.Debugf("Could not fingerprint file %s: %v", file.Path, err)
+	}
+	return fingerprint
+}
+
+// createTailer returns a new initialized tailer, the file is identified by its path
+// when its fingerprint is shared with another tailed file.
 func (s *Scanner) createTailer(file *File, outputChan chan *message.Message) *Tailer {
-	return NewTailer(outputChan, file, s.tailerSleepDuration)
+	tailer := NewTailer(outputChan, file, s.tailerSleepDuration)
+	tailer.fingerprint = s.fingerprint(file)
+	if tailer.fingerprint != "" && s.isFingerprintTailed(tailer.fingerprint, file.Path) {
+		log.Debugf("File %s has the same fingerprint as another tailed file, identifying it by its path", file.Path)
+		tailer.fingerprint = ""
+	}
+	return tailer
+}
+
+// isFingerprintTailed returns true if a file other than path is tailed and still has the fingerprint,
+// like files starting with the same header or the copy of a file rotated with copytruncate.
+// A tailer of a renamed file does not count as its previous path does not exist anymore.
+func (s *Scanner) isFingerprintTailed(fingerprint string, path string) bool {
+	for _, tailer := range s.tailers {
+		if tailer.fingerprint != fingerprint || tailer.file.Path == path {
+			continue
+		}
+		if s.fingerprint(tailer.file) == fingerprint {
+			return true
+		}
+	}
+	return false
 }
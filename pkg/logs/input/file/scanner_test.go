@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -390,6 +391,134 @@ func TestScannerScanWithTooManyFiles(t *testing.T) {
 	assert.Equal(t, 2, len(scanner.tailers))
 }
 
+// identifierRegistry returns the offsets registered per identifier.
+type identifierRegistry map[string]string
+
+func (r identifierRegistry) GetOffset(identifier string) string {
+	return r[identifier]
+}
+
+func (r identifierRegistry) GetTailingMode(identifier string) string {
+	return ""
+}
+
+func newFingerprintScanner(t *testing.T, path string, registry identifierRegistry) (*Scanner, *config.LogSource) {
+	scanner := NewScanner(config.NewLogSources(), 10, mock.NewMockProvider(), registry, 20*time.Millisecond)
+	scanner.fingerprintSize = 12
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+	scanner.activeSources = append(scanner.activeSources, source)
+	status.Clear()
+	status.InitStatus(config.CreateSources([]*config.LogSource{source}))
+	return scanner, source
+}
+
+func TestScannerResumesRenamedFileWithFingerprint(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+	defer status.Clear()
+
+	oldPath := fmt.Sprintf("%s/old.log", testDir)
+	assert.Nil(t, ioutil.WriteFile(oldPath, []byte("first line\nsecond line\n"), 0644))
+	fingerprint, err := computeFingerprint(oldPath, 12)
+	assert.Nil(t, err)
+	registry := identifierRegistry{"fingerprint:" + fingerprint: "11"}
+
+	// the file is renamed, it is identified by its content
+	newPath := fmt.Sprintf("%s/new.log", testDir)
+	assert.Nil(t, os.Rename(oldPath, newPath))
+	scanner, source := newFingerprintScanner(t, fmt.Sprintf("%s/*.log", testDir), registry)
+	defer scanner.cleanup()
+	scanner.scan()
+
+	tailer := scanner.tailers[getScanKey(newPath, source)]
+	assert.Equal(t, "fingerprint:"+fingerprint, tailer.Identifier())
+	msg := <-tailer.outputChan
+	assert.Equal(t, "second line", string(msg.Content))
+	assert.Equal(t, "fingerprint:"+fingerprint, msg.Origin.Identifier)
+}
+
+func TestScannerMigratesPathIdentifierToFingerprint(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+	defer status.Clear()
+
+	path := fmt.Sprintf("%s/test.log", testDir)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("first line\nsecond line\n"), 0644))
+	registry := identifierRegistry{"file:" + path: "11"}
+
+	scanner, source := newFingerprintScanner(t, path, registry)
+	defer scanner.cleanup()
+	scanner.scan()
+
+	tailer := scanner.tailers[getScanKey(path, source)]
+	msg := <-tailer.outputChan
+	assert.Equal(t, "second line", string(msg.Content))
+	assert.True(t, strings.HasPrefix(msg.Origin.Identifier, "fingerprint:"))
+}
+
+func TestScannerRestartsTailerWhenFingerprintChanges(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+	defer status.Clear()
+
+	path := fmt.Sprintf("%s/test.log", testDir)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("first line of the file\n"), 0644))
+	scanner, source := newFingerprintScanner(t, path, identifierRegistry{})
+	defer scanner.cleanup()
+	scanner.scan()
+
+	tailer := scanner.tailers[getScanKey(path, source)]
+	msg := <-tailer.outputChan
+	assert.Equal(t, "first line of the file", string(msg.Content))
+
+	// copy-truncate followed by writes beyond the previous offset before the next scan
+	assert.Nil(t, ioutil.WriteFile(path, []byte("other content\nwritten after the rotation\n"), 0644))
+	scanner.scan()
+
+	newTailer := scanner.tailers[getScanKey(path, source)]
+	assert.True(t, tailer != newTailer)
+	assert.NotEqual(t, tailer.Identifier(), newTailer.Identifier())
+
+	// the previous tailer drains the file from its offset while the new one starts from the beginning
+	var contents []string
+	for i := 0; i < 3; i++ {
+		msg = <-newTailer.outputChan
+		contents = append(contents, string(msg.Content))
+	}
+	assert.Contains(t, contents, "other content")
+	assert.Contains(t, contents, "written after the rotation")
+}
+
+func TestScannerIdentifiesFilesWithTheSameFingerprintByPath(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+	defer status.Clear()
+
+	// both files start with the same header
+	firstPath := fmt.Sprintf("%s/a.log", testDir)
+	secondPath := fmt.Sprintf("%s/b.log", testDir)
+	assert.Nil(t, ioutil.WriteFile(firstPath, []byte("date,level,message\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(secondPath, []byte("date,level,message\n"), 0644))
+	fingerprint, err := computeFingerprint(firstPath, 12)
+	assert.Nil(t, err)
+
+	scanner, source := newFingerprintScanner(t, fmt.Sprintf("%s/*.log", testDir), identifierRegistry{})
+	defer scanner.cleanup()
+	scanner.scan()
+
+	// the files are tailed in reverse lexicographical order
+	assert.Equal(t, "fingerprint:"+fingerprint, scanner.tailers[getScanKey(secondPath, source)].Identifier())
+	assert.Equal(t, "file:"+firstPath, scanner.tailers[getScanKey(firstPath, source)].Identifier())
+
+	outputChan := scanner.tailers[getScanKey(firstPath, source)].outputChan
+	identifiers := []string{(<-outputChan).Origin.Identifier, (<-outputChan).Origin.Identifier}
+	assert.ElementsMatch(t, []string{"fingerprint:" + fingerprint, "file:" + firstPath}, identifiers)
+}
+
 func getScanKey(path string, source *config.LogSource) string {
 	return NewFile(path, source, false).GetScanKey()
 }
@@ -43,6 +43,8 @@ type Tailer struct {
 	fullpath string
 	osFile   *os.File
 	tags     []string
+	// fingerprint is the checksum of the beginning of the file, empty when files are identified by their path
+	fingerprint string
 
 	outputChan  chan *message.Message
 	decoder     *decoder.Decoder
@@ -112,12 +114,20 @@ func NewTailer(outputChan chan *message.Message, file *File, sleepDuration time.
 }
 
 // Identifier returns a string that uniquely identifies a source.
-// This is the identifier used in the registry.
+// This is the identifier used in the registry, the fingerprint of the file when it has one, its path otherwise.
 // FIXME(remy): during container rotation, this Identifier() method could return
 // the same value for different tailers. It is happening during container rotation
 // where the dead container still has a tailer running on the log file, and the tailer
 // of the freshly spawned container starts tailing this file as well.
 func (t *Tailer) Identifier() string {
+	if t.fingerprint != "" {
+		return fmt.Sprintf("fingerprint:%s", t.fingerprint)
+	}
+	return t.pathIdentifier()
+}
+
+// pathIdentifier returns the identifier of the file based on its path.
+func (t *Tailer) pathIdentifier() string {
 	return fmt.Sprintf("file:%s", t.file.Path)
 }
 
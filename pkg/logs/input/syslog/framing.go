@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+// maxFrameLengthDigits is the maximum number of digits of an octet count.
+const maxFrameLengthDigits = 10
+
+// frameReader reads the syslog messages of a stream, each message is either prefixed
+// by its length (octet counting) or terminated by a line feed (non-transparent framing),
+// see RFC6587. Messages longer than maxSize are truncated.
+type frameReader struct {
+	reader  *bufio.Reader
+	maxSize int
+}
+
+func newFrameReader(r io.Reader, maxSize int) *frameReader {
+	return &frameReader{
+		reader:  bufio.NewReaderSize(r, maxSize),
+		maxSize: maxSize,
+	}
+}
+
+// next returns the next message of the stream.
+func (f *frameReader) next() ([]byte, error) {
+	for {
+		first, err := f.reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case first[0] >= '1' && first[0] <= '9':
+			return f.readOctetCounted()
+		case first[0] == '\n' || first[0] == '\r' || first[0] == 0:
+			// skip empty frames
+			f.reader.ReadByte() //nolint:errcheck
+		default:
+			return f.readLine()
+		}
+	}
+}
+
+// readOctetCounted reads a MSG-LEN SP SYSLOG-MSG frame.
+func (f *frameReader) readOctetCounted() ([]byte, error) {
+	// peek one byte at a time to not wait for data following a short frame
+	end := -1
+	for i := 1; i <= maxFrameLengthDigits+1 && end < 0; i++ {
+		header, err := f.reader.Peek(i)
+		if err != nil {
+			return f.readLine()
+		}
+		switch c := header[i-1]; {
+		case c == ' ':
+			end = i - 1
+		case c < '0' || c > '9':
+			// not an octet count, the message starts with a digit
+			return f.readLine()
+		}
+	}
+	if end < 0 {
+		return f.readLine()
+	}
+	header, _ := f.reader.Peek(end)
+	length, err := strconv.Atoi(string(header))
+	if err != nil {
+		return f.readLine()
+	}
+	f.reader.Discard(end + 1) //nolint:errcheck
+
+	size := length
+	if size > f.maxSize {
+		size = f.maxSize
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(f.reader, frame); err != nil {
+		return nil, fmt.Errorf("could not read frame of %d bytes: %v", length, err)
+	}
+	if length > size {
+		if _, err := io.CopyN(ioutil.Discard, f.reader, int64(length-size)); err != nil {
+			return nil, err
+		}
+	}
+	return frame, nil
+}
+
+// readLine reads a frame terminated by a line feed, the trailing carriage return is removed.
+func (f *frameReader) readLine() ([]byte, error) {
+	line, err := f.reader.ReadSlice('\n')
+	frame := append([]byte(nil), line...)
+	for err == bufio.ErrBufferFull {
+		// the line is too long, drop the remaining content
+		_, err = f.reader.ReadSlice('\n')
+	}
+	if err != nil && (err != io.EOF || len(frame) == 0) {
+		return nil, err
+	}
+	frame = bytes.TrimSuffix(frame, []byte("\n"))
+	return bytes.TrimSuffix(frame, []byte("\r")), nil
+}
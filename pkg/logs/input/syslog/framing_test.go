@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readFrames(t *testing.T, data string, maxSize int) []string {
+	reader := newFrameReader(strings.NewReader(data), maxSize)
+	var frames []string
+	for {
+		frame, err := reader.next()
+		if err == io.EOF {
+			return frames
+		}
+		if !assert.NoError(t, err) {
+			return frames
+		}
+		frames = append(frames, string(frame))
+	}
+}
+
+func TestFrameReaderOctetCounting(t *testing.T) {
+	frames := readFrames(t, "11 <34>1 hello9 <34>1 a\nb", 100)
+	assert.Equal(t, []string{"<34>1 hello", "<34>1 a\nb"}, frames)
+}
+
+func TestFrameReaderNonTransparentFraming(t *testing.T) {
+	frames := readFrames(t, "<34>hello\r\n\n<34>world\n<34>last", 100)
+	assert.Equal(t, []string{"<34>hello", "<34>world", "<34>last"}, frames)
+}
+
+func TestFrameReaderMixedFraming(t *testing.T) {
+	frames := readFrames(t, "5 <34>a<34>b\n42abc not an octet count\n", 100)
+	assert.Equal(t, []string{"<34>a", "<34>b", "42abc not an octet count"}, frames)
+}
+
+func TestFrameReaderTruncatesLongFrames(t *testing.T) {
+	frames := readFrames(t, "20 "+strings.Repeat("a", 20)+strings.Repeat("b", 30)+"\n<34>c\n", 16)
+	assert.Equal(t, []string{strings.Repeat("a", 16), strings.Repeat("b", 16), "<34>c"}, frames)
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/restart"
+)
+
+// Launcher starts a syslog listener for each syslog source.
+type Launcher struct {
+	pipelineProvider pipeline.Provider
+	frameSize        int
+	sources          chan *config.LogSource
+	listeners        []restart.Restartable
+	stop             chan struct{}
+}
+
+// NewLauncher returns an initialized Launcher
+func NewLauncher(sources *config.LogSources, frameSize int, pipelineProvider pipeline.Provider) *Launcher {
+	return &Launcher{
+		pipelineProvider: pipelineProvider,
+		frameSize:        frameSize,
+		sources:          sources.GetAddedForType(config.SyslogType),
+		stop:             make(chan struct{}),
+	}
+}
+
+// Start starts the launcher.
+func (l *Launcher) Start() {
+	go l.run()
+}
+
+// run starts new syslog listeners.
+func (l *Launcher) run() {
+	for {
+		select {
+		case source := <-l.sources:
+			listener := NewListener(l.pipelineProvider, source, l.frameSize)
+			listener.Start()
+			l.listeners = append(l.listeners, listener)
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// Stop stops all listeners
+func (l *Launcher) Stop() {
+	l.stop <- struct{}{}
+	stopper := restart.NewParallelStopper()
+	for _, listener := range l.listeners {
+		stopper.Add(listener)
+	}
+	stopper.Stop()
+}
@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+)
+
+// defaultTimeout represents the time after which a connection is closed when no data is read
+const defaultTimeout = time.Minute
+
+// Listener receives syslog messages over TCP, optionally with TLS, or UDP
+// and sends them to the pipeline.
+type Listener struct {
+	pipelineProvider pipeline.Provider
+	source           *config.LogSource
+	frameSize        int
+	listener         net.Listener
+	packetConn       net.PacketConn
+	conns            map[net.Conn]struct{}
+	mu               sync.Mutex
+	wg               sync.WaitGroup
+}
+
+// NewListener returns an initialized Listener
+func NewListener(pipelineProvider pipeline.Provider, source *config.LogSource, frameSize int) *Listener {
+	return &Listener{
+		pipelineProvider: pipelineProvider,
+		source:           source,
+		frameSize:        frameSize,
+		conns:            make(map[net.Conn]struct{}),
+	}
+}
+
+// Start starts to receive syslog messages.
+func (l *Listener) Start() {
+	log.Infof("Starting syslog %s listener on port %d", l.protocol(), l.source.Config.Port)
+	var err error
+	if l.protocol() == config.UDPType {
+		err = l.startUDP()
+	} else {
+		err = l.startTCP()
+	}
+	if err != nil {
+		log.Errorf("Can't start syslog %s listener on port %d: %v", l.protocol(), l.source.Config.Port, err)
+		l.source.Status.Error(err)
+		return
+	}
+	l.source.Status.Success()
+}
+
+// Stop stops receiving messages and waits for the received ones to be sent to the pipeline.
+func (l *Listener) Stop() {
+	log.Infof("Stopping syslog %s listener on port %d", l.protocol(), l.source.Config.Port)
+	l.mu.Lock()
+	if l.listener != nil {
+		l.listener.Close()
+	}
+	if l.packetConn != nil {
+		l.packetConn.Close()
+	}
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+}
+
+func (l *Listener) protocol() string {
+	if l.source.Config.Protocol == "" {
+		return config.TCPType
+	}
+	return l.source.Config.Protocol
+}
+
+// startTCP starts to accept TCP connections.
+func (l *Listener) startTCP() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", l.source.Config.Port))
+	if err != nil {
+		return err
+	}
+	if l.source.Config.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(l.source.Config.TLSCertFile, l.source.Config.TLSKeyFile)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		})
+	}
+	l.listener = listener
+	l.wg.Add(1)
+	go l.accept()
+	return nil
+}
+
+// accept accepts new TCP connections and reads each of them in a dedicated goroutine.
+func (l *Listener) accept() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if !isClosedConnError(err) {
+				log.Warnf("Can't accept syslog connection on port %d: %v", l.source.Config.Port, err)
+				l.source.Status.Error(err)
+			}
+			return
+		}
+		l.mu.Lock()
+		l.conns[conn] = struct{}{}
+		l.wg.Add(1)
+		l.mu.Unlock()
+		go l.readConn(conn)
+	}
+}
+
+// readConn reads the messages of a TCP connection until it is closed.
+func (l *Listener) readConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		l.wg.Done()
+	}()
+	outputChan := l.pipelineProvider.NextPipelineChan()
+	frames := newFrameReader(conn, l.frameSize)
+	for {
+		conn.SetReadDeadline(time.Now().Add(defaultTimeout)) //nolint:errcheck
+		frame, err := frames.next()
+		if err != nil {
+			if err != io.EOF && !isClosedConnError(err) {
+				log.Debugf("Couldn't read syslog message from connection: %v", err)
+			}
+			return
+		}
+		l.forward(frame, outputChan)
+	}
+}
+
+// startUDP starts to read UDP packets.
+func (l *Listener) startUDP() error {
+	packetConn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", l.source.Config.Port))
+	if err != nil {
+		return err
+	}
+	l.packetConn = packetConn
+	l.wg.Add(1)
+	go l.readPackets()
+	return nil
+}
+
+// readPackets reads UDP packets, each packet holds a single message.
+func (l *Listener) readPackets() {
+	defer l.wg.Done()
+	outputChan := l.pipelineProvider.NextPipelineChan()
+	buf := make([]byte, l.frameSize)
+	for {
+		n, _, err := l.packetConn.ReadFrom(buf)
+		if err != nil {
+			if !isClosedConnError(err) {
+				log.Warnf("Can't read syslog packet on port %d: %v", l.source.Config.Port, err)
+				l.source.Status.Error(err)
+			}
+			return
+		}
+		frame := make([]byte, n)
+		copy(frame, buf[:n])
+		l.forward(trimTrailingNewLines(frame), outputChan)
+	}
+}
+
+// forward parses a syslog message and sends it to the pipeline,
+// messages that can not be parsed are sent as is.
+func (l *Listener) forward(frame []byte, outputChan chan *message.Message) {
+	if len(frame) == 0 {
+		return
+	}
+	l.source.BytesRead.Add(int64(len(frame)))
+	now := time.Now()
+	syslogMsg, err := parse(frame, now)
+	if err != nil {
+		log.Debugf("Could not parse syslog message, sending it as is: %v", err)
+		outputChan <- message.NewMessageWithSource(frame, message.StatusInfo, l.source, now.UnixNano())
+		return
+	}
+	msg := message.NewMessageWithSource(syslogMsg.msg, syslogMsg.status(), l.source, now.UnixNano())
+	msg.Timestamp = syslogMsg.timestamp
+	msg.Attributes = map[string]interface{}{
+		"syslog": syslogMsg.attributes(),
+	}
+	outputChan <- msg
+}
+
+func trimTrailingNewLines(frame []byte) []byte {
+	for len(frame) > 0 && (frame[len(frame)-1] == '\n' || frame[len(frame)-1] == '\r' || frame[len(frame)-1] == 0) {
+		frame = frame[:len(frame)-1]
+	}
+	return frame
+}
+
+// isClosedConnError returns true if the error is related to a closed connection,
+// for more details, see: https://golang.org/src/internal/poll/fd.go#L18.
+func isClosedConnError(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
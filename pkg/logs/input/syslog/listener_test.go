@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+)
+
+func TestTCPListenerReceivesSyslogMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Port: 0})
+	listener := NewListener(pp, source, 9000)
+	listener.Start()
+	defer listener.Stop()
+	assert.True(t, source.Status.IsSuccess())
+
+	conn, err := net.Dial("tcp", listener.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	content := `<165>1 2003-10-11T22:14:15.003Z host app 12 ID47 [meta key="value"] hello world`
+	fmt.Fprintf(conn, "%d %s", len(content), content)
+
+	var msg *message.Message
+	select {
+	case msg = <-msgChan:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no message received")
+	}
+	assert.Equal(t, "hello world", string(msg.Content))
+	assert.Equal(t, message.StatusNotice, msg.GetStatus())
+	assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), msg.Timestamp)
+	assert.Equal(t, map[string]interface{}{
+		"syslog": map[string]interface{}{
+			"facility":        20,
+			"severity":        5,
+			"hostname":        "host",
+			"appname":         "app",
+			"procid":          "12",
+			"msgid":           "ID47",
+			"structured_data": map[string]map[string]string{"meta": {"key": "value"}},
+		},
+	}, msg.Attributes)
+	assert.Equal(t, int64(len(content)), source.BytesRead.Value())
+
+	fmt.Fprintf(conn, "not a syslog message\n")
+	select {
+	case msg = <-msgChan:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no message received")
+	}
+	assert.Equal(t, "not a syslog message", string(msg.Content))
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Nil(t, msg.Attributes)
+}
+
+func TestUDPListenerReceivesSyslogMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Protocol: config.UDPType, Port: 0})
+	listener := NewListener(pp, source, 9000)
+	listener.Start()
+	defer listener.Stop()
+	assert.True(t, source.Status.IsSuccess())
+
+	conn, err := net.Dial("udp", listener.packetConn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "<11>Oct 11 22:14:15 host cron[42]: job failed\n")
+
+	var msg *message.Message
+	select {
+	case msg = <-msgChan:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no message received")
+	}
+	assert.Equal(t, "job failed", string(msg.Content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	attributes := msg.Attributes["syslog"].(map[string]interface{})
+	assert.Equal(t, "host", attributes["hostname"])
+	assert.Equal(t, "cron", attributes["appname"])
+	assert.Equal(t, "42", attributes["procid"])
+}
+
+func TestListenerReportsInvalidTLSConfiguration(t *testing.T) {
+	pp := mock.NewMockProvider()
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:        config.SyslogType,
+		Port:        0,
+		TLSCertFile: "/does/not/exist.crt",
+		TLSKeyFile:  "/does/not/exist.key",
+	})
+	listener := NewListener(pp, source, 9000)
+	listener.Start()
+	defer listener.Stop()
+	assert.True(t, source.Status.IsError())
+}
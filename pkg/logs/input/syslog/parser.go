@@ -0,0 +1,269 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const nilValue = "-"
+
+// utf8BOM may prefix the MSG part of a RFC5424 message.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// severityStatuses maps the syslog severities to a status.
+var severityStatuses = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+// syslogMessage is a message parsed following RFC5424 or RFC3164.
+type syslogMessage struct {
+	facility       int
+	severity       int
+	timestamp      time.Time
+	hostname       string
+	appName        string
+	procID         string
+	msgID          string
+	structuredData map[string]map[string]string
+	msg            []byte
+}
+
+// status returns the status matching the severity of the message.
+func (m *syslogMessage) status() string {
+	if m.severity < 0 || m.severity >= len(severityStatuses) {
+		return message.StatusInfo
+	}
+	return severityStatuses[m.severity]
+}
+
+// attributes returns the header fields and the structured data of the message.
+func (m *syslogMessage) attributes() map[string]interface{} {
+	attributes := map[string]interface{}{
+		"facility": m.facility,
+		"severity": m.severity,
+	}
+	for key, value := range map[string]string{
+		"hostname": m.hostname,
+		"appname":  m.appName,
+		"procid":   m.procID,
+		"msgid":    m.msgID,
+	} {
+		if value != "" {
+			attributes[key] = value
+		}
+	}
+	if len(m.structuredData) > 0 {
+		attributes["structured_data"] = m.structuredData
+	}
+	return attributes
+}
+
+// parse parses a RFC5424 message, or a RFC3164 message when no version follows the priority.
+// now is used to guess the year of RFC3164 timestamps.
+func parse(data []byte, now time.Time) (*syslogMessage, error) {
+	m := &syslogMessage{}
+	rest, err := m.parsePriority(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 1 && rest[0] == '1' && rest[1] == ' ' {
+		return m, m.parseRFC5424(rest[2:])
+	}
+	m.parseRFC3164(rest, now)
+	return m, nil
+}
+
+// parsePriority parses the <PRI> header, returns the remaining data.
+func (m *syslogMessage) parsePriority(data []byte) ([]byte, error) {
+	if len(data) < 3 || data[0] != '<' {
+		return nil, fmt.Errorf("missing priority")
+	}
+	end := bytes.IndexByte(data[:min(len(data), 5)], '>')
+	if end < 2 {
+		return nil, fmt.Errorf("invalid priority")
+	}
+	// the priority is made of 1 to 3 digits, strconv.Atoi would also accept a sign
+	for _, c := range data[1:end] {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid priority %q", data[1:end])
+		}
+	}
+	pri, err := strconv.Atoi(string(data[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return nil, fmt.Errorf("invalid priority %q", data[1:end])
+	}
+	m.facility = pri / 8
+	m.severity = pri % 8
+	return data[end+1:], nil
+}
+
+// parseRFC5424 parses TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG].
+func (m *syslogMessage) parseRFC5424(data []byte) error {
+	var fields [5]string
+	for i := range fields {
+		var field []byte
+		field, data = nextField(data)
+		if len(field) == 0 {
+			return fmt.Errorf("missing header field")
+		}
+		if string(field) != nilValue {
+			fields[i] = string(field)
+		}
+	}
+	if fields[0] != "" {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid timestamp: %v", err)
+		}
+		m.timestamp = ts.UTC()
+	}
+	m.hostname, m.appName, m.procID, m.msgID = fields[1], fields[2], fields[3], fields[4]
+
+	rest, err := m.parseStructuredData(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	m.msg = bytes.TrimPrefix(rest, utf8BOM)
+	return nil
+}
+
+// parseStructuredData parses the [SD-ID PARAM="VALUE"...] elements, returns the remaining data.
+func (m *syslogMessage) parseStructuredData(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("missing structured data")
+	}
+	if data[0] == '-' {
+		return data[1:], nil
+	}
+	m.structuredData = make(map[string]map[string]string)
+	for len(data) > 0 && data[0] == '[' {
+		end := bytes.IndexAny(data, " ]")
+		if end < 2 {
+			return nil, fmt.Errorf("invalid structured data element")
+		}
+		params := make(map[string]string)
+		m.structuredData[string(data[1:end])] = params
+		data = data[end:]
+		for len(data) > 0 && data[0] == ' ' {
+			data = data[1:]
+			eq := bytes.IndexByte(data, '=')
+			if eq < 1 || len(data) < eq+2 || data[eq+1] != '"' {
+				return nil, fmt.Errorf("invalid structured data parameter")
+			}
+			name := string(data[:eq])
+			value, rest, err := parseParamValue(data[eq+2:])
+			if err != nil {
+				return nil, err
+			}
+			params[name] = value
+			data = rest
+		}
+		if len(data) == 0 || data[0] != ']' {
+			return nil, fmt.Errorf("unterminated structured data element")
+		}
+		data = data[1:]
+	}
+	return data, nil
+}
+
+// parseParamValue parses a quoted parameter value after its opening quote,
+// returns the unescaped value and the data following the closing quote.
+func parseParamValue(data []byte) (string, []byte, error) {
+	var value []byte
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			if i+1 < len(data) && (data[i+1] == '"' || data[i+1] == '\\' || data[i+1] == ']') {
+				i++
+			}
+			value = append(value, data[i])
+		case '"':
+			return string(value), data[i+1:], nil
+		default:
+			value = append(value, data[i])
+		}
+	}
+	return "", nil, fmt.Errorf("unterminated structured data parameter value")
+}
+
+// parseRFC3164 parses TIMESTAMP HOSTNAME TAG[PID]: MSG, it never fails as the format
+// is loosely followed, the data that can not be parsed is kept in the message.
+func (m *syslogMessage) parseRFC3164(data []byte, now time.Time) {
+	m.msg = data
+	// Mmm dd hh:mm:ss
+	const stampLength = len(time.Stamp)
+	if len(data) < stampLength+1 || data[stampLength] != ' ' {
+		return
+	}
+	ts, err := time.ParseInLocation(time.Stamp, string(data[:stampLength]), now.Location())
+	if err != nil {
+		return
+	}
+	ts = ts.AddDate(now.Year(), 0, 0)
+	// messages sent right before the new year are received after it
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	m.timestamp = ts.UTC()
+	data = data[stampLength+1:]
+
+	// the hostname is optional, a field ending like a tag is not a hostname
+	if hostname, rest := nextField(data); len(hostname) > 0 && !bytes.ContainsAny(hostname, ":[]") {
+		m.hostname = string(hostname)
+		data = rest
+	}
+	m.msg = data
+
+	// the tag is made of alphanumeric characters and ends with ':' or '[' when followed by the pid
+	end := bytes.IndexAny(data, ":[ ")
+	if end < 1 || data[end] == ' ' {
+		return
+	}
+	m.appName = string(data[:end])
+	data = data[end:]
+	if data[0] == '[' {
+		pidEnd := bytes.IndexByte(data, ']')
+		if pidEnd < 0 {
+			return
+		}
+		m.procID = string(data[1:pidEnd])
+		data = data[pidEnd+1:]
+	}
+	data = bytes.TrimPrefix(data, []byte(":"))
+	m.msg = bytes.TrimPrefix(data, []byte(" "))
+}
+
+// nextField returns the data until the next space and the data following it.
+func nextField(data []byte) ([]byte, []byte) {
+	end := bytes.IndexByte(data, ' ')
+	if end < 0 {
+		return data, nil
+	}
+	return data[:end], data[end+1:]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	data := []byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][meta escaped="a\"b\]c"] ` + "\xEF\xBB\xBF" + `An application event log entry...`)
+	m, err := parse(data, time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, m.facility)
+	assert.Equal(t, 5, m.severity)
+	assert.Equal(t, message.StatusNotice, m.status())
+	assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), m.timestamp)
+	assert.Equal(t, "mymachine.example.com", m.hostname)
+	assert.Equal(t, "evntslog", m.appName)
+	assert.Equal(t, "1234", m.procID)
+	assert.Equal(t, "ID47", m.msgID)
+	assert.Equal(t, map[string]map[string]string{
+		"exampleSDID@32473": {"iut": "3", "eventSource": "Application", "eventID": "1011"},
+		"meta":              {"escaped": `a"b]c`},
+	}, m.structuredData)
+	assert.Equal(t, "An application event log entry...", string(m.msg))
+}
+
+func TestParseRFC5424WithNilValues(t *testing.T) {
+	m, err := parse([]byte("<34>1 - - - - - -"), time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, m.facility)
+	assert.Equal(t, 2, m.severity)
+	assert.True(t, m.timestamp.IsZero())
+	assert.Equal(t, map[string]interface{}{"facility": 4, "severity": 2}, m.attributes())
+	assert.Empty(t, m.msg)
+}
+
+func TestParseRFC5424Invalid(t *testing.T) {
+	for _, data := range []string{
+		"<34>1 2003-10-11T22:14:15.003Z host",
+		"<34>1 not-a-timestamp host app - - - msg",
+		"<34>1 - host app - - [unterminated msg",
+		`<34>1 - host app - - [id key="value] msg`,
+	} {
+		_, err := parse([]byte(data), time.Now())
+		assert.Error(t, err, data)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	now := time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC)
+	m, err := parse([]byte("<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8"), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, m.facility)
+	assert.Equal(t, 2, m.severity)
+	assert.Equal(t, message.StatusCritical, m.status())
+	assert.Equal(t, time.Date(2021, 10, 11, 22, 14, 15, 0, time.UTC), m.timestamp)
+	assert.Equal(t, "mymachine", m.hostname)
+	assert.Equal(t, "su", m.appName)
+	assert.Equal(t, "230", m.procID)
+	assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", string(m.msg))
+}
+
+func TestParseRFC3164WithoutHostname(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	m, err := parse([]byte("<13>Dec 31 23:59:59 cron: job done"), now)
+	require.NoError(t, err)
+
+	// the message was sent during the previous year
+	assert.Equal(t, time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC), m.timestamp)
+	assert.Equal(t, "", m.hostname)
+	assert.Equal(t, "cron", m.appName)
+	assert.Equal(t, "job done", string(m.msg))
+}
+
+func TestParseRFC3164WithoutHeader(t *testing.T) {
+	m, err := parse([]byte("<13>just a message"), time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, m.facility)
+	assert.Equal(t, 5, m.severity)
+	assert.True(t, m.timestamp.IsZero())
+	assert.Equal(t, "just a message", string(m.msg))
+}
+
+func TestParseInvalidPriority(t *testing.T) {
+	for _, data := range []string{"", "hello", "<>1 - - - - - -", "<>msg", "<-1>msg", "<+5>msg", "<abc>msg", "<192>msg", "<1234567>msg"} {
+		_, err := parse([]byte(data), time.Now())
+		assert.Error(t, err, data)
+	}
+}
+
+func TestStatusOutOfRangeSeverity(t *testing.T) {
+	assert.Equal(t, message.StatusInfo, (&syslogMessage{severity: -1}).status())
+	assert.Equal(t, message.StatusInfo, (&syslogMessage{severity: 8}).status())
+	assert.Equal(t, message.StatusDebug, (&syslogMessage{severity: 7}).status())
+}
@@ -98,6 +98,7 @@ func start(getAC func() *autodiscovery.AutoConfig, serverless bool, logsChan cha
 	if !serverless {
 		// regular logs agent
 		log.Info("Starting logs-agent...")
+		endpoints.SpillBuffer = config.BuildSpillBuffer()
 		agent = NewAgent(sources, services, processingRules, endpoints)
 	} else {
 		// serverless logs agent
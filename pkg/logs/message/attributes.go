@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package message
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// maxSDParamNameLength is the maximum length of a structured data parameter name defined by RFC5424.
+const maxSDParamNameLength = 32
+
+// sdValueEscaper escapes the characters that are not allowed in a structured data value.
+var sdValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// AttributesPayload returns the attributes as a RFC5424 structured data element,
+// [attrs key="value" ...], or an empty payload if there is no attribute.
+// Values that are not strings are encoded in JSON.
+func AttributesPayload(attributes map[string]interface{}) []byte {
+	if len(attributes) == 0 {
+		return []byte{}
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	payload := []byte("[attrs")
+	for _, key := range keys {
+		value, ok := attributes[key].(string)
+		if !ok {
+			encoded, err := json.Marshal(attributes[key])
+			if err != nil {
+				continue
+			}
+			value = string(encoded)
+		}
+		payload = append(payload, ' ')
+		payload = append(payload, sdParamName(key)...)
+		payload = append(payload, '=', '"')
+		payload = append(payload, sdValueEscaper.Replace(value)...)
+		payload = append(payload, '"')
+	}
+	return append(payload, ']')
+}
+
+// sdParamName returns a valid structured data parameter name, made of at most 32 printable
+// characters other than '=', ' ', ']' and '"'.
+func sdParamName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+	if name == "" {
+		return "_"
+	}
+	if len(name) > maxSDParamNameLength {
+		return name[:maxSDParamNameLength]
+	}
+	return name
+}
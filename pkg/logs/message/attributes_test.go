@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributesPayload(t *testing.T) {
+	assert.Equal(t, []byte{}, AttributesPayload(nil))
+
+	payload := AttributesPayload(map[string]interface{}{
+		"user":      `john "doe"`,
+		"retry":     true,
+		"http code": 500,
+		"syslog":    map[string]interface{}{"appname": "app"},
+	})
+	assert.Equal(t, `[attrs http_code="500" retry="true" syslog="{\"appname\":\"app\"}" user="john \"doe\""]`, string(payload))
+}
@@ -23,6 +23,9 @@ type Message struct {
 	// Optional.
 	// Used in the Serverless Agent
 	Lambda *Lambda
+	// Optional.
+	// Attributes extracted from the content by the parsing rules
+	Attributes map[string]interface{}
 }
 
 // Lambda is a struct storing information about the Lambda function and function execution.
@@ -70,6 +73,11 @@ func (m *Message) GetStatus() string {
 	return m.status
 }
 
+// SetStatus sets the status of the message.
+func (m *Message) SetStatus(status string) {
+	m.status = status
+}
+
 // GetLatency returns the latency delta from ingestion time until now
 func (m *Message) GetLatency() int64 {
 	return time.Now().UnixNano() - m.IngestionTimestamp
@@ -88,6 +88,12 @@ func (o *Origin) SetTags(tags []string) {
 	o.tags = tags
 }
 
+// AddTags appends tags to the ones already set on the origin.
+func (o *Origin) AddTags(tags ...string) {
+	// force a copy as the current tags may be shared with the tailer
+	o.tags = append(o.tags[:len(o.tags):len(o.tags)], tags...)
+}
+
 // SetSource sets the source of the origin.
 func (o *Origin) SetSource(source string) {
 	o.source = source
@@ -105,3 +105,18 @@ func TestDefaultServiceValueIsServiceFromConfig(t *testing.T) {
 	origin.SetService("bar")
 	assert.Equal(t, "bar", origin.Service())
 }
+
+func TestAddTagsDoesNotAlterSharedTags(t *testing.T) {
+	cfg := &config.LogsConfig{}
+	source := config.NewLogSource("", cfg)
+	shared := make([]string, 1, 10)
+	shared[0] = "foo:bar"
+	origin := NewOrigin(source)
+	origin.SetTags(shared)
+	origin.AddTags("env:prod")
+	other := NewOrigin(source)
+	other.SetTags(shared)
+	other.AddTags("env:staging")
+	assert.Equal(t, []string{"foo:bar", "env:prod"}, origin.Tags())
+	assert.Equal(t, []string{"foo:bar", "env:staging"}, other.Tags())
+}
@@ -5,6 +5,8 @@
 
 package message
 
+import "strings"
+
 // Status values
 const (
 	StatusEmergency = "emergency"
@@ -48,3 +50,30 @@ func StatusToSeverity(status string) []byte {
 	}
 	return SevInfo
 }
+
+// statusAliases maps the most common level names found in logs to a status.
+var statusAliases = map[string]string{
+	"emerg":       StatusEmergency,
+	"emergency":   StatusEmergency,
+	"panic":       StatusEmergency,
+	"alert":       StatusAlert,
+	"crit":        StatusCritical,
+	"critical":    StatusCritical,
+	"fatal":       StatusCritical,
+	"err":         StatusError,
+	"error":       StatusError,
+	"warn":        StatusWarning,
+	"warning":     StatusWarning,
+	"notice":      StatusNotice,
+	"info":        StatusInfo,
+	"information": StatusInfo,
+	"debug":       StatusDebug,
+	"trace":       StatusDebug,
+}
+
+// StatusFromString transforms a level name into a status,
+// returns false if the level is not recognized.
+func StatusFromString(level string) (string, bool) {
+	status, exists := statusAliases[strings.ToLower(strings.TrimSpace(level))]
+	return status, exists
+}
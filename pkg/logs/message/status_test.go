@@ -33,3 +33,20 @@ func TestStatusToSeverity(t *testing.T) {
 	// default value should be "info"
 	assert.Equal(t, 0, bytes.Compare(SevInfo, StatusToSeverity("foo")))
 }
+
+func TestStatusFromString(t *testing.T) {
+	status, ok := StatusFromString("ERROR")
+	assert.True(t, ok)
+	assert.Equal(t, StatusError, status)
+
+	status, ok = StatusFromString(" warning ")
+	assert.True(t, ok)
+	assert.Equal(t, StatusWarning, status)
+
+	status, ok = StatusFromString("fatal")
+	assert.True(t, ok)
+	assert.Equal(t, StatusCritical, status)
+
+	_, ok = StatusFromString("foo")
+	assert.False(t, ok)
+}
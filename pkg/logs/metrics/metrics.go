@@ -51,6 +51,41 @@ var (
 	// TlmEncodedBytesSent is the total number of sent bytes after encoding if any
 	TlmEncodedBytesSent = telemetry.NewCounter("logs", "encoded_bytes_sent",
 		nil, "Total number of sent bytes after encoding if any")
+	// EncodingDuration is the total time spent encoding payloads, in nanoseconds
+	EncodingDuration = expvar.Int{}
+	// TlmEncodingInputBytes is the total number of bytes before encoding per content encoding
+	TlmEncodingInputBytes = telemetry.NewCounter("logs", "encoding_input_bytes",
+		[]string{"encoding"}, "Total number of bytes before encoding per content encoding")
+	// TlmEncodingOutputBytes is the total number of bytes after encoding per content encoding
+	TlmEncodingOutputBytes = telemetry.NewCounter("logs", "encoding_output_bytes",
+		[]string{"encoding"}, "Total number of bytes after encoding per content encoding")
+	// TlmEncodingRatio is the compression ratio of the last payload encoded per content encoding
+	TlmEncodingRatio = telemetry.NewGauge("logs", "encoding_ratio",
+		[]string{"encoding"}, "Compression ratio of the last payload encoded per content encoding")
+	// TlmEncodingDuration is the total time spent encoding payloads per content encoding
+	TlmEncodingDuration = telemetry.NewCounter("logs", "encoding_seconds",
+		[]string{"encoding"}, "Total time spent encoding payloads per content encoding, in seconds")
+	// TlmSpilledPayloads is the total number of payloads stored on disk while the main destination was unavailable
+	TlmSpilledPayloads = telemetry.NewCounter("logs", "spilled_payloads",
+		nil, "Total number of payloads stored on disk while the main destination was unavailable")
+	// TlmSpillEvicted is the total number of spilled payloads evicted because the spill buffer was full
+	TlmSpillEvicted = telemetry.NewCounter("logs", "spill_evicted_payloads",
+		nil, "Total number of spilled payloads evicted because the spill buffer was full")
+	// TlmSpillReplayed is the total number of spilled payloads sent once the main destination recovered
+	TlmSpillReplayed = telemetry.NewCounter("logs", "spill_replayed_payloads",
+		nil, "Total number of spilled payloads sent once the main destination recovered")
+	// TlmSpillBufferSize is the current size of the spill buffer, in bytes
+	TlmSpillBufferSize = telemetry.NewGauge("logs", "spill_buffer_bytes",
+		[]string{"path"}, "Current size of the spill buffer, in bytes")
+	// TlmSpillBufferFiles is the current number of payloads stored in the spill buffer
+	TlmSpillBufferFiles = telemetry.NewGauge("logs", "spill_buffer_payloads",
+		[]string{"path"}, "Current number of payloads stored in the spill buffer")
+	// TlmLogsRateLimited is the total number of logs dropped because their source exceeded its rate limit
+	TlmLogsRateLimited = telemetry.NewCounter("logs", "rate_limited",
+		[]string{"source"}, "Total number of logs dropped because their source exceeded its rate limit")
+	// TlmLogsSampledOut is the total number of logs dropped by a sampling rule
+	TlmLogsSampledOut = telemetry.NewCounter("logs", "sampled_out",
+		[]string{"source"}, "Total number of logs dropped by a sampling rule")
 	// TODO: Add LogsCollected for the total number of collected logs.
 
 )
@@ -64,4 +99,5 @@ func init() {
 	LogsExpvars.Set("DestinationLogsDropped", &DestinationLogsDropped)
 	LogsExpvars.Set("BytesSent", &BytesSent)
 	LogsExpvars.Set("EncodedBytesSent", &EncodedBytesSent)
+	LogsExpvars.Set("EncodingDuration", &EncodingDuration)
 }
@@ -10,6 +10,8 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/kafka"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/syslog"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/tcp"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
@@ -26,20 +28,24 @@ type Pipeline struct {
 }
 
 // NewPipeline returns a new Pipeline
-func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver, serverless bool) *Pipeline {
+func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver, serverless bool, diskBuffer *sender.DiskBuffer, metricGenerator *processor.MetricGenerator) *Pipeline {
 	var destinations *client.Destinations
 	if endpoints.UseHTTP {
 		main := http.NewDestination(endpoints.Main, http.JSONContentType, destinationsContext)
 		additionals := []client.Destination{}
 		for _, endpoint := range endpoints.Additionals {
-			additionals = append(additionals, http.NewDestination(endpoint, http.JSONContentType, destinationsContext))
+			additionals = append(additionals, newAdditionalDestination(endpoint, destinationsContext, func() client.Destination {
+				return http.NewDestination(endpoint, http.JSONContentType, destinationsContext)
+			}))
 		}
 		destinations = client.NewDestinations(main, additionals)
 	} else {
 		main := tcp.NewDestination(endpoints.Main, endpoints.UseProto, destinationsContext)
 		additionals := []client.Destination{}
 		for _, endpoint := range endpoints.Additionals {
-			additionals = append(additionals, tcp.NewDestination(endpoint, endpoints.UseProto, destinationsContext))
+			additionals = append(additionals, newAdditionalDestination(endpoint, destinationsContext, func() client.Destination {
+				return tcp.NewDestination(endpoint, endpoints.UseProto, destinationsContext)
+			}))
 		}
 		destinations = client.NewDestinations(main, additionals)
 	}
@@ -52,7 +58,12 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 	} else {
 		strategy = sender.StreamStrategy
 	}
-	sender := sender.NewSender(senderChan, outputChan, destinations, strategy)
+	var logsSender *sender.Sender
+	if diskBuffer != nil {
+		logsSender = sender.NewSenderWithDiskBuffer(senderChan, outputChan, destinations, strategy, diskBuffer)
+	} else {
+		logsSender = sender.NewSender(senderChan, outputChan, destinations, strategy)
+	}
 
 	var encoder processor.Encoder
 	if serverless {
@@ -66,12 +77,25 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 	}
 
 	inputChan := make(chan *message.Message, config.ChanSize)
-	processor := processor.New(inputChan, senderChan, processingRules, encoder, diagnosticMessageReceiver)
+	processor := processor.New(inputChan, senderChan, processingRules, encoder, diagnosticMessageReceiver, metricGenerator)
 
 	return &Pipeline{
 		InputChan: inputChan,
 		processor: processor,
-		sender:    sender,
+		sender:    logsSender,
+	}
+}
+
+// newAdditionalDestination returns a destination for the protocol of the endpoint,
+// or the default one when the endpoint uses the protocol of the main endpoint.
+func newAdditionalDestination(endpoint config.Endpoint, destinationsContext *client.DestinationsContext, defaultDestination func() client.Destination) client.Destination {
+	switch endpoint.Protocol {
+	case config.SyslogProtocol:
+		return syslog.NewDestination(endpoint, destinationsContext)
+	case config.KafkaProtocol:
+		return kafka.NewDestination(endpoint, destinationsContext)
+	default:
+		return defaultDestination()
 	}
 }
 
@@ -7,15 +7,21 @@ package pipeline
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"sync/atomic"
 
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/processor"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
+	"github.com/DataDog/datadog-agent/pkg/logs/sender"
 )
 
 // Provider provides message channels
@@ -39,6 +45,7 @@ type provider struct {
 	pipelines            []*Pipeline
 	currentPipelineIndex int32
 	destinationsContext  *client.DestinationsContext
+	metricGenerator      *processor.MetricGenerator
 
 	serverless bool
 }
@@ -70,14 +77,32 @@ func newProvider(numberOfPipelines int, auditor auditor.Auditor, diagnosticMessa
 func (p *provider) Start() {
 	// This requires the auditor to be started before.
 	p.outputChan = p.auditor.Channel()
+	// the metrics generated by all the pipelines are committed together
+	p.metricGenerator = processor.NewMetricGenerator()
+	p.metricGenerator.Start()
 
 	for i := 0; i < p.numberOfPipelines; i++ {
-		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless)
+		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.diagnosticMessageReceiver, p.serverless, p.newDiskBuffer(i), p.metricGenerator)
 		pipeline.Start()
 		p.pipelines = append(p.pipelines, pipeline)
 	}
 }
 
+// newDiskBuffer returns the disk buffer of the i-th pipeline, the maximum size is shared between the pipelines.
+// Returns nil if the disk buffer is disabled or could not be created.
+func (p *provider) newDiskBuffer(i int) *sender.DiskBuffer {
+	if p.serverless || p.endpoints.SpillBuffer == nil {
+		return nil
+	}
+	path := filepath.Join(p.endpoints.SpillBuffer.Path, fmt.Sprintf("pipeline_%d", i))
+	diskBuffer, err := sender.NewDiskBuffer(path, p.endpoints.SpillBuffer.MaxSizeInBytes/int64(p.numberOfPipelines))
+	if err != nil {
+		log.Warnf("Could not create the disk buffer %s, payloads will not be stored on disk: %v", path, err)
+		return nil
+	}
+	return diskBuffer
+}
+
 // Stop stops all pipelines in parallel,
 // this call blocks until all pipelines are stopped
 func (p *provider) Stop() {
@@ -86,6 +111,7 @@ func (p *provider) Stop() {
 		stopper.Add(pipeline)
 	}
 	stopper.Stop()
+	p.metricGenerator.Stop()
 	p.pipelines = p.pipelines[:0]
 	p.outputChan = nil
 }
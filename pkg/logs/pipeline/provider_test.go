@@ -6,9 +6,12 @@
 package pipeline
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 
 	"github.com/stretchr/testify/suite"
@@ -20,12 +23,18 @@ import (
 
 type ProviderTestSuite struct {
 	suite.Suite
-	p *provider
-	a *auditor.RegistryAuditor
+	p       *provider
+	a       *auditor.RegistryAuditor
+	testDir string
 }
 
 func (suite *ProviderTestSuite) SetupTest() {
-	suite.a = auditor.New("", auditor.DefaultRegistryFilename, time.Hour, health.RegisterLiveness("fake"))
+	var err error
+	suite.testDir, err = ioutil.TempDir("", "tests")
+	suite.Nil(err)
+	coreConfig.Mock().Set("logs_config.run_path", suite.testDir)
+
+	suite.a = auditor.New(coreConfig.Datadog.GetString("logs_config.run_path"), auditor.DefaultRegistryFilename, time.Hour, health.RegisterLiveness("fake"))
 	suite.p = &provider{
 		numberOfPipelines: 3,
 		auditor:           suite.a,
@@ -34,6 +43,10 @@ func (suite *ProviderTestSuite) SetupTest() {
 	}
 }
 
+func (suite *ProviderTestSuite) TearDownTest() {
+	os.RemoveAll(suite.testDir)
+}
+
 func (suite *ProviderTestSuite) TestProvider() {
 	suite.a.Start()
 	suite.p.Start()
@@ -56,6 +56,21 @@ func TestRawEncoder(t *testing.T) {
 
 }
 
+func TestRawEncoderWithAttributes(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{Source: "Source"})
+
+	msg := newMessage([]byte("message"), source, message.StatusError)
+	msg.Attributes = map[string]interface{}{"user": "bob"}
+
+	raw, err := RawEncoder.Encode(msg, []byte("redacted"))
+	assert.Nil(t, err)
+
+	content := string(raw)
+	extra := content[strings.Index(content, "[") : strings.LastIndex(content, "]")+1]
+	assert.Equal(t, "[dd ddsource=\"Source\"][attrs user=\"bob\"]", extra)
+	assert.Equal(t, "redacted", content[strings.LastIndex(content, " ")+1:])
+}
+
 func TestRawEncoderDefaults(t *testing.T) {
 
 	logsConfig := &config.LogsConfig{}
@@ -221,6 +236,36 @@ func TestJsonEncoder(t *testing.T) {
 	assert.NotEmpty(t, log.Timestamp)
 }
 
+func TestJsonEncoderWithAttributes(t *testing.T) {
+	logsConfig := &config.LogsConfig{
+		Service: "Service",
+		Source:  "Source",
+	}
+
+	source := config.NewLogSource("", logsConfig)
+
+	msg := newMessage([]byte("message"), source, message.StatusError)
+	msg.Timestamp = time.Unix(1600000000, 0).UTC()
+	msg.Attributes = map[string]interface{}{
+		"user":    "bob",
+		"service": "overridden",
+	}
+
+	jsonMessage, err := JSONEncoder.Encode(msg, []byte("redacted"))
+	assert.Nil(t, err)
+
+	var log map[string]interface{}
+	err = json.Unmarshal(jsonMessage, &log)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "bob", log["user"])
+	assert.Equal(t, "Service", log["service"])
+	assert.Equal(t, "Source", log["ddsource"])
+	assert.Equal(t, "redacted", log["message"])
+	assert.Equal(t, message.StatusError, log["status"])
+	assert.Equal(t, float64(1600000000000), log["timestamp"])
+}
+
 func TestEncoderToValidUTF8(t *testing.T) {
 	assert.Equal(t, "a�z", toValidUtf8([]byte("a\xfez")))
 	assert.Equal(t, "a��z", toValidUtf8([]byte("a\xc0\xafz")))
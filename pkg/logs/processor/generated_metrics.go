@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const (
+	// generatedMetricsSenderID identifies the aggregator sender of the metrics generated from logs
+	generatedMetricsSenderID check.ID = "logs_generated_metrics"
+	// generatedMetricsCommitInterval is the interval at which the generated metrics are flushed to the aggregator
+	generatedMetricsCommitInterval = 15 * time.Second
+)
+
+// MetricGenerator submits the metrics of the generate_metric rules to the aggregator.
+// A single generator is shared by the processors of all the pipelines so that the metrics
+// are committed once per interval, the sender is retrieved on the first submission as
+// the aggregator may not run in every agent.
+type MetricGenerator struct {
+	sender    aggregator.Sender
+	err       error
+	once      sync.Once
+	mu        sync.Mutex
+	submitted bool
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewMetricGenerator returns a new MetricGenerator.
+func NewMetricGenerator() *MetricGenerator {
+	return &MetricGenerator{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// Start commits the submitted metrics periodically.
+func (g *MetricGenerator) Start() {
+	go func() {
+		defer close(g.done)
+		ticker := time.NewTicker(generatedMetricsCommitInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				g.commit()
+			case <-g.stop:
+				g.commit()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic commits and commits the remaining metrics.
+func (g *MetricGenerator) Stop() {
+	close(g.stop)
+	<-g.done
+}
+
+// generate submits the metric of the rule if the content matches its pattern,
+// the value is 1 unless the rule has a value field.
+func (g *MetricGenerator) generate(rule *config.ProcessingRule, msg *message.Message, content []byte) {
+	fields := parseRegex(rule, content)
+	if fields == nil {
+		return
+	}
+	value := 1.0
+	if rule.ValueField != "" {
+		var err error
+		value, err = strconv.ParseFloat(fmt.Sprint(fields[rule.ValueField]), 64)
+		if err != nil {
+			log.Debugf("Could not parse the value of the metric %s: %v", rule.MetricName, err)
+			return
+		}
+	}
+	sender := g.getSender()
+	if sender == nil {
+		return
+	}
+	tags := metricTags(rule, msg, fields)
+	switch rule.MetricType {
+	case config.GaugeMetricType:
+		sender.Gauge(rule.MetricName, value, "", tags)
+	case config.HistogramMetricType:
+		sender.Histogram(rule.MetricName, value, "", tags)
+	default:
+		sender.Count(rule.MetricName, value, "", tags)
+	}
+	g.mu.Lock()
+	g.submitted = true
+	g.mu.Unlock()
+}
+
+// getSender returns the aggregator sender, nil if the aggregator is not available.
+func (g *MetricGenerator) getSender() aggregator.Sender {
+	g.once.Do(func() {
+		g.sender, g.err = aggregator.GetSender(generatedMetricsSenderID)
+		if g.err != nil {
+			log.Warnf("Can't generate metrics from logs: %v", g.err)
+		}
+	})
+	return g.sender
+}
+
+// commit flushes the metrics submitted since the last commit.
+func (g *MetricGenerator) commit() {
+	g.mu.Lock()
+	submitted := g.submitted
+	g.submitted = false
+	g.mu.Unlock()
+	if submitted {
+		g.sender.Commit()
+	}
+}
+
+// metricTags returns the tags of the source of the message followed by the tag fields of the rule.
+func metricTags(rule *config.ProcessingRule, msg *message.Message, fields map[string]interface{}) []string {
+	originTags := msg.Origin.Tags()
+	tags := make([]string, 0, len(originTags)+len(rule.TagFields)+2)
+	tags = append(tags, originTags...)
+	if service := msg.Origin.Service(); service != "" {
+		tags = append(tags, "service:"+service)
+	}
+	if source := msg.Origin.Source(); source != "" {
+		tags = append(tags, "source:"+source)
+	}
+	for _, field := range rule.TagFields {
+		if value, found := fields[field]; found {
+			tags = append(tags, fmt.Sprintf("%s:%v", field, value))
+		}
+	}
+	return tags
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func newMetricRule(metricType, pattern, valueField string, tagFields ...string) *config.ProcessingRule {
+	return &config.ProcessingRule{
+		Type:       config.GenerateMetric,
+		Name:       "test",
+		Pattern:    pattern,
+		Regex:      regexp.MustCompile(pattern),
+		MetricName: "app.metric",
+		MetricType: metricType,
+		ValueField: valueField,
+		TagFields:  tagFields,
+	}
+}
+
+func TestGenerateCountMetric(t *testing.T) {
+	sender := mocksender.NewMockSender(generatedMetricsSenderID)
+	sender.SetupAcceptAll()
+
+	p := &Processor{metricGenerator: NewMetricGenerator()}
+	rule := newMetricRule("", "ERROR", "")
+	source := config.NewLogSource("", &config.LogsConfig{Service: "api", Source: "java", Tags: []string{"env:prod"}, ProcessingRules: []*config.ProcessingRule{rule}})
+
+	shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("ERROR something failed"), source, ""))
+	assert.True(t, shouldProcess)
+	p.applyRedactingRules(newMessage([]byte("INFO all good"), source, ""))
+
+	sender.AssertNumberOfCalls(t, "Count", 1)
+	sender.AssertCalled(t, "Count", "app.metric", 1.0, "", []string{"env:prod", "service:api", "source:java"})
+}
+
+func TestGenerateMetricWithValueAndTagFields(t *testing.T) {
+	sender := mocksender.NewMockSender(generatedMetricsSenderID)
+	sender.SetupAcceptAll()
+
+	p := &Processor{metricGenerator: NewMetricGenerator()}
+	rule := newMetricRule(config.HistogramMetricType, `(?P<method>GET|POST) \S+ took (?P<duration>[\d.]+)ms`, "duration", "method")
+	source := config.NewLogSource("", &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{rule}})
+
+	p.applyRedactingRules(newMessage([]byte("GET /users took 12.5ms"), source, ""))
+	p.applyRedactingRules(newMessage([]byte("POST /users took not-a-number"), source, ""))
+
+	sender.AssertNumberOfCalls(t, "Histogram", 1)
+	sender.AssertCalled(t, "Histogram", "app.metric", 12.5, "", []string{"method:GET"})
+}
+
+func TestGeneratedMetricsAreCommittedOnStop(t *testing.T) {
+	sender := mocksender.NewMockSender(generatedMetricsSenderID)
+	sender.SetupAcceptAll()
+
+	generator := NewMetricGenerator()
+	generator.Start()
+	generator.Stop()
+	sender.AssertNotCalled(t, "Commit")
+
+	generator = NewMetricGenerator()
+	generator.Start()
+	rule := newMetricRule(config.GaugeMetricType, `queue=(?P<size>\d+)`, "size")
+	source := config.NewLogSource("", &config.LogsConfig{})
+	generator.generate(rule, newMessage([]byte("queue=42"), source, ""), []byte("queue=42"))
+	generator.Stop()
+
+	sender.AssertCalled(t, "Gauge", "app.metric", 42.0, "", mock.Anything)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
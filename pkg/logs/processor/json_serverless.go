@@ -57,7 +57,7 @@ func (j *jsonServerlessEncoder) Encode(msg *message.Message, redactedMsg []byte)
 		}
 	}
 
-	return json.Marshal(jsonServerlessPayload{
+	payload := jsonServerlessPayload{
 		Message: jsonServerlessMessage{
 			Message: toValidUtf8(redactedMsg),
 			Lambda:  lambdaPart,
@@ -68,5 +68,26 @@ func (j *jsonServerlessEncoder) Encode(msg *message.Message, redactedMsg []byte)
 		Service:   msg.Origin.Service(),
 		Source:    msg.Origin.Source(),
 		Tags:      msg.Origin.TagsToString(),
-	})
+	}
+	if len(msg.Attributes) == 0 {
+		return json.Marshal(payload)
+	}
+	return json.Marshal(withServerlessAttributes(payload, msg.Attributes))
+}
+
+// withServerlessAttributes flattens the payload and the parsed attributes in a single object,
+// the reserved fields of the payload take precedence over the attributes.
+func withServerlessAttributes(payload jsonServerlessPayload, attributes map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(attributes)+7)
+	for key, value := range attributes {
+		fields[key] = value
+	}
+	fields["message"] = payload.Message
+	fields["status"] = payload.Status
+	fields["timestamp"] = payload.Timestamp
+	fields["hostname"] = payload.Hostname
+	fields["service"] = payload.Service
+	fields["ddsource"] = payload.Source
+	fields["ddtags"] = payload.Tags
+	return fields
 }
@@ -0,0 +1,230 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// Default fields looked up when promoting the parsed attributes,
+// they can be overridden on each parsing rule.
+var (
+	defaultMessageFields   = []string{"message", "msg"}
+	defaultStatusFields    = []string{"status", "level", "severity"}
+	defaultTimestampFields = []string{"timestamp", "time", "ts"}
+)
+
+// applyParsingRules extracts attributes from the content of the message using the first parsing rule
+// that succeeds, then promotes some of them to the status, the timestamp and the tags of the message.
+// Returns the new content to send, which is left unchanged when no message field is found.
+func (p *Processor) applyParsingRules(msg *message.Message, content []byte) []byte {
+	for _, rule := range p.rules(msg) {
+		if !rule.IsParsingRule() {
+			continue
+		}
+		attributes, err := parse(rule, content)
+		if err != nil || len(attributes) == 0 {
+			continue
+		}
+		return promoteAttributes(rule, msg, attributes, content)
+	}
+	return content
+}
+
+// parse extracts the attributes out of content following the rule type.
+func parse(rule *config.ProcessingRule, content []byte) (map[string]interface{}, error) {
+	switch rule.Type {
+	case config.JSONParsing:
+		return parseJSON(content)
+	case config.LogfmtParsing:
+		return parseLogfmt(content)
+	case config.RegexParsing:
+		return parseRegex(rule, content), nil
+	}
+	return nil, fmt.Errorf("rule %s is not a parsing rule", rule.Name)
+}
+
+// parseJSON decodes content as a JSON object.
+func parseJSON(content []byte) (map[string]interface{}, error) {
+	var attributes map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&attributes); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// parseLogfmt decodes content made of space separated key=value pairs,
+// values can be double-quoted and keys without a value are set to true.
+// The content is rejected when it holds no key=value pair or when keys without a value
+// make up most of it, so that plain text is not mistaken for logfmt.
+func parseLogfmt(content []byte) (map[string]interface{}, error) {
+	attributes := make(map[string]interface{})
+	var pairs, bareKeys int
+	s := string(content)
+	for i := 0; i < len(s); {
+		// skip spaces
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' {
+			i++
+		}
+		key := s[start:i]
+		if key == "" {
+			return nil, fmt.Errorf("unexpected '=' at position %d", i)
+		}
+		if i >= len(s) || s[i] == ' ' {
+			attributes[key] = true
+			bareKeys++
+			continue
+		}
+		// skip '='
+		i++
+		pairs++
+		if i < len(s) && s[i] == '"' {
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated quoted value for key %s", key)
+			}
+			value, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for key %s: %v", key, err)
+			}
+			attributes[key] = value
+			i = end + 1
+			continue
+		}
+		start = i
+		for i < len(s) && s[i] != ' ' {
+			i++
+		}
+		attributes[key] = s[start:i]
+	}
+	if pairs == 0 || bareKeys > pairs {
+		return nil, fmt.Errorf("not a logfmt content: %d key=value pairs for %d keys without a value", pairs, bareKeys)
+	}
+	return attributes, nil
+}
+
+// parseRegex extracts the named capture groups of the rule pattern,
+// returns nil if the content does not match.
+func parseRegex(rule *config.ProcessingRule, content []byte) map[string]interface{} {
+	match := rule.Regex.FindSubmatch(content)
+	if match == nil {
+		return nil
+	}
+	attributes := make(map[string]interface{})
+	for i, name := range rule.Regex.SubexpNames() {
+		if name == "" || match[i] == nil {
+			continue
+		}
+		attributes[name] = string(match[i])
+	}
+	return attributes
+}
+
+// promoteAttributes moves the well-known attributes to the message status, timestamp and tags,
+// and attaches the remaining ones to the message.
+func promoteAttributes(rule *config.ProcessingRule, msg *message.Message, attributes map[string]interface{}, content []byte) []byte {
+	if key, value, found := lookup(attributes, rule.StatusField, defaultStatusFields); found {
+		if status, ok := message.StatusFromString(fmt.Sprint(value)); ok {
+			msg.SetStatus(status)
+			delete(attributes, key)
+		}
+	}
+	if key, value, found := lookup(attributes, rule.TimestampField, defaultTimestampFields); found {
+		if ts, err := parseTimestamp(value, rule.TimestampFormat); err == nil {
+			msg.Timestamp = ts
+			delete(attributes, key)
+		}
+	}
+	var tags []string
+	for _, field := range rule.TagFields {
+		if value, found := attributes[field]; found {
+			tags = append(tags, field+":"+fmt.Sprint(value))
+			delete(attributes, field)
+		}
+	}
+	if len(tags) > 0 {
+		msg.Origin.AddTags(tags...)
+	}
+	if key, value, found := lookup(attributes, rule.MessageField, defaultMessageFields); found {
+		if s, ok := value.(string); ok {
+			content = []byte(s)
+			delete(attributes, key)
+		}
+	}
+	if len(attributes) > 0 {
+		if msg.Attributes == nil {
+			msg.Attributes = attributes
+		} else {
+			for k, v := range attributes {
+				msg.Attributes[k] = v
+			}
+		}
+	}
+	return content
+}
+
+// lookup returns the first attribute found either from the configured field,
+// or from the default fields when none is configured.
+func lookup(attributes map[string]interface{}, field string, defaults []string) (string, interface{}, bool) {
+	if field != "" {
+		value, found := attributes[field]
+		return field, value, found
+	}
+	for _, key := range defaults {
+		if value, found := attributes[key]; found {
+			return key, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// parseTimestamp parses value with the given layout, by default it accepts RFC3339 strings
+// and unix timestamps in seconds or milliseconds.
+func parseTimestamp(value interface{}, layout string) (time.Time, error) {
+	s := fmt.Sprint(value)
+	if layout != "" {
+		ts, err := time.Parse(layout, s)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return ts.UTC(), nil
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return ts.UTC(), nil
+	}
+	epoch, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unsupported timestamp %s", s)
+	}
+	// values too large to be seconds are considered as milliseconds
+	if epoch > 1e11 {
+		epoch /= 1e3
+	}
+	sec := int64(epoch)
+	return time.Unix(sec, int64((epoch-float64(sec))*1e9)).UTC(), nil
+}
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newParsingRule(ruleType, pattern string) *config.ProcessingRule {
+	rule := &config.ProcessingRule{
+		Type:    ruleType,
+		Name:    "test",
+		Pattern: pattern,
+	}
+	if pattern != "" {
+		rule.Regex = regexp.MustCompile(pattern)
+	}
+	return rule
+}
+
+func TestParseJSON(t *testing.T) {
+	rule := newParsingRule(config.JSONParsing, "")
+	rule.TagFields = []string{"team"}
+	p := &Processor{processingRules: []*config.ProcessingRule{rule}}
+
+	source := config.LogSource{Config: &config.LogsConfig{}}
+	msg := newMessage(nil, &source, "")
+	content := p.applyParsingRules(msg, []byte(`{"msg":"hello","level":"ERROR","ts":1600000000,"team":"logs","user":{"id":42}}`))
+
+	assert.Equal(t, "hello", string(content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, time.Unix(1600000000, 0).UTC(), msg.Timestamp)
+	assert.Equal(t, []string{"team:logs"}, msg.Origin.Tags())
+	assert.Equal(t, 1, len(msg.Attributes))
+	assert.Contains(t, msg.Attributes, "user")
+}
+
+func TestParseJSONInvalidContent(t *testing.T) {
+	p := &Processor{processingRules: []*config.ProcessingRule{newParsingRule(config.JSONParsing, "")}}
+
+	source := config.LogSource{Config: &config.LogsConfig{}}
+	msg := newMessage(nil, &source, "")
+	content := p.applyParsingRules(msg, []byte("not a json"))
+
+	assert.Equal(t, "not a json", string(content))
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Nil(t, msg.Attributes)
+}
+
+func TestParseLogfmt(t *testing.T) {
+	rule := newParsingRule(config.LogfmtParsing, "")
+	rule.TimestampField = "at"
+	rule.TimestampFormat = "2006-01-02 15:04:05"
+	source := config.LogSource{Config: &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{rule}}}
+	p := &Processor{}
+
+	msg := newMessage(nil, &source, "")
+	content := p.applyParsingRules(msg, []byte(`at="2020-09-13 12:26:40" level=warn msg="disk \"sda\" is full" retry`))
+
+	assert.Equal(t, `disk "sda" is full`, string(content))
+	assert.Equal(t, message.StatusWarning, msg.GetStatus())
+	assert.Equal(t, time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC), msg.Timestamp)
+	assert.Equal(t, map[string]interface{}{"retry": true}, msg.Attributes)
+}
+
+func TestParseLogfmtInvalidContent(t *testing.T) {
+	_, err := parseLogfmt([]byte(`key="unterminated`))
+	assert.NotNil(t, err)
+
+	_, err = parseLogfmt([]byte(`=value`))
+	assert.NotNil(t, err)
+
+	_, err = parseLogfmt([]byte(`retry`))
+	assert.NotNil(t, err)
+
+	_, err = parseLogfmt([]byte(`failed to connect to host=db`))
+	assert.NotNil(t, err)
+}
+
+func TestParseLogfmtLeavesPlainTextUnparsed(t *testing.T) {
+	p := &Processor{processingRules: []*config.ProcessingRule{newParsingRule(config.LogfmtParsing, "")}}
+
+	source := config.LogSource{Config: &config.LogsConfig{}}
+	msg := newMessage(nil, &source, "")
+	content := p.applyParsingRules(msg, []byte("connection refused by peer"))
+
+	assert.Equal(t, "connection refused by peer", string(content))
+	assert.Nil(t, msg.Attributes)
+}
+
+func TestParseRegex(t *testing.T) {
+	rule := newParsingRule(config.RegexParsing, `^(?P<severity>\w+) \[(?P<thread>[^\]]+)\] (?P<message>.*)$`)
+	p := &Processor{processingRules: []*config.ProcessingRule{rule}}
+
+	source := config.LogSource{Config: &config.LogsConfig{}}
+	msg := newMessage(nil, &source, "")
+	content := p.applyParsingRules(msg, []byte("DEBUG [main] starting up"))
+
+	assert.Equal(t, "starting up", string(content))
+	assert.Equal(t, message.StatusDebug, msg.GetStatus())
+	assert.Equal(t, map[string]interface{}{"thread": "main"}, msg.Attributes)
+
+	msg = newMessage(nil, &source, "")
+	content = p.applyParsingRules(msg, []byte("no match"))
+	assert.Equal(t, "no match", string(content))
+	assert.Nil(t, msg.Attributes)
+}
+
+func TestParsingRulesFallback(t *testing.T) {
+	p := &Processor{processingRules: []*config.ProcessingRule{
+		newParsingRule(config.JSONParsing, ""),
+		newParsingRule(config.LogfmtParsing, ""),
+	}}
+
+	source := config.LogSource{Config: &config.LogsConfig{}}
+	msg := newMessage(nil, &source, "")
+	content := p.applyParsingRules(msg, []byte("msg=hello status=error"))
+
+	assert.Equal(t, "hello", string(content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ts, err := parseTimestamp("2020-09-13T12:26:40.5Z", "")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 9, 13, 12, 26, 40, 500000000, time.UTC), ts)
+
+	ts, err = parseTimestamp("1600000000500", "")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Unix(1600000000, 500000000).UTC(), ts)
+
+	_, err = parseTimestamp("yesterday", "")
+	assert.NotNil(t, err)
+}
@@ -7,6 +7,7 @@ package processor
 
 import (
 	"context"
+	"math/rand"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -26,11 +27,13 @@ type Processor struct {
 	encoder                   Encoder
 	done                      chan struct{}
 	diagnosticMessageReceiver diagnostic.MessageReceiver
+	metricGenerator           *MetricGenerator
 	mu                        sync.Mutex
 }
 
-// New returns an initialized Processor.
-func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver) *Processor {
+// New returns an initialized Processor, the metrics of the generate_metric rules are
+// submitted to metricGenerator which is not generated when nil.
+func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver, metricGenerator *MetricGenerator) *Processor {
 	return &Processor{
 		inputChan:                 inputChan,
 		outputChan:                outputChan,
@@ -38,6 +41,7 @@ func New(inputChan, outputChan chan *message.Message, processingRules []*config.
 		encoder:                   encoder,
 		done:                      make(chan struct{}),
 		diagnosticMessageReceiver: diagnosticMessageReceiver,
+		metricGenerator:           metricGenerator,
 	}
 }
 
@@ -86,10 +90,16 @@ func (p *Processor) run() {
 func (p *Processor) processMessage(msg *message.Message) {
 	metrics.LogsDecoded.Add(1)
 	metrics.TlmLogsDecoded.Inc()
+	if !msg.Origin.LogSource.AllowMessage() {
+		metrics.TlmLogsRateLimited.Inc(msg.Origin.LogSource.Config.Source)
+		return
+	}
 	if shouldProcess, redactedMsg := p.applyRedactingRules(msg); shouldProcess {
 		metrics.LogsProcessed.Add(1)
 		metrics.TlmLogsProcessed.Inc()
 
+		redactedMsg = p.applyParsingRules(msg, redactedMsg)
+
 		p.diagnosticMessageReceiver.HandleMessage(*msg, redactedMsg)
 
 		// Encode the message to its final format
@@ -107,8 +117,7 @@ func (p *Processor) processMessage(msg *message.Message) {
 // and a copy of the message with some fields redacted, depending on config
 func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 	content := msg.Content
-	rules := append(p.processingRules, msg.Origin.LogSource.Config.ProcessingRules...)
-	for _, rule := range rules {
+	for _, rule := range p.rules(msg) {
 		switch rule.Type {
 		case config.ExcludeAtMatch:
 			if rule.Regex.Match(content) {
@@ -120,7 +129,24 @@ func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 			}
 		case config.MaskSequences:
 			content = rule.Regex.ReplaceAll(content, rule.Placeholder)
+		case config.Sample:
+			if rule.Regex.Match(content) && rand.Float64() >= rule.SampleRate {
+				msg.Origin.LogSource.RecordSampledOut()
+				metrics.TlmLogsSampledOut.Inc(msg.Origin.LogSource.Config.Source)
+				return false, nil
+			}
+		case config.GenerateMetric:
+			if p.metricGenerator != nil {
+				p.metricGenerator.generate(rule, msg, content)
+			}
 		}
 	}
 	return true, content
 }
+
+// rules returns the global processing rules followed by the ones of the source of the message.
+func (p *Processor) rules(msg *message.Message) []*config.ProcessingRule {
+	rules := make([]*config.ProcessingRule, 0, len(p.processingRules)+len(msg.Origin.LogSource.Config.ProcessingRules))
+	rules = append(rules, p.processingRules...)
+	return append(rules, msg.Origin.LogSource.Config.ProcessingRules...)
+}
@@ -6,10 +6,12 @@
 package processor
 
 import (
+	"fmt"
 	"regexp"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/stretchr/testify/assert"
 )
@@ -128,6 +130,44 @@ func TestMask(t *testing.T) {
 	assert.Equal(t, []byte("New data added to data_values= on prod"), redactedMessage)
 }
 
+func TestSample(t *testing.T) {
+	p := &Processor{}
+	rule := newProcessingRule("sample", "", "DEBUG")
+	rule.SampleRate = 0.1
+	source := config.NewLogSource("", &config.LogsConfig{ProcessingRules: []*config.ProcessingRule{rule}})
+
+	var kept int
+	for i := 0; i < 10000; i++ {
+		if shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("DEBUG hello"), source, "")); shouldProcess {
+			kept++
+		}
+	}
+	assert.InDelta(t, 1000, kept, 200)
+	assert.Equal(t, []string{fmt.Sprintf("%d", 10000-kept)}, source.GetInfoStatus()["Sampled out messages"])
+
+	// messages not matching the pattern are all kept
+	shouldProcess, redactedMessage := p.applyRedactingRules(newMessage([]byte("INFO hello"), source, ""))
+	assert.True(t, shouldProcess)
+	assert.Equal(t, []byte("INFO hello"), redactedMessage)
+
+	rule.SampleRate = 1
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("DEBUG hello"), source, ""))
+	assert.True(t, shouldProcess)
+}
+
+func TestRateLimit(t *testing.T) {
+	inputChan := make(chan *message.Message, 10)
+	outputChan := make(chan *message.Message, 10)
+	p := New(inputChan, outputChan, nil, RawEncoder, &diagnostic.NoopMessageReceiver{}, nil)
+	source := config.NewLogSource("", &config.LogsConfig{RateLimit: 0.001, RateLimitBurst: 2})
+
+	for i := 0; i < 5; i++ {
+		p.processMessage(newMessage([]byte("hello"), source, ""))
+	}
+	assert.Len(t, outputChan, 2)
+	assert.Equal(t, []string{"3"}, source.GetInfoStatus()["Rate limited messages"])
+}
+
 func TestTruncate(t *testing.T) {
 	p := &Processor{}
 
@@ -6,10 +6,12 @@
 package processor
 
 import (
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 // ProtoEncoder is a shared proto encoder.
@@ -18,12 +20,24 @@ var ProtoEncoder Encoder = &protoEncoder{}
 // protoEncoder transforms a message into a protobuf byte array.
 type protoEncoder struct{}
 
+// droppedAttributesWarning makes sure that the protobuf format dropping the attributes is only logged once.
+var droppedAttributesWarning sync.Once
+
 // Encode encodes a message into a protobuf byte array.
 func (p *protoEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte, error) {
+	ts := time.Now().UTC()
+	if !msg.Timestamp.IsZero() {
+		ts = msg.Timestamp
+	}
+	if len(msg.Attributes) > 0 {
+		droppedAttributesWarning.Do(func() {
+			log.Warn("The attributes extracted by the parsing rules are dropped by the protobuf format, send the logs over HTTP or set logs_config.dev_mode_use_proto to false to keep them")
+		})
+	}
 	return (&pb.Log{
 		Message:   toValidUtf8(redactedMsg),
 		Status:    msg.GetStatus(),
-		Timestamp: time.Now().UTC().UnixNano(),
+		Timestamp: ts.UnixNano(),
 		Hostname:  getHostname(),
 		Service:   msg.Origin.Service(),
 		Source:    msg.Origin.Source(),
@@ -52,8 +52,8 @@ func (r *rawEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte, e
 		// Extra
 		extraContent = append(extraContent, []byte(" - - ")...)
 
-		// Tags
-		tagsPayload := msg.Origin.TagsPayload()
+		// Tags and attributes
+		tagsPayload := append(msg.Origin.TagsPayload(), message.AttributesPayload(msg.Attributes)...)
 		if len(tagsPayload) > 0 {
 			extraContent = append(extraContent, tagsPayload...)
 		} else {
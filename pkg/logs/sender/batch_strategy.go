@@ -37,7 +37,7 @@ func NewBatchStrategy(serializer Serializer, batchWait time.Duration) Strategy {
 	}
 }
 
-func (s *batchStrategy) Flush(ctx context.Context, inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error, mu *sync.Mutex) {
+func (s *batchStrategy) Flush(ctx context.Context, inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte, []*message.Message) error, mu *sync.Mutex) {
 	mu.Lock()
 	defer mu.Unlock()
 	for {
@@ -64,7 +64,7 @@ func (s *batchStrategy) Flush(ctx context.Context, inputChan chan *message.Messa
 }
 
 // Send accumulates messages to a buffer and sends them when the buffer is full or outdated.
-func (s *batchStrategy) Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error, mu *sync.Mutex) {
+func (s *batchStrategy) Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte, []*message.Message) error, mu *sync.Mutex) {
 	flushTimer := time.NewTimer(s.batchWait)
 	defer func() {
 		flushTimer.Stop()
@@ -113,7 +113,7 @@ func (s *batchStrategy) Send(inputChan chan *message.Message, outputChan chan *m
 
 // sendBuffer sends all the messages that are stored in the buffer and forwards them
 // to the next stage of the pipeline.
-func (s *batchStrategy) sendBuffer(outputChan chan *message.Message, send func([]byte) error) {
+func (s *batchStrategy) sendBuffer(outputChan chan *message.Message, send func([]byte, []*message.Message) error) {
 	if s.buffer.IsEmpty() {
 		return
 	}
@@ -121,9 +121,9 @@ func (s *batchStrategy) sendBuffer(outputChan chan *message.Message, send func([
 	messages := s.buffer.GetMessages()
 	defer s.buffer.Clear()
 
-	err := send(s.serializer.Serialize(messages))
+	err := send(s.serializer.Serialize(messages), messages)
 	if err != nil {
-		if shouldStopSending(err) {
+		if shouldStopSending(err) || isSpilled(err) {
 			return
 		}
 		log.Warnf("Could not send payload: %v", err)
@@ -32,7 +32,7 @@ func TestBatchStrategySendsPayloadWhenBufferIsFull(t *testing.T) {
 	var mu sync.Mutex
 
 	var content []byte
-	success := func(payload []byte) error {
+	success := func(payload []byte, messages []*message.Message) error {
 		assert.Equal(t, content, payload)
 		return nil
 	}
@@ -57,7 +57,7 @@ func TestBatchStrategySendsPayloadWhenBufferIsFull(t *testing.T) {
 // 	output := make(chan *message.Message)
 
 // 	var content []byte
-// 	success := func(payload []byte) error {
+// 	success := func(payload []byte, messages []*message.Message) error {
 // 		assert.Equal(t, content, payload)
 // 		return nil
 // 	}
@@ -96,7 +96,7 @@ func TestBatchStrategySendsPayloadWhenClosingInput(t *testing.T) {
 	var mu sync.Mutex
 
 	var content []byte
-	success := func(payload []byte) error {
+	success := func(payload []byte, messages []*message.Message) error {
 		assert.Equal(t, content, payload)
 		return nil
 	}
@@ -125,7 +125,7 @@ func TestBatchStrategyShouldNotBlockWhenForceStopping(t *testing.T) {
 	var mu sync.Mutex
 
 	var content []byte
-	success := func(payload []byte) error {
+	success := func(payload []byte, messages []*message.Message) error {
 		return context.Canceled
 	}
 
@@ -145,7 +145,7 @@ func TestBatchStrategyShouldNotBlockWhenStoppingGracefully(t *testing.T) {
 	var mu sync.Mutex
 
 	var content []byte
-	success := func(payload []byte) error {
+	success := func(payload []byte, messages []*message.Message) error {
 		return nil
 	}
 
@@ -0,0 +1,274 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+const spillFileExtension = ".spill"
+
+// spillFileFormat makes the file names sort in creation order.
+const spillFileFormat = "20060102T150405.000000000_"
+
+// spilledOrigin holds what the auditor needs to commit the offset of a message.
+type spilledOrigin struct {
+	Identifier  string `json:"identifier"`
+	Offset      string `json:"offset"`
+	TailingMode string `json:"tailing_mode"`
+}
+
+// bufferedPayload is a payload waiting to be sent to the main destination,
+// along with the messages to forward to the auditor once it is sent.
+type bufferedPayload struct {
+	payload  []byte
+	messages []*message.Message
+	// filename is the file storing the payload
+	filename string
+}
+
+// spillFile is a file of the disk buffer along with its size, which is tracked in memory
+// so that the accounting does not depend on the file still being on disk.
+type spillFile struct {
+	name string
+	size int64
+}
+
+// DiskBuffer stores on disk the payloads that could not be sent, in their sending order.
+// When the maximum size is reached, the oldest payloads are evicted first.
+// The origins of the messages are stored with each payload, so that their offsets
+// are only committed once the payload has been sent.
+type DiskBuffer struct {
+	storagePath        string
+	maxSizeInBytes     int64
+	files              []spillFile
+	currentSizeInBytes int64
+	notify             chan struct{}
+	mu                 sync.Mutex
+}
+
+// NewDiskBuffer returns a new DiskBuffer, the payloads spilled by a previous run are reloaded.
+func NewDiskBuffer(storagePath string, maxSizeInBytes int64) (*DiskBuffer, error) {
+	if err := os.MkdirAll(storagePath, 0700); err != nil {
+		return nil, err
+	}
+	b := &DiskBuffer{
+		storagePath:    storagePath,
+		maxSizeInBytes: maxSizeInBytes,
+		notify:         make(chan struct{}, 1),
+	}
+	if err := b.reloadExistingFiles(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Store writes the payload on disk along with the origins of its messages.
+func (b *DiskBuffer) Store(payload []byte, messages []*message.Message) error {
+	origins, err := json.Marshal(latestOrigins(messages))
+	if err != nil {
+		return err
+	}
+	content := make([]byte, 0, len(origins)+1+len(payload))
+	content = append(content, origins...)
+	content = append(content, '\n')
+	content = append(content, payload...)
+	size := int64(len(content))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.makeRoomFor(size); err != nil {
+		return err
+	}
+
+	filename := time.Now().UTC().Format(spillFileFormat)
+	file, err := ioutil.TempFile(b.storagePath, filename+"*"+spillFileExtension)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(content); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err = file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	b.currentSizeInBytes += size
+	b.files = append(b.files, spillFile{name: file.Name(), size: size})
+	metrics.TlmSpilledPayloads.Inc()
+	b.updateTelemetry()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Peek returns the oldest payload stored on disk, or nil if the buffer is empty.
+// Files that can not be read are removed.
+func (b *DiskBuffer) Peek() *bufferedPayload {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for len(b.files) > 0 {
+		filename := b.files[0].name
+		payload, err := readSpillFile(filename)
+		if err == nil {
+			return payload
+		}
+		log.Warnf("Could not read spilled logs payload %s, removing it: %v", filename, err)
+		b.removeFileAt(0)
+	}
+	return nil
+}
+
+// Remove deletes a payload returned by Peek once it has been sent,
+// the payload may already have been evicted.
+func (b *DiskBuffer) Remove(payload *bufferedPayload) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, file := range b.files {
+		if file.name == payload.filename {
+			b.removeFileAt(i)
+			return
+		}
+	}
+}
+
+// IsEmpty returns true if no payload is stored on disk.
+func (b *DiskBuffer) IsEmpty() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.files) == 0
+}
+
+// Notify returns a channel receiving an event when a payload is stored.
+func (b *DiskBuffer) Notify() <-chan struct{} {
+	return b.notify
+}
+
+// makeRoomFor evicts the oldest payloads until the new one fits in the buffer.
+func (b *DiskBuffer) makeRoomFor(size int64) error {
+	if size > b.maxSizeInBytes {
+		return fmt.Errorf("the payload is too big. Current:%v Maximum:%v", size, b.maxSizeInBytes)
+	}
+	for len(b.files) > 0 && b.currentSizeInBytes+size > b.maxSizeInBytes {
+		log.Warnf("Maximum disk space for spilled logs is reached. Removing %s", b.files[0].name)
+		b.removeFileAt(0)
+		metrics.TlmSpillEvicted.Inc()
+	}
+	return nil
+}
+
+func (b *DiskBuffer) removeFileAt(index int) {
+	file := b.files[index]
+
+	// Remove the file from b.files also in case of error to not
+	// fail on the next call.
+	b.files = append(b.files[:index], b.files[index+1:]...)
+	b.currentSizeInBytes -= file.size
+
+	if err := os.Remove(file.name); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Could not remove spilled logs payload %s: %v", file.name, err)
+	}
+	b.updateTelemetry()
+}
+
+func (b *DiskBuffer) reloadExistingFiles() error {
+	entries, err := ioutil.ReadDir(b.storagePath)
+	if err != nil {
+		return err
+	}
+	// entries are sorted by file name, which starts with the creation date
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() && filepath.Ext(entry.Name()) == spillFileExtension {
+			b.currentSizeInBytes += entry.Size()
+			b.files = append(b.files, spillFile{name: filepath.Join(b.storagePath, entry.Name()), size: entry.Size()})
+		}
+	}
+	if len(b.files) > 0 {
+		log.Infof("Reloaded %d spilled logs payloads from %s", len(b.files), b.storagePath)
+	}
+	b.updateTelemetry()
+	return nil
+}
+
+func (b *DiskBuffer) updateTelemetry() {
+	metrics.TlmSpillBufferSize.Set(float64(b.currentSizeInBytes), b.storagePath)
+	metrics.TlmSpillBufferFiles.Set(float64(len(b.files)), b.storagePath)
+}
+
+// readSpillFile decodes a file written by Store.
+func readSpillFile(filename string) (*bufferedPayload, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	i := bytes.IndexByte(content, '\n')
+	if i < 0 {
+		return nil, fmt.Errorf("missing header")
+	}
+	var origins []spilledOrigin
+	if err := json.Unmarshal(content[:i], &origins); err != nil {
+		return nil, err
+	}
+	messages := make([]*message.Message, 0, len(origins))
+	for _, o := range origins {
+		// the auditor only needs the identifier, the offset and the tailing mode of the origin
+		origin := message.NewOrigin(&config.LogSource{Config: &config.LogsConfig{TailingMode: o.TailingMode}})
+		origin.Identifier = o.Identifier
+		origin.Offset = o.Offset
+		messages = append(messages, message.NewMessage(nil, origin, "", 0))
+	}
+	return &bufferedPayload{
+		payload:  content[i+1:],
+		messages: messages,
+		filename: filename,
+	}, nil
+}
+
+// latestOrigins returns the last origin of each identifier, which is all the auditor needs
+// to commit the offsets of the messages.
+func latestOrigins(messages []*message.Message) []spilledOrigin {
+	var origins []spilledOrigin
+	indexes := make(map[string]int)
+	for _, msg := range messages {
+		if msg.Origin == nil || msg.Origin.Identifier == "" {
+			continue
+		}
+		origin := spilledOrigin{
+			Identifier: msg.Origin.Identifier,
+			Offset:     msg.Origin.Offset,
+		}
+		if msg.Origin.LogSource != nil && msg.Origin.LogSource.Config != nil {
+			origin.TailingMode = msg.Origin.LogSource.Config.TailingMode
+		}
+		if i, exists := indexes[origin.Identifier]; exists {
+			origins[i] = origin
+			continue
+		}
+		indexes[origin.Identifier] = len(origins)
+		origins = append(origins, origin)
+	}
+	return origins
+}
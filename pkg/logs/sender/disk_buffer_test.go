@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newOriginMessage(identifier, offset string) *message.Message {
+	source := config.NewLogSource("", &config.LogsConfig{TailingMode: "end"})
+	origin := message.NewOrigin(source)
+	origin.Identifier = identifier
+	origin.Offset = offset
+	return message.NewMessage([]byte(identifier+offset), origin, "", 0)
+}
+
+func TestDiskBufferStoreAndPeek(t *testing.T) {
+	path, err := ioutil.TempDir("", "spill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	b, err := NewDiskBuffer(path, 1000)
+	assert.Nil(t, err)
+	assert.True(t, b.IsEmpty())
+	assert.Nil(t, b.Peek())
+
+	messages := []*message.Message{
+		newOriginMessage("file:/var/log/a.log", "10"),
+		newOriginMessage("file:/var/log/b.log", "5"),
+		newOriginMessage("file:/var/log/a.log", "20"),
+		message.NewMessage([]byte("no origin"), nil, "", 0),
+	}
+	assert.Nil(t, b.Store([]byte("payload 1"), messages))
+	assert.Nil(t, b.Store([]byte("payload 2"), nil))
+	assert.False(t, b.IsEmpty())
+
+	select {
+	case <-b.Notify():
+	default:
+		assert.Fail(t, "expected a notification")
+	}
+
+	payload := b.Peek()
+	assert.Equal(t, "payload 1", string(payload.payload))
+	assert.Equal(t, 2, len(payload.messages))
+	assert.Equal(t, "file:/var/log/a.log", payload.messages[0].Origin.Identifier)
+	assert.Equal(t, "20", payload.messages[0].Origin.Offset)
+	assert.Equal(t, "end", payload.messages[0].Origin.LogSource.Config.TailingMode)
+	assert.Equal(t, "file:/var/log/b.log", payload.messages[1].Origin.Identifier)
+	assert.Equal(t, "5", payload.messages[1].Origin.Offset)
+
+	b.Remove(payload)
+	// removing twice is a no-op
+	b.Remove(payload)
+
+	payload = b.Peek()
+	assert.Equal(t, "payload 2", string(payload.payload))
+	assert.Equal(t, 0, len(payload.messages))
+	b.Remove(payload)
+
+	assert.True(t, b.IsEmpty())
+	assert.Equal(t, int64(0), b.currentSizeInBytes)
+}
+
+func TestDiskBufferEvictsOldestPayloads(t *testing.T) {
+	path, err := ioutil.TempDir("", "spill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	// each entry is made of a 5 bytes header ("null\n") and the payload, only two of them fit
+	b, err := NewDiskBuffer(path, 30)
+	assert.Nil(t, err)
+
+	assert.Nil(t, b.Store([]byte("payload 1"), nil))
+	assert.Nil(t, b.Store([]byte("payload 2"), nil))
+	assert.Nil(t, b.Store([]byte("payload 3"), nil))
+	assert.Equal(t, 2, len(b.files))
+	assert.Equal(t, int64(28), b.currentSizeInBytes)
+	assert.Equal(t, "payload 2", string(b.Peek().payload))
+
+	assert.NotNil(t, b.Store(make([]byte, 26), nil))
+	assert.Equal(t, 2, len(b.files))
+}
+
+func TestDiskBufferReloadsExistingFiles(t *testing.T) {
+	path, err := ioutil.TempDir("", "spill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	b, err := NewDiskBuffer(path, 1000)
+	assert.Nil(t, err)
+	assert.Nil(t, b.Store([]byte("payload 1"), []*message.Message{newOriginMessage("file:/var/log/a.log", "10")}))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(path, "unrelated.txt"), []byte("foo"), 0600))
+
+	b, err = NewDiskBuffer(path, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(b.files))
+
+	payload := b.Peek()
+	assert.Equal(t, "payload 1", string(payload.payload))
+	assert.Equal(t, "10", payload.messages[0].Origin.Offset)
+}
+
+func TestDiskBufferRemovesCorruptedFiles(t *testing.T) {
+	path, err := ioutil.TempDir("", "spill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(path, "corrupted"+spillFileExtension), []byte("not a spilled payload"), 0600))
+
+	b, err := NewDiskBuffer(path, 1000)
+	assert.Nil(t, err)
+	assert.False(t, b.IsEmpty())
+	assert.Nil(t, b.Peek())
+	assert.True(t, b.IsEmpty())
+}
+
+func TestDiskBufferRemovesMissingFiles(t *testing.T) {
+	path, err := ioutil.TempDir("", "spill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	b, err := NewDiskBuffer(path, 1000)
+	assert.Nil(t, err)
+	assert.Nil(t, b.Store([]byte("payload 1"), nil))
+	assert.Nil(t, b.Store([]byte("payload 2"), nil))
+	assert.Nil(t, os.Remove(b.files[0].name))
+
+	// the size of the missing file is released as well
+	assert.Equal(t, "payload 2", string(b.Peek().payload))
+	assert.Equal(t, 1, len(b.files))
+	assert.Equal(t, int64(14), b.currentSizeInBytes)
+}
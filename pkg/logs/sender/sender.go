@@ -7,18 +7,29 @@ package sender
 
 import (
 	"context"
+	"errors"
+	"math/rand"
 	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 )
 
+const maxReplayBackoffCount = 7
+
+// errSpilled is returned when the payload has been stored in the disk buffer,
+// its messages are forwarded to the next stage of the pipeline once the payload is replayed.
+var errSpilled = errors.New("payload stored in the disk buffer")
+
 // Strategy should contain all logic to send logs to a remote destination
 // and forward them the next stage of the pipeline.
 type Strategy interface {
-	Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error, mu *sync.Mutex)
-	Flush(ctx context.Context, inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error, mu *sync.Mutex)
+	Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte, []*message.Message) error, mu *sync.Mutex)
+	Flush(ctx context.Context, inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte, []*message.Message) error, mu *sync.Mutex)
 }
 
 // Sender sends logs to different destinations.
@@ -29,6 +40,9 @@ type Sender struct {
 	strategy     Strategy
 	done         chan struct{}
 	mu           sync.Mutex
+	diskBuffer   *DiskBuffer
+	stopReplay   chan struct{}
+	replayDone   chan struct{}
 }
 
 // NewSender returns a new sender.
@@ -42,8 +56,22 @@ func NewSender(inputChan chan *message.Message, outputChan chan *message.Message
 	}
 }
 
+// NewSenderWithDiskBuffer returns a new sender storing on disk the payloads that can not
+// be sent to the main destination, they are replayed once the destination recovers.
+// The messages are forwarded to the next stage of the pipeline only once they have been sent.
+func NewSenderWithDiskBuffer(inputChan chan *message.Message, outputChan chan *message.Message, destinations *client.Destinations, strategy Strategy, diskBuffer *DiskBuffer) *Sender {
+	sender := NewSender(inputChan, outputChan, destinations, strategy)
+	sender.diskBuffer = diskBuffer
+	return sender
+}
+
 // Start starts the sender.
 func (s *Sender) Start() {
+	if s.diskBuffer != nil {
+		s.stopReplay = make(chan struct{})
+		s.replayDone = make(chan struct{})
+		go s.replay()
+	}
 	go s.run()
 }
 
@@ -52,6 +80,11 @@ func (s *Sender) Start() {
 func (s *Sender) Stop() {
 	close(s.inputChan)
 	<-s.done
+	if s.diskBuffer != nil {
+		// the payloads not replayed yet are kept on disk for the next run
+		close(s.stopReplay)
+		<-s.replayDone
+	}
 }
 
 // Flush sends synchronously the messages that this sender has to send.
@@ -69,7 +102,11 @@ func (s *Sender) run() {
 // send sends a payload to multiple destinations,
 // it will forever retry for the main destination unless the error is not retryable
 // and only try once for additionnal destinations.
-func (s *Sender) send(payload []byte) error {
+// When a disk buffer is set, the payload is stored on disk instead of being retried.
+func (s *Sender) send(payload []byte, messages []*message.Message) error {
+	if s.diskBuffer != nil {
+		return s.sendOrSpill(payload, messages)
+	}
 	for {
 		err := s.destinations.Main.Send(payload)
 		if err != nil {
@@ -85,16 +122,116 @@ func (s *Sender) send(payload []byte) error {
 		break
 	}
 
+	s.sendAdditionals(payload)
+	return nil
+}
+
+// sendOrSpill sends a payload to the main destination, the payload is stored in the disk buffer
+// if the destination is not available or if older payloads are waiting to be replayed to keep them in order.
+func (s *Sender) sendOrSpill(payload []byte, messages []*message.Message) error {
+	if s.diskBuffer.IsEmpty() {
+		err := s.destinations.Main.Send(payload)
+		if err == nil {
+			s.sendAdditionals(payload)
+			return nil
+		}
+		metrics.DestinationErrors.Add(1)
+		metrics.TlmDestinationErrors.Inc()
+		if _, ok := err.(*client.RetryableError); !ok {
+			return err
+		}
+	}
+	if err := s.diskBuffer.Store(payload, messages); err != nil {
+		log.Warnf("Could not store payload in the disk buffer, dropping it: %v", err)
+		return err
+	}
+	return errSpilled
+}
+
+// replay sends the payloads stored in the disk buffer, from the oldest to the newest,
+// with a randomized exponential backoff in case of failure.
+func (s *Sender) replay() {
+	defer close(s.replayDone)
+	var retries uint
+	for {
+		select {
+		case <-s.stopReplay:
+			return
+		default:
+		}
+		bufferedPayload := s.diskBuffer.Peek()
+		if bufferedPayload == nil {
+			select {
+			case <-s.diskBuffer.Notify():
+				continue
+			case <-s.stopReplay:
+				return
+			}
+		}
+		err := s.destinations.Main.Send(bufferedPayload.payload)
+		if err != nil {
+			if shouldStopSending(err) {
+				return
+			}
+			metrics.DestinationErrors.Add(1)
+			metrics.TlmDestinationErrors.Inc()
+			if _, ok := err.(*client.RetryableError); ok {
+				retries++
+				if !s.backoff(retries) {
+					return
+				}
+				continue
+			}
+			log.Warnf("Could not replay payload: %v", err)
+		} else {
+			s.sendAdditionals(bufferedPayload.payload)
+			metrics.TlmSpillReplayed.Inc()
+		}
+		retries = 0
+
+		metrics.LogsSent.Add(int64(len(bufferedPayload.messages)))
+		metrics.TlmLogsSent.Add(float64(len(bufferedPayload.messages)))
+		for _, message := range bufferedPayload.messages {
+			s.outputChan <- message
+		}
+		s.diskBuffer.Remove(bufferedPayload)
+	}
+}
+
+// backoff waits before the next replay attempt, returns false if the sender is stopping.
+func (s *Sender) backoff(retries uint) bool {
+	if retries > maxReplayBackoffCount {
+		retries = maxReplayBackoffCount
+	}
+	backoffMax := 1 << retries
+	backoffMin := 1 << (retries - 1)
+	backoffDuration := time.Duration(backoffMin+rand.Intn(backoffMax-backoffMin)) * time.Second
+	timer := time.NewTimer(backoffDuration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.stopReplay:
+		return false
+	}
+}
+
+// sendAdditionals sends the payload to the additional destinations.
+func (s *Sender) sendAdditionals(payload []byte) {
 	for _, destination := range s.destinations.Additionals {
 		// send in the background so that the agent does not fall behind
 		// for the main destination
 		destination.SendAsync(payload)
 	}
-
-	return nil
 }
 
 // shouldStopSending returns true if a component should stop sending logs.
 func shouldStopSending(err error) bool {
 	return err == context.Canceled
 }
+
+// isSpilled returns true if the payload has been stored in the disk buffer
+// and its messages must not be forwarded to the next stage of the pipeline yet.
+func isSpilled(err error) bool {
+	return err == errSpilled
+}
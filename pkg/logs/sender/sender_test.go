@@ -6,7 +6,11 @@
 package sender
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -87,3 +91,83 @@ func TestSenderNotBlockedByAdditional(t *testing.T) {
 	sender.Stop()
 	destinationsCtx.Stop()
 }
+
+// unavailableDestination fails to send the first payloads.
+type unavailableDestination struct {
+	failures int
+	payloads chan []byte
+}
+
+func (d *unavailableDestination) Send(payload []byte) error {
+	if d.failures > 0 {
+		d.failures--
+		return client.NewRetryableError(errors.New("intake unavailable"))
+	}
+	d.payloads <- payload
+	return nil
+}
+
+func (d *unavailableDestination) SendAsync(payload []byte) {}
+
+func TestSenderWithDiskBuffer(t *testing.T) {
+	path, err := ioutil.TempDir("", "spill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	diskBuffer, err := NewDiskBuffer(path, 1000)
+	assert.Nil(t, err)
+
+	input := make(chan *message.Message, 1)
+	output := make(chan *message.Message, 1)
+
+	destination := &unavailableDestination{failures: 1, payloads: make(chan []byte, 2)}
+	destinations := client.NewDestinations(destination, nil)
+
+	sender := NewSenderWithDiskBuffer(input, output, destinations, StreamStrategy, diskBuffer)
+	sender.Start()
+
+	// the first payload is spilled then replayed, the messages are forwarded once sent
+	input <- newOriginMessage("file:/var/log/a.log", "10")
+	assert.Equal(t, "file:/var/log/a.log10", string(<-destination.payloads))
+	message := <-output
+	assert.Equal(t, "file:/var/log/a.log", message.Origin.Identifier)
+	assert.Equal(t, "10", message.Origin.Offset)
+
+	// the destination is available again, the payload is sent directly
+	expectedMessage := newOriginMessage("file:/var/log/a.log", "20")
+	input <- expectedMessage
+	assert.Equal(t, "file:/var/log/a.log20", string(<-destination.payloads))
+	assert.Equal(t, expectedMessage, <-output)
+
+	sender.Stop()
+	assert.True(t, diskBuffer.IsEmpty())
+}
+
+func TestSenderWithDiskBufferKeepsPayloadsOnStop(t *testing.T) {
+	path, err := ioutil.TempDir("", "spill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	diskBuffer, err := NewDiskBuffer(path, 1000)
+	assert.Nil(t, err)
+
+	input := make(chan *message.Message, 1)
+	output := make(chan *message.Message, 1)
+
+	destination := &unavailableDestination{failures: 10, payloads: make(chan []byte, 1)}
+	destinations := client.NewDestinations(destination, nil)
+
+	sender := NewSenderWithDiskBuffer(input, output, destinations, StreamStrategy, diskBuffer)
+	sender.Start()
+
+	input <- newOriginMessage("file:/var/log/a.log", "10")
+	assert.Eventually(t, func() bool { return !diskBuffer.IsEmpty() }, time.Second, 10*time.Millisecond)
+	sender.Stop()
+
+	// the messages are not forwarded to the auditor until the payload is sent
+	assert.Equal(t, 0, len(output))
+
+	diskBuffer, err = NewDiskBuffer(path, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, "file:/var/log/a.log10", string(diskBuffer.Peek().payload))
+}
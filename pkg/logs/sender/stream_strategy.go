@@ -21,25 +21,28 @@ var StreamStrategy Strategy = &streamStrategy{}
 // streamStrategy contains all the logic to send one log at a time.
 type streamStrategy struct{}
 
-func (s *streamStrategy) Flush(ctx context.Context, inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error, mu *sync.Mutex) {
+func (s *streamStrategy) Flush(ctx context.Context, inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte, []*message.Message) error, mu *sync.Mutex) {
 	// nothing to do
 }
 
 // Send sends one message at a time and forwards them to the next stage of the pipeline.
-func (s *streamStrategy) Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte) error, mu *sync.Mutex) {
-	for message := range inputChan {
-		if message.Origin != nil {
-			message.Origin.LogSource.LatencyStats.Add(message.GetLatency())
+func (s *streamStrategy) Send(inputChan chan *message.Message, outputChan chan *message.Message, send func([]byte, []*message.Message) error, mu *sync.Mutex) {
+	for msg := range inputChan {
+		if msg.Origin != nil {
+			msg.Origin.LogSource.LatencyStats.Add(msg.GetLatency())
 		}
-		err := send(message.Content)
+		err := send(msg.Content, []*message.Message{msg})
 		if err != nil {
 			if shouldStopSending(err) {
 				return
 			}
+			if isSpilled(err) {
+				continue
+			}
 			log.Warnf("Could not send payload: %v", err)
 		}
 		metrics.LogsSent.Add(1)
 		metrics.TlmLogsSent.Inc()
-		outputChan <- message
+		outputChan <- msg
 	}
 }
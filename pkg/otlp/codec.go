@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// Codec is the gRPC codec of the OTLP messages, whose stubs are not generated. It
+// implements both the codec interface of the gRPC clients and the one of the servers.
+type Codec struct{}
+
+// Marshal implements the gRPC codec
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return proto.Marshal(v.(proto.Message))
+}
+
+// Unmarshal implements the gRPC codec
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return proto.Unmarshal(data, v.(proto.Message))
+}
+
+// Name implements the gRPC codec of the clients
+func (Codec) Name() string {
+	return "proto"
+}
+
+// String implements the gRPC codec of the servers
+func (Codec) String() string {
+	return "proto"
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package otlp holds the subset of the messages of the OpenTelemetry protocol (OTLP) used
+// by the Agent, see https://github.com/open-telemetry/opentelemetry-proto/tree/main/opentelemetry/proto
+// The messages are not generated by protoc, the `oneof` fields are defined as optional
+// fields, which share their wire format.
+package otlp
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// Resource is the entity producing the telemetry.
+type Resource struct {
+	Attributes []*KeyValue `protobuf:"bytes,1,rep,name=attributes,proto3" json:"attributes,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Resource) Reset() { *m = Resource{} }
+
+// String implements proto.Message
+func (m *Resource) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Resource) ProtoMessage() {}
+
+// InstrumentationScope identifies the library producing the telemetry.
+type InstrumentationScope struct {
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *InstrumentationScope) Reset() { *m = InstrumentationScope{} }
+
+// String implements proto.Message
+func (m *InstrumentationScope) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*InstrumentationScope) ProtoMessage() {}
+
+// KeyValue is an attribute of a resource, span, event or data point.
+type KeyValue struct {
+	Key   string    `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value *AnyValue `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *KeyValue) Reset() { *m = KeyValue{} }
+
+// String implements proto.Message
+func (m *KeyValue) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*KeyValue) ProtoMessage() {}
+
+// AnyValue is the value of an attribute, only one of its fields is set.
+type AnyValue struct {
+	StringValue *string       `protobuf:"bytes,1,opt,name=string_value,json=stringValue" json:"string_value,omitempty"`
+	BoolValue   *bool         `protobuf:"varint,2,opt,name=bool_value,json=boolValue" json:"bool_value,omitempty"`
+	IntValue    *int64        `protobuf:"varint,3,opt,name=int_value,json=intValue" json:"int_value,omitempty"`
+	DoubleValue *float64      `protobuf:"fixed64,4,opt,name=double_value,json=doubleValue" json:"double_value,omitempty"`
+	ArrayValue  *ArrayValue   `protobuf:"bytes,5,opt,name=array_value,json=arrayValue" json:"array_value,omitempty"`
+	KvlistValue *KeyValueList `protobuf:"bytes,6,opt,name=kvlist_value,json=kvlistValue" json:"kvlist_value,omitempty"`
+	BytesValue  []byte        `protobuf:"bytes,7,opt,name=bytes_value,json=bytesValue" json:"bytes_value,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *AnyValue) Reset() { *m = AnyValue{} }
+
+// String implements proto.Message
+func (m *AnyValue) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*AnyValue) ProtoMessage() {}
+
+// ArrayValue is a list of values.
+type ArrayValue struct {
+	Values []*AnyValue `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ArrayValue) Reset() { *m = ArrayValue{} }
+
+// String implements proto.Message
+func (m *ArrayValue) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ArrayValue) ProtoMessage() {}
+
+// KeyValueList is a list of attributes.
+type KeyValueList struct {
+	Values []*KeyValue `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *KeyValueList) Reset() { *m = KeyValueList{} }
+
+// String implements proto.Message
+func (m *KeyValueList) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*KeyValueList) ProtoMessage() {}
@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// MetricsExportMethod is the gRPC method of the OTLP metrics export requests
+const MetricsExportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+// ExportMetricsServiceRequest is the payload of an OTLP metrics export request.
+type ExportMetricsServiceRequest struct {
+	ResourceMetrics []*ResourceMetrics `protobuf:"bytes,1,rep,name=resource_metrics,json=resourceMetrics,proto3" json:"resource_metrics,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ExportMetricsServiceRequest) Reset() { *m = ExportMetricsServiceRequest{} }
+
+// String implements proto.Message
+func (m *ExportMetricsServiceRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ExportMetricsServiceRequest) ProtoMessage() {}
+
+// ExportMetricsServiceResponse is the response to an OTLP metrics export request, its fields are skipped.
+type ExportMetricsServiceResponse struct{}
+
+// Reset implements proto.Message
+func (m *ExportMetricsServiceResponse) Reset() { *m = ExportMetricsServiceResponse{} }
+
+// String implements proto.Message
+func (m *ExportMetricsServiceResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ExportMetricsServiceResponse) ProtoMessage() {}
+
+// ResourceMetrics holds the metrics of a resource.
+type ResourceMetrics struct {
+	Resource     *Resource       `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	ScopeMetrics []*ScopeMetrics `protobuf:"bytes,2,rep,name=scope_metrics,json=scopeMetrics,proto3" json:"scope_metrics,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ResourceMetrics) Reset() { *m = ResourceMetrics{} }
+
+// String implements proto.Message
+func (m *ResourceMetrics) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ResourceMetrics) ProtoMessage() {}
+
+// ScopeMetrics holds the metrics produced by an instrumentation scope.
+type ScopeMetrics struct {
+	Scope   *InstrumentationScope `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	Metrics []*Metric             `protobuf:"bytes,2,rep,name=metrics,proto3" json:"metrics,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ScopeMetrics) Reset() { *m = ScopeMetrics{} }
+
+// String implements proto.Message
+func (m *ScopeMetrics) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ScopeMetrics) ProtoMessage() {}
+
+// Metric is a metric, only one of its data fields is set.
+type Metric struct {
+	Name    string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Gauge   *Gauge   `protobuf:"bytes,5,opt,name=gauge,proto3" json:"gauge,omitempty"`
+	Sum     *Sum     `protobuf:"bytes,7,opt,name=sum,proto3" json:"sum,omitempty"`
+	Summary *Summary `protobuf:"bytes,11,opt,name=summary,proto3" json:"summary,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Metric) Reset() { *m = Metric{} }
+
+// String implements proto.Message
+func (m *Metric) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Metric) ProtoMessage() {}
+
+// Gauge holds the data points of a gauge.
+type Gauge struct {
+	DataPoints []*NumberDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Gauge) Reset() { *m = Gauge{} }
+
+// String implements proto.Message
+func (m *Gauge) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Gauge) ProtoMessage() {}
+
+// Sum holds the data points of a sum.
+type Sum struct {
+	DataPoints             []*NumberDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	AggregationTemporality int32              `protobuf:"varint,2,opt,name=aggregation_temporality,json=aggregationTemporality,proto3" json:"aggregation_temporality,omitempty"`
+	IsMonotonic            bool               `protobuf:"varint,3,opt,name=is_monotonic,json=isMonotonic,proto3" json:"is_monotonic,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Sum) Reset() { *m = Sum{} }
+
+// String implements proto.Message
+func (m *Sum) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Sum) ProtoMessage() {}
+
+// Summary holds the data points of a summary.
+type Summary struct {
+	DataPoints []*SummaryDataPoint `protobuf:"bytes,1,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Summary) Reset() { *m = Summary{} }
+
+// String implements proto.Message
+func (m *Summary) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Summary) ProtoMessage() {}
+
+// NumberDataPoint is a value of a gauge or sum, timestamps are in nanoseconds.
+type NumberDataPoint struct {
+	StartTimeUnixNano uint64      `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano      uint64      `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	AsDouble          *float64    `protobuf:"fixed64,4,opt,name=as_double,json=asDouble" json:"as_double,omitempty"`
+	Attributes        []*KeyValue `protobuf:"bytes,7,rep,name=attributes,proto3" json:"attributes,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *NumberDataPoint) Reset() { *m = NumberDataPoint{} }
+
+// String implements proto.Message
+func (m *NumberDataPoint) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*NumberDataPoint) ProtoMessage() {}
+
+// SummaryDataPoint is a distribution summarized by its count, sum and quantiles.
+type SummaryDataPoint struct {
+	StartTimeUnixNano uint64             `protobuf:"fixed64,2,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	TimeUnixNano      uint64             `protobuf:"fixed64,3,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Count             uint64             `protobuf:"fixed64,4,opt,name=count,proto3" json:"count,omitempty"`
+	Sum               float64            `protobuf:"fixed64,5,opt,name=sum,proto3" json:"sum,omitempty"`
+	QuantileValues    []*ValueAtQuantile `protobuf:"bytes,6,rep,name=quantile_values,json=quantileValues,proto3" json:"quantile_values,omitempty"`
+	Attributes        []*KeyValue        `protobuf:"bytes,7,rep,name=attributes,proto3" json:"attributes,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *SummaryDataPoint) Reset() { *m = SummaryDataPoint{} }
+
+// String implements proto.Message
+func (m *SummaryDataPoint) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*SummaryDataPoint) ProtoMessage() {}
+
+// ValueAtQuantile is the value of a quantile of a summary.
+type ValueAtQuantile struct {
+	Quantile float64 `protobuf:"fixed64,1,opt,name=quantile,proto3" json:"quantile,omitempty"`
+	Value    float64 `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ValueAtQuantile) Reset() { *m = ValueAtQuantile{} }
+
+// String implements proto.Message
+func (m *ValueAtQuantile) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ValueAtQuantile) ProtoMessage() {}
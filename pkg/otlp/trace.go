@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package otlp
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// TracesExportMethod is the gRPC method of the OTLP traces export requests
+const TracesExportMethod = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+
+// SpanKind is the kind of a span.
+type SpanKind int32
+
+// The kinds of spans
+const (
+	SpanKindUnspecified SpanKind = 0
+	SpanKindInternal    SpanKind = 1
+	SpanKindServer      SpanKind = 2
+	SpanKindClient      SpanKind = 3
+	SpanKindProducer    SpanKind = 4
+	SpanKindConsumer    SpanKind = 5
+)
+
+// StatusCode is the status of a span.
+type StatusCode int32
+
+// The status codes of the spans
+const (
+	StatusCodeUnset StatusCode = 0
+	StatusCodeOk    StatusCode = 1
+	StatusCodeError StatusCode = 2
+)
+
+// ExportTraceServiceRequest is the payload of an OTLP traces export request.
+type ExportTraceServiceRequest struct {
+	ResourceSpans []*ResourceSpans `protobuf:"bytes,1,rep,name=resource_spans,json=resourceSpans,proto3" json:"resource_spans,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ExportTraceServiceRequest) Reset() { *m = ExportTraceServiceRequest{} }
+
+// String implements proto.Message
+func (m *ExportTraceServiceRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ExportTraceServiceRequest) ProtoMessage() {}
+
+// ExportTraceServiceResponse is the response to an OTLP traces export request, its fields are skipped.
+type ExportTraceServiceResponse struct{}
+
+// Reset implements proto.Message
+func (m *ExportTraceServiceResponse) Reset() { *m = ExportTraceServiceResponse{} }
+
+// String implements proto.Message
+func (m *ExportTraceServiceResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ExportTraceServiceResponse) ProtoMessage() {}
+
+// ResourceSpans holds the spans of a resource. The spans of the SDKs predating the
+// instrumentation scopes are in InstrumentationLibrarySpans.
+type ResourceSpans struct {
+	Resource                    *Resource     `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	ScopeSpans                  []*ScopeSpans `protobuf:"bytes,2,rep,name=scope_spans,json=scopeSpans,proto3" json:"scope_spans,omitempty"`
+	InstrumentationLibrarySpans []*ScopeSpans `protobuf:"bytes,1000,rep,name=instrumentation_library_spans,json=instrumentationLibrarySpans,proto3" json:"instrumentation_library_spans,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ResourceSpans) Reset() { *m = ResourceSpans{} }
+
+// String implements proto.Message
+func (m *ResourceSpans) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ResourceSpans) ProtoMessage() {}
+
+// ScopeSpans holds the spans produced by an instrumentation scope, it shares its
+// wire format with the deprecated InstrumentationLibrarySpans.
+type ScopeSpans struct {
+	Scope *InstrumentationScope `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	Spans []*Span               `protobuf:"bytes,2,rep,name=spans,proto3" json:"spans,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ScopeSpans) Reset() { *m = ScopeSpans{} }
+
+// String implements proto.Message
+func (m *ScopeSpans) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ScopeSpans) ProtoMessage() {}
+
+// Span is an operation of a trace, timestamps are in nanoseconds.
+type Span struct {
+	TraceID           []byte       `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	SpanID            []byte       `protobuf:"bytes,2,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	ParentSpanID      []byte       `protobuf:"bytes,4,opt,name=parent_span_id,json=parentSpanId,proto3" json:"parent_span_id,omitempty"`
+	Name              string       `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	Kind              SpanKind     `protobuf:"varint,6,opt,name=kind,proto3,enum=SpanKind" json:"kind,omitempty"`
+	StartTimeUnixNano uint64       `protobuf:"fixed64,7,opt,name=start_time_unix_nano,json=startTimeUnixNano,proto3" json:"start_time_unix_nano,omitempty"`
+	EndTimeUnixNano   uint64       `protobuf:"fixed64,8,opt,name=end_time_unix_nano,json=endTimeUnixNano,proto3" json:"end_time_unix_nano,omitempty"`
+	Attributes        []*KeyValue  `protobuf:"bytes,9,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	Events            []*SpanEvent `protobuf:"bytes,11,rep,name=events,proto3" json:"events,omitempty"`
+	Status            *SpanStatus  `protobuf:"bytes,15,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Span) Reset() { *m = Span{} }
+
+// String implements proto.Message
+func (m *Span) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Span) ProtoMessage() {}
+
+// SpanEvent is an event which happened during a span.
+type SpanEvent struct {
+	TimeUnixNano uint64      `protobuf:"fixed64,1,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Name         string      `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Attributes   []*KeyValue `protobuf:"bytes,3,rep,name=attributes,proto3" json:"attributes,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *SpanEvent) Reset() { *m = SpanEvent{} }
+
+// String implements proto.Message
+func (m *SpanEvent) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*SpanEvent) ProtoMessage() {}
+
+// SpanStatus is the status of a span.
+type SpanStatus struct {
+	Message string     `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Code    StatusCode `protobuf:"varint,3,opt,name=code,proto3,enum=StatusCode" json:"code,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *SpanStatus) Reset() { *m = SpanStatus{} }
+
+// String implements proto.Message
+func (m *SpanStatus) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*SpanStatus) ProtoMessage() {}
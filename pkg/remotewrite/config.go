@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// IsEnabled returns whether the Prometheus remote write receiver is enabled in the Agent configuration.
+func IsEnabled() bool {
+	return config.Datadog.GetBool("prometheus_remote_write.enabled")
+}
+
+// Config contains the configuration of the remote write receiver.
+type Config struct {
+	Port           int    `mapstructure:"port"`
+	BindHost       string `mapstructure:"bind_host"`
+	MaxRequestSize int64  `mapstructure:"max_request_size"`
+	// TimestampMaxAge and TimestampMaxFuture bound the timestamps of the samples, in seconds
+	TimestampMaxAge    int `mapstructure:"timestamp_max_age"`
+	TimestampMaxFuture int `mapstructure:"timestamp_max_future"`
+}
+
+// ReadConfig builds and returns configuration from Agent configuration.
+func ReadConfig() (*Config, error) {
+	var c Config
+	err := config.Datadog.UnmarshalKey("prometheus_remote_write", &c)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Port <= 0 {
+		return nil, fmt.Errorf("invalid prometheus_remote_write.port: %d", c.Port)
+	}
+	if c.MaxRequestSize <= 0 {
+		return nil, fmt.Errorf("invalid prometheus_remote_write.max_request_size: %d", c.MaxRequestSize)
+	}
+	if c.TimestampMaxAge <= 0 {
+		return nil, fmt.Errorf("invalid prometheus_remote_write.timestamp_max_age: %d", c.TimestampMaxAge)
+	}
+	if c.TimestampMaxFuture < 0 {
+		return nil, fmt.Errorf("invalid prometheus_remote_write.timestamp_max_future: %d", c.TimestampMaxFuture)
+	}
+	if c.BindHost == "" {
+		// Default to global bind_host option.
+		c.BindHost = config.GetBindHost()
+	}
+
+	return &c, nil
+}
+
+// Addr returns the host:port address to listen on.
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.BindHost, strconv.Itoa(c.Port))
+}
@@ -0,0 +1,238 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// writePath is the path of the remote write endpoint
+	writePath = "/api/v1/write"
+	// metricNameLabel is the label holding the name of the metric of a series
+	metricNameLabel = "__name__"
+	stopTimeout     = 5 * time.Second
+)
+
+// Server receives Prometheus remote write requests and forwards their samples to the aggregator.
+type Server struct {
+	config     *Config
+	listener   net.Listener
+	httpServer *http.Server
+
+	// samplesOut is the channel of the samples timestamped by their sender
+	samplesOut chan<- []metrics.MetricSample
+	samplePool *metrics.MetricSamplePool
+}
+
+var (
+	serverInstance *Server
+	startError     error
+)
+
+// StartServer starts the global remote write server.
+func StartServer(agg *aggregator.BufferedAggregator) error {
+	server, err := NewServer(agg)
+	serverInstance = server
+	startError = err
+	return err
+}
+
+// StopServer stops the global remote write server, if it is running.
+func StopServer() {
+	if serverInstance != nil {
+		serverInstance.Stop()
+		serverInstance = nil
+		startError = nil
+	}
+}
+
+// NewServer configures and returns a running remote write server.
+func NewServer(agg *aggregator.BufferedAggregator) (*Server, error) {
+	config, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", config.Addr())
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		config:     config,
+		listener:   listener,
+		samplesOut: agg.GetBufferedMetricsWithTsChannel(),
+		samplePool: agg.MetricSamplePool,
+	}
+	mux := http.NewServeMux()
+	mux.Handle(writePath, s)
+	s.httpServer = &http.Server{Handler: mux}
+
+	go func() {
+		log.Infof("Start listening for Prometheus remote write requests on %s", config.Addr())
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Prometheus remote write server stopped: %s", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// Stop stops the Server, waiting for the requests in flight to be processed.
+func (s *Server) Stop() {
+	log.Infof("Stop listening on %s", s.config.Addr())
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		log.Errorf("Stopping server. Timeout after %s: %s", stopTimeout, err)
+	}
+}
+
+// ServeHTTP handles a remote write request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats := getSenderStats(senderName(r))
+	stats.Add("Requests", 1)
+
+	request, status, err := s.readRequest(r)
+	if err != nil {
+		log.Debugf("Invalid Prometheus remote write request from %s: %s", r.RemoteAddr, err)
+		stats.Add("Errors", 1)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	samples, dropped := s.submit(request)
+	stats.Add("Series", int64(len(request.Timeseries)))
+	stats.Add("Samples", int64(samples))
+	if dropped > 0 {
+		stats.Add("DroppedSamples", int64(dropped))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readRequest decodes the snappy compressed protobuf payload of a request, both the compressed
+// and decompressed payloads are limited to max_request_size. The returned status code is the
+// one to reply with when an error is returned.
+func (s *Server) readRequest(r *http.Request) (*WriteRequest, int, error) {
+	compressed, err := ioutil.ReadAll(io.LimitReader(r.Body, s.config.MaxRequestSize+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if int64(len(compressed)) > s.config.MaxRequestSize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request larger than %d bytes", s.config.MaxRequestSize)
+	}
+
+	// the size is checked before decoding to not allocate huge buffers for malformed payloads
+	decodedLen, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if int64(decodedLen) > s.config.MaxRequestSize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("decompressed request larger than %d bytes", s.config.MaxRequestSize)
+	}
+	payload, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	var request WriteRequest
+	if err := proto.Unmarshal(payload, &request); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return &request, 0, nil
+}
+
+// submit converts the series of a request to metric samples sent to the aggregator with
+// their own timestamps. It returns the number of samples submitted and dropped.
+func (s *Server) submit(request *WriteRequest) (int, int) {
+	submitted, dropped := 0, 0
+	// the timestamps of the samples are in milliseconds
+	now := time.Now()
+	minTimestamp := now.Add(-time.Duration(s.config.TimestampMaxAge)*time.Second).UnixNano() / int64(time.Millisecond)
+	maxTimestamp := now.Add(time.Duration(s.config.TimestampMaxFuture)*time.Second).UnixNano() / int64(time.Millisecond)
+	batch := s.samplePool.GetBatch()
+	count := 0
+
+	for _, series := range request.Timeseries {
+		name, tags := seriesNameAndTags(series)
+		if name == "" {
+			dropped += len(series.Samples)
+			continue
+		}
+		for _, sample := range series.Samples {
+			// stale markers and infinite values can't be aggregated, like the samples too old or in the future
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) || sample.Timestamp < minTimestamp || sample.Timestamp > maxTimestamp {
+				dropped++
+				continue
+			}
+			if count == len(batch) {
+				s.samplesOut <- batch[:count]
+				batch = s.samplePool.GetBatch()
+				count = 0
+			}
+			batch[count] = metrics.MetricSample{
+				Name:       name,
+				Value:      sample.Value,
+				Mtype:      metrics.GaugeType,
+				Tags:       tags,
+				SampleRate: 1,
+				Timestamp:  float64(sample.Timestamp * int64(time.Millisecond)),
+			}
+			count++
+			submitted++
+		}
+	}
+
+	if count > 0 {
+		s.samplesOut <- batch[:count]
+	} else {
+		s.samplePool.PutBatch(batch)
+	}
+	return submitted, dropped
+}
+
+// seriesNameAndTags returns the metric name of a series and its other labels as tags.
+func seriesNameAndTags(series *TimeSeries) (string, []string) {
+	name := ""
+	tags := make([]string, 0, len(series.Labels))
+	for _, label := range series.Labels {
+		if label.Name == metricNameLabel {
+			name = label.Value
+			continue
+		}
+		tags = append(tags, label.Name+":"+label.Value)
+	}
+	return name, tags
+}
+
+// senderName identifies the sender of a request with its IP address.
+func senderName(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
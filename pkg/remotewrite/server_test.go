@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func newTestServer(batchSize int) (*Server, chan []metrics.MetricSample) {
+	samples := make(chan []metrics.MetricSample, 16)
+	return &Server{
+		config:     &Config{MaxRequestSize: 1024, TimestampMaxAge: 3600, TimestampMaxFuture: 600},
+		samplesOut: samples,
+		samplePool: metrics.NewMetricSamplePool(batchSize),
+	}, samples
+}
+
+func newWriteRequest(t *testing.T, request *WriteRequest) *http.Request {
+	payload, err := proto.Marshal(request)
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, writePath, bytes.NewReader(snappy.Encode(nil, payload)))
+	r.RemoteAddr = "10.0.0.1:41234"
+	return r
+}
+
+func series(name string, samples ...*Sample) *TimeSeries {
+	return &TimeSeries{
+		Labels: []*Label{
+			{Name: metricNameLabel, Value: name},
+			{Name: "job", Value: "api"},
+		},
+		Samples: samples,
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	server, samples := newTestServer(16)
+	ts := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	ms := func(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, newWriteRequest(t, &WriteRequest{Timeseries: []*TimeSeries{
+		series("http_requests_total",
+			&Sample{Value: 10, Timestamp: ts.UnixNano() / int64(time.Millisecond)},
+			&Sample{Value: math.NaN(), Timestamp: ts.UnixNano() / int64(time.Millisecond)},
+		),
+		series("queue_size", &Sample{Value: 3, Timestamp: ts.Add(time.Second).UnixNano() / int64(time.Millisecond)}),
+		{Labels: []*Label{{Name: "job", Value: "api"}}, Samples: []*Sample{{Value: 1, Timestamp: ms(ts)}}},
+		series("too_old", &Sample{Value: 1, Timestamp: ms(ts.Add(-2 * time.Hour))}),
+		series("in_future", &Sample{Value: 1, Timestamp: ms(ts.Add(time.Hour))}),
+	}}))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	require.Len(t, samples, 1)
+	batch := <-samples
+	assert.Equal(t, []metrics.MetricSample{
+		{
+			Name:       "http_requests_total",
+			Value:      10,
+			Mtype:      metrics.GaugeType,
+			Tags:       []string{"job:api"},
+			SampleRate: 1,
+			Timestamp:  float64(ts.UnixNano()),
+		},
+		{
+			Name:       "queue_size",
+			Value:      3,
+			Mtype:      metrics.GaugeType,
+			Tags:       []string{"job:api"},
+			SampleRate: 1,
+			Timestamp:  float64(ts.Add(time.Second).UnixNano()),
+		},
+	}, batch)
+
+	stats := getSenderStats("10.0.0.1")
+	assert.Equal(t, "1", stats.Get("Requests").String())
+	assert.Equal(t, "5", stats.Get("Series").String())
+	assert.Equal(t, "2", stats.Get("Samples").String())
+	assert.Equal(t, "4", stats.Get("DroppedSamples").String())
+	assert.Contains(t, GetStatus()["senders"], "10.0.0.1")
+}
+
+func TestServeHTTPBatches(t *testing.T) {
+	server, samples := newTestServer(2)
+
+	var batchSamples []*Sample
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	for i := 1; i <= 5; i++ {
+		batchSamples = append(batchSamples, &Sample{Value: float64(i), Timestamp: now + int64(i)})
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, newWriteRequest(t, &WriteRequest{Timeseries: []*TimeSeries{series("up", batchSamples...)}}))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	require.Len(t, samples, 3)
+	var values []float64
+	for i := 0; i < 3; i++ {
+		for _, sample := range <-samples {
+			values = append(values, sample.Value)
+		}
+	}
+	assert.Equal(t, []float64{1, 2, 3, 4, 5}, values)
+}
+
+func TestServeHTTPErrors(t *testing.T) {
+	server, samples := newTestServer(16)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, writePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, writePath, bytes.NewReader([]byte("not snappy"))))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, writePath, bytes.NewReader(snappy.Encode(nil, make([]byte, 2048))))
+	server.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	assert.Len(t, samples, 0)
+}
+
+func TestGetSenderStatsLimit(t *testing.T) {
+	remoteWriteSendersMu.Lock()
+	remoteWriteSenders.Init()
+	remoteWriteSendersCount = 0
+	remoteWriteSendersMu.Unlock()
+
+	for i := 0; i < maxTrackedSenders; i++ {
+		getSenderStats(fmt.Sprintf("10.0.1.%d", i)).Add("Requests", 1)
+	}
+	// the stats of the senders beyond the limit are added together
+	getSenderStats("10.0.2.1").Add("Requests", 1)
+	getSenderStats("10.0.2.2").Add("Requests", 1)
+
+	senders := GetStatus()["senders"].(map[string]interface{})
+	assert.Len(t, senders, maxTrackedSenders+1)
+	assert.NotContains(t, senders, "10.0.2.1")
+	assert.Equal(t, "2", getSenderStats(otherSenders).Get("Requests").String())
+	// the tracked senders are still tracked separately
+	assert.Equal(t, "1", getSenderStats("10.0.1.0").Get("Requests").String())
+}
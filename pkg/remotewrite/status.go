@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+)
+
+const (
+	// maxTrackedSenders is the maximum number of senders whose stats are tracked separately
+	maxTrackedSenders = 100
+	// otherSenders holds the stats of the senders beyond maxTrackedSenders
+	otherSenders = "other"
+)
+
+var (
+	remoteWriteExpvars = expvar.NewMap("remote_write")
+	// remoteWriteSenders holds the ingestion stats of each sender, indexed by its address
+	remoteWriteSenders      = expvar.Map{}
+	remoteWriteSendersCount int
+	remoteWriteSendersMu    sync.Mutex
+)
+
+func init() {
+	remoteWriteExpvars.Set("Senders", &remoteWriteSenders)
+}
+
+// getSenderStats returns the ingestion stats of a sender, creating them on its first request.
+// Once maxTrackedSenders are tracked, the stats of the new senders are added together.
+func getSenderStats(sender string) *expvar.Map {
+	remoteWriteSendersMu.Lock()
+	defer remoteWriteSendersMu.Unlock()
+
+	if stats, ok := remoteWriteSenders.Get(sender).(*expvar.Map); ok {
+		return stats
+	}
+	if remoteWriteSendersCount >= maxTrackedSenders {
+		sender = otherSenders
+		if stats, ok := remoteWriteSenders.Get(sender).(*expvar.Map); ok {
+			return stats
+		}
+	} else {
+		remoteWriteSendersCount++
+	}
+	stats := new(expvar.Map).Init()
+	remoteWriteSenders.Set(sender, stats)
+	return stats
+}
+
+// GetStatus returns key-value data for use in status reporting of the remote write receiver.
+func GetStatus() map[string]interface{} {
+	status := make(map[string]interface{})
+
+	sendersJSON := []byte(remoteWriteSenders.String())
+	senders := make(map[string]interface{})
+	json.Unmarshal(sendersJSON, &senders) //nolint:errcheck
+	status["senders"] = senders
+
+	if startError != nil {
+		status["error"] = startError.Error()
+	}
+
+	return status
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// The types below are the subset of the messages of the Prometheus remote write protocol
+// used by the server, see https://github.com/prometheus/prometheus/blob/master/prompb/remote.proto
+// The fields not defined here, like the metadata of the write requests, are skipped when decoding.
+
+// WriteRequest is the payload of a remote write request.
+type WriteRequest struct {
+	Timeseries []*TimeSeries `protobuf:"bytes,1,rep,name=timeseries,proto3" json:"timeseries,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *WriteRequest) Reset() { *m = WriteRequest{} }
+
+// String implements proto.Message
+func (m *WriteRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*WriteRequest) ProtoMessage() {}
+
+// TimeSeries holds the samples of a series identified by its labels.
+type TimeSeries struct {
+	Labels  []*Label  `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"`
+	Samples []*Sample `protobuf:"bytes,2,rep,name=samples,proto3" json:"samples,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *TimeSeries) Reset() { *m = TimeSeries{} }
+
+// String implements proto.Message
+func (m *TimeSeries) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*TimeSeries) ProtoMessage() {}
+
+// Label is a label of a series, the metric name is the __name__ label.
+type Label struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Label) Reset() { *m = Label{} }
+
+// String implements proto.Message
+func (m *Label) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Label) ProtoMessage() {}
+
+// Sample is a value of a series, its timestamp is in milliseconds.
+type Sample struct {
+	Value     float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp int64   `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *Sample) Reset() { *m = Sample{} }
+
+// String implements proto.Message
+func (m *Sample) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*Sample) ProtoMessage() {}
@@ -16,8 +16,10 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/process/util/api/headers"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/serializer/sink"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
 	"github.com/DataDog/datadog-agent/pkg/serializer/stream"
 	"github.com/DataDog/datadog-agent/pkg/util/compression"
@@ -123,6 +125,12 @@ type Serializer struct {
 	enableServiceChecksJSONStream bool
 	enableEventsJSONStream        bool
 	enableSketchProtobufStream    bool
+
+	// sinks receive the series, sketches, service checks and events in addition
+	// to the Datadog intake, which can be disabled to only use the sinks.
+	// They are only set by NewSerializerWithSinks.
+	sinks        sink.Sinks
+	enableIntake bool
 }
 
 // NewSerializer returns a new Serializer initialized
@@ -140,6 +148,7 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder forwarde
 		enableServiceChecksJSONStream: stream.Available && config.Datadog.GetBool("enable_service_checks_stream_payload_serialization"),
 		enableEventsJSONStream:        stream.Available && config.Datadog.GetBool("enable_events_stream_payload_serialization"),
 		enableSketchProtobufStream:    stream.Available && config.Datadog.GetBool("enable_sketch_stream_payload_serialization"),
+		enableIntake:                  true,
 	}
 
 	if !s.enableEvents {
@@ -157,10 +166,27 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder forwarde
 	if !s.enableJSONToV1Intake {
 		log.Warn("JSON to V1 intake is disabled: all payloads to that endpoint will be dropped")
 	}
+	return s
+}
 
+// NewSerializerWithSinks returns a new Serializer also sending the series, sketches, service checks
+// and events to the sinks, which are stopped by Stop. It is meant for the serializer of the
+// long-lived Agent process, short-lived commands should use NewSerializer.
+func NewSerializerWithSinks(forwarder forwarder.Forwarder, orchestratorForwarder forwarder.Forwarder, sinks sink.Sinks) *Serializer {
+	s := NewSerializer(forwarder, orchestratorForwarder)
+	s.sinks = sinks
+	s.enableIntake = config.Datadog.GetBool("metrics_sinks.datadog.enabled")
+	if !s.enableIntake {
+		log.Warn("the Datadog metrics sink is disabled: series, sketches, service_checks and events are only sent to the other metrics sinks")
+	}
 	return s
 }
 
+// Stop stops the sinks of the serializer.
+func (s *Serializer) Stop() {
+	s.sinks.Stop()
+}
+
 func (s Serializer) serializePayload(payload marshaler.Marshaler, compress bool, useV1API bool) (forwarder.Payloads, http.Header, error) {
 	var marshalType split.MarshalType
 	var extraHeaders http.Header
@@ -238,6 +264,13 @@ func (s *Serializer) SendEvents(e EventsStreamJSONMarshaler) error {
 		return nil
 	}
 
+	if events, ok := e.(metrics.Events); ok {
+		s.sinks.SendEvents(events)
+	}
+	if !s.enableIntake {
+		return nil
+	}
+
 	useV1API := !config.Datadog.GetBool("use_v2_api.events")
 	var eventPayloads forwarder.Payloads
 	var extraHeaders http.Header
@@ -265,6 +298,13 @@ func (s *Serializer) SendServiceChecks(sc marshaler.StreamJSONMarshaler) error {
 		return nil
 	}
 
+	if serviceChecks, ok := sc.(metrics.ServiceChecks); ok {
+		s.sinks.SendServiceChecks(serviceChecks)
+	}
+	if !s.enableIntake {
+		return nil
+	}
+
 	useV1API := !config.Datadog.GetBool("use_v2_api.service_checks")
 
 	var serviceCheckPayloads forwarder.Payloads
@@ -293,6 +333,13 @@ func (s *Serializer) SendSeries(series marshaler.StreamJSONMarshaler) error {
 		return nil
 	}
 
+	if metricSeries, ok := series.(metrics.Series); ok {
+		s.sinks.SendSeries(metricSeries)
+	}
+	if !s.enableIntake {
+		return nil
+	}
+
 	useV1API := !config.Datadog.GetBool("use_v2_api.series")
 
 	var seriesPayloads forwarder.Payloads
@@ -322,6 +369,13 @@ func (s *Serializer) SendSketch(sketches marshaler.Marshaler) error {
 		return nil
 	}
 
+	if sketchSeries, ok := sketches.(metrics.SketchSeriesList); ok {
+		s.sinks.SendSketches(sketchSeries)
+	}
+	if !s.enableIntake {
+		return nil
+	}
+
 	if s.enableSketchProtobufStream {
 		payloads, err := sketches.MarshalSplitCompress(marshaler.DefaultBufferContext())
 		if err == nil {
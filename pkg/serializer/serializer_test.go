@@ -19,7 +19,9 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/serializer/sink"
 	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
@@ -454,3 +456,80 @@ func TestSendWithDisabledKind(t *testing.T) {
 	s.SendMetadata(payload)
 	f.AssertNumberOfCalls(t, "SubmitMetadata", 1) // called once for the metadata
 }
+
+type recordingSink struct {
+	series        metrics.Series
+	sketches      metrics.SketchSeriesList
+	serviceChecks metrics.ServiceChecks
+	events        metrics.Events
+	stopped       bool
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Stop() { s.stopped = true }
+
+func (s *recordingSink) SendSeries(series metrics.Series) error {
+	s.series = append(s.series, series...)
+	return nil
+}
+
+func (s *recordingSink) SendSketches(sketches metrics.SketchSeriesList) error {
+	s.sketches = append(s.sketches, sketches...)
+	return nil
+}
+
+func (s *recordingSink) SendServiceChecks(serviceChecks metrics.ServiceChecks) error {
+	s.serviceChecks = append(s.serviceChecks, serviceChecks...)
+	return nil
+}
+
+func (s *recordingSink) SendEvents(events metrics.Events) error {
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func TestSendToSinksWithDisabledIntake(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("metrics_sinks.datadog.enabled", false)
+	defer mockConfig.Set("metrics_sinks.datadog.enabled", true)
+
+	f := &forwarder.MockedForwarder{}
+	recorder := &recordingSink{}
+	s := NewSerializerWithSinks(f, nil, sink.Sinks{recorder})
+
+	series := metrics.Series{{Name: "my.gauge", Points: []metrics.Point{{Value: 1, Ts: 10}}}}
+	sketches := metrics.SketchSeriesList{{Name: "my.distribution"}}
+	serviceChecks := metrics.ServiceChecks{{CheckName: "my.check"}}
+	events := metrics.Events{{Title: "my event"}}
+	require.NoError(t, s.SendSeries(series))
+	require.NoError(t, s.SendSketch(sketches))
+	require.NoError(t, s.SendServiceChecks(serviceChecks))
+	require.NoError(t, s.SendEvents(events))
+
+	assert.Equal(t, series, recorder.series)
+	assert.Equal(t, sketches, recorder.sketches)
+	assert.Equal(t, serviceChecks, recorder.serviceChecks)
+	assert.Equal(t, events, recorder.events)
+	f.AssertNotCalled(t, "SubmitV1Series")
+	f.AssertNotCalled(t, "SubmitSeries")
+	f.AssertNotCalled(t, "SubmitSketchSeries")
+	f.AssertNotCalled(t, "SubmitV1CheckRuns")
+	f.AssertNotCalled(t, "SubmitServiceChecks")
+	f.AssertNotCalled(t, "SubmitV1Intake")
+	f.AssertNotCalled(t, "SubmitEvents")
+
+	s.Stop()
+	assert.True(t, recorder.stopped)
+}
+
+func TestNewSerializerIgnoresSinks(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("metrics_sinks.datadog.enabled", false)
+	defer mockConfig.Set("metrics_sinks.datadog.enabled", true)
+
+	// the sinks are only used by the serializers built with NewSerializerWithSinks
+	s := NewSerializer(&forwarder.MockedForwarder{}, nil)
+	assert.Empty(t, s.sinks)
+	assert.True(t, s.enableIntake)
+}
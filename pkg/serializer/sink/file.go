@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const defaultFileName = "metrics.ndjson"
+
+// FileSink writes the payloads to a local file rotated by size, in the NDJSON
+// format: one JSON record per line, holding the type and data of an item.
+type FileSink struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// record is a line of the file
+type record struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// NewFileSink returns a FileSink writing to path. The file is rotated when it
+// would exceed maxSize bytes, up to maxFiles rotated files are kept as path.1,
+// path.2 and so on.
+func NewFileSink(path string, maxSize int64, maxFiles int) (*FileSink, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid max size: %d", maxSize)
+	}
+	if maxFiles < 0 {
+		return nil, fmt.Errorf("invalid max files: %d", maxFiles)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	s := &FileSink{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	file, size, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s.file, s.size = file, size
+	return s, nil
+}
+
+// Name implements Sink
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// SendSeries implements Sink
+func (s *FileSink) SendSeries(series metrics.Series) error {
+	items := make([]interface{}, 0, len(series))
+	for _, serie := range series {
+		items = append(items, serie)
+	}
+	return s.write("series", items)
+}
+
+// SendSketches implements Sink
+func (s *FileSink) SendSketches(sketches metrics.SketchSeriesList) error {
+	items := make([]interface{}, 0, len(sketches))
+	for _, sketch := range sketches {
+		items = append(items, sketch)
+	}
+	return s.write("sketch", items)
+}
+
+// SendServiceChecks implements Sink
+func (s *FileSink) SendServiceChecks(serviceChecks metrics.ServiceChecks) error {
+	items := make([]interface{}, 0, len(serviceChecks))
+	for _, serviceCheck := range serviceChecks {
+		items = append(items, serviceCheck)
+	}
+	return s.write("service_check", items)
+}
+
+// SendEvents implements Sink
+func (s *FileSink) SendEvents(events metrics.Events) error {
+	items := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		items = append(items, event)
+	}
+	return s.write("event", items)
+}
+
+// Stop implements Sink, it closes the file.
+func (s *FileSink) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Close(); err != nil {
+		log.Warnf("Could not close the file metrics sink %s: %v", s.path, err)
+	}
+}
+
+// write writes the records of the items, the file is rotated beforehand if it
+// would exceed the max size.
+func (s *FileSink) write(itemType string, items []interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, item := range items {
+		if err := encoder.Encode(record{Type: itemType, Data: item}); err != nil {
+			return fmt.Errorf("could not serialize %s: %v", itemType, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size > 0 && s.size+int64(buf.Len()) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			log.Warnf("Could not rotate %s: %v", s.path, err)
+		}
+	}
+	n, err := s.file.Write(buf.Bytes())
+	s.size += int64(n)
+	return err
+}
+
+// openFile opens the file in append mode and returns its size.
+func openFile(path string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+// rotate shifts the rotated files, the oldest one being removed, and reopens
+// the file, even if the shift failed. The current file is kept until the reopen
+// succeeds, its size is reset so that the rotation is only retried once it holds
+// max size bytes again instead of on every write.
+func (s *FileSink) rotate() error {
+	err := s.shift()
+	file, size, openErr := openFile(s.path)
+	if openErr != nil {
+		s.size = 0
+		return openErr
+	}
+	s.file.Close()
+	s.file, s.size = file, size
+	return err
+}
+
+func (s *FileSink) shift() error {
+	if s.maxFiles == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	for i := s.maxFiles - 1; i > 0; i-- {
+		if err := os.Rename(rotatedPath(s.path, i), rotatedPath(s.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, rotatedPath(s.path, 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func rotatedPath(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
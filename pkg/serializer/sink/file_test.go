@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func readRecords(t *testing.T, path string) []map[string]interface{} {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-sink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sink", defaultFileName)
+
+	s, err := NewFileSink(path, 1024*1024, 2)
+	require.NoError(t, err)
+	defer s.Stop()
+
+	require.NoError(t, s.SendSeries(metrics.Series{
+		{Name: "my.gauge", Points: []metrics.Point{{Value: 1, Ts: 10}}, Tags: []string{"env:prod"}, Host: "web-1"},
+		{Name: "my.count", Points: []metrics.Point{{Value: 2, Ts: 10}}, MType: metrics.APICountType},
+	}))
+	require.NoError(t, s.SendServiceChecks(metrics.ServiceChecks{{CheckName: "my.check", Status: metrics.ServiceCheckCritical}}))
+	require.NoError(t, s.SendEvents(metrics.Events{{Title: "my event"}}))
+	require.NoError(t, s.SendSketches(nil))
+
+	records := readRecords(t, path)
+	require.Len(t, records, 4)
+	assert.Equal(t, "series", records[0]["type"])
+	assert.Equal(t, map[string]interface{}{
+		"metric":   "my.gauge",
+		"points":   []interface{}{[]interface{}{float64(10), float64(1)}},
+		"tags":     []interface{}{"env:prod"},
+		"host":     "web-1",
+		"type":     "gauge",
+		"interval": float64(0),
+	}, records[0]["data"])
+	assert.Equal(t, "count", records[1]["data"].(map[string]interface{})["type"])
+	assert.Equal(t, "service_check", records[2]["type"])
+	assert.Equal(t, "my.check", records[2]["data"].(map[string]interface{})["check"])
+	assert.Equal(t, "event", records[3]["type"])
+	assert.Equal(t, "my event", records[3]["data"].(map[string]interface{})["msg_title"])
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-sink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, defaultFileName)
+
+	s, err := NewFileSink(path, 150, 2)
+	require.NoError(t, err)
+	defer s.Stop()
+
+	for _, name := range []string{"first", "second", "third", "fourth"} {
+		require.NoError(t, s.SendServiceChecks(metrics.ServiceChecks{{CheckName: name}}))
+	}
+
+	// each record is larger than half of the max size: one record per file
+	assertCheckName := func(path string, name string) {
+		records := readRecords(t, path)
+		require.Len(t, records, 1)
+		assert.Equal(t, name, records[0]["data"].(map[string]interface{})["check"])
+	}
+	assertCheckName(path, "fourth")
+	assertCheckName(path+".1", "third")
+	assertCheckName(path+".2", "second")
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestFileSinkRotationFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-sink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, defaultFileName)
+
+	s, err := NewFileSink(path, 150, 0)
+	require.NoError(t, err)
+	defer s.Stop()
+	require.NoError(t, s.SendServiceChecks(metrics.ServiceChecks{{CheckName: "first"}}))
+
+	// the file can't be reopened once moved: a non-empty directory takes its place
+	moved := filepath.Join(dir, "moved.ndjson")
+	require.NoError(t, os.Rename(path, moved))
+	require.NoError(t, os.MkdirAll(filepath.Join(path, "dir"), 0755))
+
+	// the records are still written to the current file, which is not rotated again right away
+	require.NoError(t, s.SendServiceChecks(metrics.ServiceChecks{{CheckName: "second"}}))
+	assert.Len(t, readRecords(t, moved), 2)
+	assert.Less(t, s.size, s.maxSize)
+}
+
+func TestNewFileSinkErrors(t *testing.T) {
+	_, err := NewFileSink("metrics.ndjson", 0, 1)
+	assert.Error(t, err)
+	_, err = NewFileSink("metrics.ndjson", 1024, -1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,303 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"google.golang.org/grpc"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/otlp"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	otlpProtocolHTTP = "http"
+	otlpProtocolGRPC = "grpc"
+
+	defaultOTLPHTTPEndpoint = "http://localhost:4318/v1/metrics"
+	defaultOTLPGRPCEndpoint = "localhost:4317"
+
+	// otlpQueueSize is the number of export requests waiting to be sent, the
+	// requests are dropped when the collector can't keep up
+	otlpQueueSize = 10
+
+	// aggregationTemporalityDelta is the temporality of the sums reset at every flush
+	aggregationTemporalityDelta = 1
+)
+
+// sketchQuantiles are the quantiles of the summaries of the sketches, 0 and 1 are the min and max
+var sketchQuantiles = []float64{0, 0.5, 0.75, 0.95, 0.99, 1}
+
+// OTLPSink exports the series, sketches and service checks as OTLP metrics to a
+// collector, over gRPC or HTTP. The events can't be exported as metrics, they are
+// skipped. The requests are queued and sent in the background, so that a slow
+// collector doesn't delay the serializer.
+type OTLPSink struct {
+	protocol string
+	endpoint string
+	timeout  time.Duration
+
+	httpClient *http.Client
+	grpcConn   *grpc.ClientConn
+
+	queue chan *otlp.ExportMetricsServiceRequest
+	done  chan struct{}
+}
+
+// NewOTLPSink returns an OTLPSink exporting to the endpoint of a collector with the
+// `http` or `grpc` protocol. The default endpoint of the protocol is used when the
+// endpoint is empty.
+func NewOTLPSink(protocol string, endpoint string, timeout time.Duration) (*OTLPSink, error) {
+	s := &OTLPSink{
+		protocol: protocol,
+		endpoint: endpoint,
+		timeout:  timeout,
+		queue:    make(chan *otlp.ExportMetricsServiceRequest, otlpQueueSize),
+		done:     make(chan struct{}),
+	}
+	switch protocol {
+	case otlpProtocolHTTP:
+		if s.endpoint == "" {
+			s.endpoint = defaultOTLPHTTPEndpoint
+		}
+		s.httpClient = &http.Client{Timeout: timeout}
+	case otlpProtocolGRPC:
+		if s.endpoint == "" {
+			s.endpoint = defaultOTLPGRPCEndpoint
+		}
+		// the connection is established in the background, and re-established when lost
+		conn, err := grpc.Dial(s.endpoint, grpc.WithInsecure())
+		if err != nil {
+			return nil, err
+		}
+		s.grpcConn = conn
+	default:
+		return nil, fmt.Errorf("invalid protocol %q, must be `%s` or `%s`", protocol, otlpProtocolHTTP, otlpProtocolGRPC)
+	}
+	go s.run()
+	return s, nil
+}
+
+// Stop implements Sink, it sends the queued requests and stops the sink.
+func (s *OTLPSink) Stop() {
+	close(s.queue)
+	<-s.done
+	if s.grpcConn != nil {
+		s.grpcConn.Close()
+	}
+}
+
+// run sends the queued requests to the collector until the sink is stopped.
+func (s *OTLPSink) run() {
+	defer close(s.done)
+	for request := range s.queue {
+		if err := s.send(request); err != nil {
+			log.Warnf("Error exporting metrics to the OTLP collector %s: %v", s.endpoint, err)
+		}
+	}
+}
+
+// Name implements Sink
+func (s *OTLPSink) Name() string {
+	return "otlp"
+}
+
+// SendSeries implements Sink
+func (s *OTLPSink) SendSeries(series metrics.Series) error {
+	otlpMetrics := make([]*otlp.Metric, 0, len(series))
+	for _, serie := range series {
+		attributes := toAttributes(serie.Tags, serie.Host, serie.Device)
+		var startTime uint64
+		if serie.Interval > 0 {
+			startTime = uint64(serie.Interval) * uint64(time.Second)
+		}
+		dataPoints := make([]*otlp.NumberDataPoint, 0, len(serie.Points))
+		for _, point := range serie.Points {
+			value := point.Value
+			ts := uint64(point.Ts * float64(time.Second))
+			dataPoints = append(dataPoints, &otlp.NumberDataPoint{
+				StartTimeUnixNano: ts - startTime,
+				TimeUnixNano:      ts,
+				AsDouble:          &value,
+				Attributes:        attributes,
+			})
+		}
+
+		metric := &otlp.Metric{Name: serie.Name}
+		if serie.MType == metrics.APICountType {
+			metric.Sum = &otlp.Sum{DataPoints: dataPoints, AggregationTemporality: aggregationTemporalityDelta}
+		} else {
+			metric.Gauge = &otlp.Gauge{DataPoints: dataPoints}
+		}
+		otlpMetrics = append(otlpMetrics, metric)
+	}
+	return s.export(otlpMetrics)
+}
+
+// SendSketches implements Sink
+func (s *OTLPSink) SendSketches(sketches metrics.SketchSeriesList) error {
+	config := quantile.Default()
+	otlpMetrics := make([]*otlp.Metric, 0, len(sketches))
+	for _, sketchSeries := range sketches {
+		attributes := toAttributes(sketchSeries.Tags, sketchSeries.Host, "")
+		dataPoints := make([]*otlp.SummaryDataPoint, 0, len(sketchSeries.Points))
+		for _, point := range sketchSeries.Points {
+			if point.Sketch == nil {
+				continue
+			}
+			ts := uint64(point.Ts) * uint64(time.Second)
+			dataPoint := &otlp.SummaryDataPoint{
+				StartTimeUnixNano: ts - uint64(sketchSeries.Interval)*uint64(time.Second),
+				TimeUnixNano:      ts,
+				Count:             uint64(point.Sketch.Basic.Cnt),
+				Sum:               point.Sketch.Basic.Sum,
+				Attributes:        attributes,
+			}
+			for _, q := range sketchQuantiles {
+				dataPoint.QuantileValues = append(dataPoint.QuantileValues, &otlp.ValueAtQuantile{
+					Quantile: q,
+					Value:    point.Sketch.Quantile(config, q),
+				})
+			}
+			dataPoints = append(dataPoints, dataPoint)
+		}
+		otlpMetrics = append(otlpMetrics, &otlp.Metric{Name: sketchSeries.Name, Summary: &otlp.Summary{DataPoints: dataPoints}})
+	}
+	return s.export(otlpMetrics)
+}
+
+// SendServiceChecks implements Sink, the service checks are exported as gauges
+// named after the checks, their value is the status of the check.
+func (s *OTLPSink) SendServiceChecks(serviceChecks metrics.ServiceChecks) error {
+	otlpMetrics := make([]*otlp.Metric, 0, len(serviceChecks))
+	for _, serviceCheck := range serviceChecks {
+		value := float64(serviceCheck.Status)
+		otlpMetrics = append(otlpMetrics, &otlp.Metric{
+			Name: serviceCheck.CheckName,
+			Gauge: &otlp.Gauge{DataPoints: []*otlp.NumberDataPoint{{
+				TimeUnixNano: uint64(serviceCheck.Ts) * uint64(time.Second),
+				AsDouble:     &value,
+				Attributes:   toAttributes(serviceCheck.Tags, serviceCheck.Host, ""),
+			}}},
+		})
+	}
+	return s.export(otlpMetrics)
+}
+
+// SendEvents implements Sink, the events are skipped.
+func (s *OTLPSink) SendEvents(events metrics.Events) error {
+	if len(events) > 0 {
+		log.Debugf("Skipping %d events, they can't be exported as OTLP metrics", len(events))
+	}
+	return nil
+}
+
+// export queues a request exporting the metrics to the collector.
+func (s *OTLPSink) export(otlpMetrics []*otlp.Metric) error {
+	if len(otlpMetrics) == 0 {
+		return nil
+	}
+	request := &otlp.ExportMetricsServiceRequest{
+		ResourceMetrics: []*otlp.ResourceMetrics{{
+			Resource: &otlp.Resource{},
+			ScopeMetrics: []*otlp.ScopeMetrics{{
+				Scope:   &otlp.InstrumentationScope{Name: "datadog-agent", Version: version.AgentVersion},
+				Metrics: otlpMetrics,
+			}},
+		}},
+	}
+
+	select {
+	case s.queue <- request:
+		return nil
+	default:
+		return fmt.Errorf("the export queue is full, dropping %d metrics", len(otlpMetrics))
+	}
+}
+
+// send sends an export request to the collector.
+func (s *OTLPSink) send(request *otlp.ExportMetricsServiceRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if s.protocol == otlpProtocolGRPC {
+		return s.grpcConn.Invoke(ctx, otlp.MetricsExportMethod, request, &otlp.ExportMetricsServiceResponse{}, grpc.ForceCodec(otlp.Codec{}))
+	}
+	return s.exportHTTP(ctx, request)
+}
+
+func (s *OTLPSink) exportHTTP(ctx context.Context, request *otlp.ExportMetricsServiceRequest) error {
+	payload, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := s.httpClient.Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected response from %s: %s %s", s.endpoint, resp.Status, body)
+	}
+	return nil
+}
+
+// toAttributes returns the attributes of the tags, host and device of a metric. The
+// values of the tags with the same key are joined with commas, the tags without
+// value get the `true` value.
+func toAttributes(tags []string, host string, device string) []*otlp.KeyValue {
+	values := make(map[string][]string, len(tags)+2)
+	add := func(key, value string) {
+		for _, v := range values[key] {
+			if v == value {
+				return
+			}
+		}
+		values[key] = append(values[key], value)
+	}
+	if host != "" {
+		add("host", host)
+	}
+	if device != "" {
+		add("device", device)
+	}
+	for _, tag := range tags {
+		if i := strings.IndexByte(tag, ':'); i >= 0 {
+			add(tag[:i], tag[i+1:])
+		} else {
+			add(tag, "true")
+		}
+	}
+
+	attributes := make([]*otlp.KeyValue, 0, len(values))
+	for key, keyValues := range values {
+		sort.Strings(keyValues)
+		value := strings.Join(keyValues, ",")
+		attributes = append(attributes, &otlp.KeyValue{Key: key, Value: &otlp.AnyValue{StringValue: &value}})
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+	return attributes
+}
@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/otlp"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+)
+
+func stringValue(s string) *otlp.AnyValue {
+	return &otlp.AnyValue{StringValue: &s}
+}
+
+func doubleValue(v float64) *float64 {
+	return &v
+}
+
+// exportedMetrics returns the metrics of the requests received by a collector
+func exportedMetrics(t *testing.T, requests chan *otlp.ExportMetricsServiceRequest) []*otlp.Metric {
+	var request *otlp.ExportMetricsServiceRequest
+	select {
+	case request = <-requests:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no request received by the collector")
+	}
+	require.Len(t, request.ResourceMetrics, 1)
+	require.Len(t, request.ResourceMetrics[0].ScopeMetrics, 1)
+	assert.Equal(t, "datadog-agent", request.ResourceMetrics[0].ScopeMetrics[0].Scope.Name)
+	return request.ResourceMetrics[0].ScopeMetrics[0].Metrics
+}
+
+func TestOTLPSinkHTTP(t *testing.T) {
+	requests := make(chan *otlp.ExportMetricsServiceRequest, 4)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		payload, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		request := &otlp.ExportMetricsServiceRequest{}
+		require.NoError(t, proto.Unmarshal(payload, request))
+		requests <- request
+	}))
+	defer collector.Close()
+
+	s, err := NewOTLPSink(otlpProtocolHTTP, collector.URL+"/v1/metrics", time.Second)
+	require.NoError(t, err)
+	defer s.Stop()
+
+	require.NoError(t, s.SendSeries(metrics.Series{
+		{
+			Name:   "my.gauge",
+			Points: []metrics.Point{{Value: 0, Ts: 10}},
+			Tags:   []string{"env:prod", "env:dev", "canary"},
+			Host:   "web-1",
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			Name:     "my.count",
+			Points:   []metrics.Point{{Value: 3, Ts: 20}},
+			MType:    metrics.APICountType,
+			Interval: 10,
+		},
+	}))
+	assert.Equal(t, []*otlp.Metric{
+		{
+			Name: "my.gauge",
+			Gauge: &otlp.Gauge{DataPoints: []*otlp.NumberDataPoint{{
+				StartTimeUnixNano: 10e9,
+				TimeUnixNano:      10e9,
+				AsDouble:          doubleValue(0),
+				Attributes: []*otlp.KeyValue{
+					{Key: "canary", Value: stringValue("true")},
+					{Key: "env", Value: stringValue("dev,prod")},
+					{Key: "host", Value: stringValue("web-1")},
+				},
+			}}},
+		},
+		{
+			Name: "my.count",
+			Sum: &otlp.Sum{
+				DataPoints: []*otlp.NumberDataPoint{{
+					StartTimeUnixNano: 10e9,
+					TimeUnixNano:      20e9,
+					AsDouble:          doubleValue(3),
+				}},
+				AggregationTemporality: aggregationTemporalityDelta,
+			},
+		},
+	}, exportedMetrics(t, requests))
+
+	agent := &quantile.Agent{}
+	for i := 1; i <= 10; i++ {
+		agent.Insert(float64(i), 1)
+	}
+	require.NoError(t, s.SendSketches(metrics.SketchSeriesList{{
+		Name:     "my.distribution",
+		Interval: 10,
+		Points:   []metrics.SketchPoint{{Sketch: agent.Finish(), Ts: 20}},
+	}}))
+	sketchMetrics := exportedMetrics(t, requests)
+	require.Len(t, sketchMetrics, 1)
+	require.NotNil(t, sketchMetrics[0].Summary)
+	dataPoint := sketchMetrics[0].Summary.DataPoints[0]
+	assert.Equal(t, uint64(10), dataPoint.Count)
+	assert.Equal(t, float64(55), dataPoint.Sum)
+	assert.Equal(t, uint64(10e9), dataPoint.StartTimeUnixNano)
+	require.Len(t, dataPoint.QuantileValues, len(sketchQuantiles))
+	assert.Equal(t, &otlp.ValueAtQuantile{Quantile: 0, Value: 1}, dataPoint.QuantileValues[0])
+	assert.Equal(t, &otlp.ValueAtQuantile{Quantile: 1, Value: 10}, dataPoint.QuantileValues[len(sketchQuantiles)-1])
+
+	// events are skipped
+	require.NoError(t, s.SendEvents(metrics.Events{{Title: "my event"}}))
+	assert.Len(t, requests, 0)
+}
+
+func TestOTLPSinkHTTPError(t *testing.T) {
+	var calls int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+	}))
+	defer collector.Close()
+
+	s, err := NewOTLPSink(otlpProtocolHTTP, collector.URL, time.Second)
+	require.NoError(t, err)
+	// the errors of the collector are logged in the background
+	require.NoError(t, s.SendServiceChecks(metrics.ServiceChecks{{CheckName: "my.check"}}))
+	s.Stop()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestOTLPSinkQueueFull(t *testing.T) {
+	unblock := make(chan struct{})
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer collector.Close()
+
+	s, err := NewOTLPSink(otlpProtocolHTTP, collector.URL, 5*time.Second)
+	require.NoError(t, err)
+	serviceChecks := metrics.ServiceChecks{{CheckName: "my.check"}}
+	// one request is being sent, the next ones are queued, then dropped
+	for i := 0; i < otlpQueueSize+1; i++ {
+		if err = s.SendServiceChecks(serviceChecks); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = s.SendServiceChecks(serviceChecks)
+	}
+	assert.EqualError(t, err, "the export queue is full, dropping 1 metrics")
+	close(unblock)
+	s.Stop()
+}
+
+func TestOTLPSinkGRPC(t *testing.T) {
+	requests := make(chan *otlp.ExportMetricsServiceRequest, 4)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(
+		grpc.CustomCodec(otlp.Codec{}),
+		grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			assert.Equal(t, otlp.MetricsExportMethod, method)
+			request := &otlp.ExportMetricsServiceRequest{}
+			if err := stream.RecvMsg(request); err != nil {
+				return err
+			}
+			requests <- request
+			return stream.SendMsg(&otlp.ExportMetricsServiceResponse{})
+		}),
+	)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	s, err := NewOTLPSink(otlpProtocolGRPC, listener.Addr().String(), 5*time.Second)
+	require.NoError(t, err)
+	defer s.Stop()
+
+	require.NoError(t, s.SendServiceChecks(metrics.ServiceChecks{{
+		CheckName: "my.check",
+		Status:    metrics.ServiceCheckCritical,
+		Ts:        10,
+		Host:      "web-1",
+	}}))
+	assert.Equal(t, []*otlp.Metric{{
+		Name: "my.check",
+		Gauge: &otlp.Gauge{DataPoints: []*otlp.NumberDataPoint{{
+			TimeUnixNano: 10e9,
+			AsDouble:     doubleValue(2),
+			Attributes:   []*otlp.KeyValue{{Key: "host", Value: stringValue("web-1")}},
+		}}},
+	}}, exportedMetrics(t, requests))
+}
+
+func TestNewOTLPSinkInvalidProtocol(t *testing.T) {
+	_, err := NewOTLPSink("udp", "", time.Second)
+	assert.Error(t, err)
+}
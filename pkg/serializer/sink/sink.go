@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sink
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Sink receives the series, sketches, service checks and events sent by the
+// serializer, in addition to or in place of the Datadog intake.
+type Sink interface {
+	Name() string
+	SendSeries(series metrics.Series) error
+	SendSketches(sketches metrics.SketchSeriesList) error
+	SendServiceChecks(serviceChecks metrics.ServiceChecks) error
+	SendEvents(events metrics.Events) error
+	Stop()
+}
+
+// Sinks sends the payloads to several sinks, the errors of each sink are logged.
+type Sinks []Sink
+
+// FromConfig returns the sinks enabled in the Agent configuration, the sinks
+// which can't be created are skipped.
+func FromConfig() Sinks {
+	var sinks Sinks
+	if config.Datadog.GetBool("metrics_sinks.file.enabled") {
+		path := config.Datadog.GetString("metrics_sinks.file.path")
+		if path == "" {
+			path = filepath.Join(config.Datadog.GetString("run_path"), defaultFileName)
+		}
+		sink, err := NewFileSink(
+			path,
+			config.Datadog.GetInt64("metrics_sinks.file.max_size"),
+			config.Datadog.GetInt("metrics_sinks.file.max_files"),
+		)
+		if err != nil {
+			log.Errorf("Could not create the file metrics sink: %v", err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+	if config.Datadog.GetBool("metrics_sinks.otlp.enabled") {
+		sink, err := NewOTLPSink(
+			config.Datadog.GetString("metrics_sinks.otlp.protocol"),
+			config.Datadog.GetString("metrics_sinks.otlp.endpoint"),
+			config.Datadog.GetDuration("metrics_sinks.otlp.timeout")*time.Second,
+		)
+		if err != nil {
+			log.Errorf("Could not create the OTLP metrics sink: %v", err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+	return sinks
+}
+
+// Stop stops every sink.
+func (sinks Sinks) Stop() {
+	for _, sink := range sinks {
+		sink.Stop()
+	}
+}
+
+// SendSeries sends the series to every sink.
+func (sinks Sinks) SendSeries(series metrics.Series) {
+	for _, sink := range sinks {
+		if err := sink.SendSeries(series); err != nil {
+			log.Warnf("Error sending series to the %s metrics sink: %v", sink.Name(), err)
+		}
+	}
+}
+
+// SendSketches sends the sketches to every sink.
+func (sinks Sinks) SendSketches(sketches metrics.SketchSeriesList) {
+	for _, sink := range sinks {
+		if err := sink.SendSketches(sketches); err != nil {
+			log.Warnf("Error sending sketches to the %s metrics sink: %v", sink.Name(), err)
+		}
+	}
+}
+
+// SendServiceChecks sends the service checks to every sink.
+func (sinks Sinks) SendServiceChecks(serviceChecks metrics.ServiceChecks) {
+	for _, sink := range sinks {
+		if err := sink.SendServiceChecks(serviceChecks); err != nil {
+			log.Warnf("Error sending service checks to the %s metrics sink: %v", sink.Name(), err)
+		}
+	}
+}
+
+// SendEvents sends the events to every sink.
+func (sinks Sinks) SendEvents(events metrics.Events) {
+	for _, sink := range sinks {
+		if err := sink.SendEvents(events); err != nil {
+			log.Warnf("Error sending events to the %s metrics sink: %v", sink.Name(), err)
+		}
+	}
+}
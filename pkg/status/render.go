@@ -16,6 +16,7 @@ import (
 	"text/template"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/remotewrite"
 	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 )
 
@@ -41,6 +42,7 @@ func FormatStatus(data []byte) (string, error) {
 	inventoriesStats := stats["inventories"]
 	systemProbeStats := stats["systemProbeStats"]
 	snmpTrapsStats := stats["snmpTrapsStats"]
+	remoteWriteStats := stats["remoteWriteStats"]
 	title := fmt.Sprintf("Agent (v%s)", stats["version"])
 	stats["title"] = title
 	renderStatusTemplate(b, "/header.tmpl", stats)
@@ -61,6 +63,9 @@ func FormatStatus(data []byte) (string, error) {
 	if traps.IsEnabled() {
 		renderStatusTemplate(b, "/snmp-traps.tmpl", snmpTrapsStats)
 	}
+	if remotewrite.IsEnabled() {
+		renderStatusTemplate(b, "/remotewrite.tmpl", remoteWriteStats)
+	}
 
 	return b.String(), nil
 }
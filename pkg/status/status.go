@@ -22,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
+	"github.com/DataDog/datadog-agent/pkg/remotewrite"
 	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
@@ -405,6 +406,8 @@ func expvarStats(stats map[string]interface{}) (map[string]interface{}, error) {
 
 	stats["snmpTrapsStats"] = traps.GetStatus()
 
+	stats["remoteWriteStats"] = remotewrite.GetStatus()
+
 	complianceVar := expvar.Get("compliance")
 	if complianceVar != nil {
 		complianceStatusJSON := []byte(complianceVar.String())
@@ -35,14 +35,17 @@ const (
 // Agent struct holds all the sub-routines structs and make the data flow between them
 type Agent struct {
 	Receiver          *api.HTTPReceiver
+	OTLPReceiver      *api.OTLPReceiver
 	Concentrator      *stats.Concentrator
 	Blacklister       *filters.Blacklister
 	Replacer          *filters.Replacer
+	SpanProcessor     *filters.SpanProcessor
 	PrioritySampler   *sampler.PrioritySampler
 	ErrorsSampler     *sampler.ErrorsSampler
 	ExceptionSampler  *sampler.ExceptionSampler
 	NoPrioritySampler *sampler.NoPrioritySampler
 	EventProcessor    *event.Processor
+	TailSampler       *TailSampler // nil when tail sampling is disabled
 	TraceWriter       *writer.TraceWriter
 	StatsWriter       *writer.StatsWriter
 
@@ -71,6 +74,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 		Concentrator:      stats.NewConcentrator(conf, statsChan, time.Now()),
 		Blacklister:       filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:          filters.NewReplacer(conf.ReplaceTags),
+		SpanProcessor:     filters.NewSpanProcessor(conf.SpanRules),
 		PrioritySampler:   sampler.NewPrioritySampler(conf, dynConf),
 		ErrorsSampler:     sampler.NewErrorsSampler(conf),
 		ExceptionSampler:  sampler.NewExceptionSampler(),
@@ -84,6 +88,10 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 		ctx:               ctx,
 	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt)
+	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf, agnt.Receiver.Stats)
+	if conf.TailSampling != nil && conf.TailSampling.Enabled {
+		agnt.TailSampler = NewTailSampler(conf, agnt.TraceWriter.In)
+	}
 	return agnt
 }
 
@@ -91,6 +99,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 func (a *Agent) Run() {
 	for _, starter := range []interface{ Start() }{
 		a.Receiver,
+		a.OTLPReceiver,
 		a.Concentrator,
 		a.PrioritySampler,
 		a.ErrorsSampler,
@@ -99,6 +108,9 @@ func (a *Agent) Run() {
 	} {
 		starter.Start()
 	}
+	if a.TailSampler != nil {
+		a.TailSampler.Start()
+	}
 
 	go a.TraceWriter.Run()
 	go a.StatsWriter.Run()
@@ -146,10 +158,18 @@ func (a *Agent) loop() {
 		select {
 		case <-a.ctx.Done():
 			log.Info("Exiting...")
+			// the OTLP receiver is stopped first as the HTTP receiver closes the channel of the payloads
+			if err := a.OTLPReceiver.Stop(); err != nil {
+				log.Error(err)
+			}
 			if err := a.Receiver.Stop(); err != nil {
 				log.Error(err)
 			}
 			a.Concentrator.Stop()
+			if a.TailSampler != nil {
+				// the buffered traces are decided before the trace writer is stopped
+				a.TailSampler.Stop()
+			}
 			a.TraceWriter.Stop()
 			a.StatsWriter.Stop()
 			a.PrioritySampler.Stop()
@@ -189,6 +209,9 @@ func (a *Agent) Process(p *api.Payload) {
 			atomic.AddInt64(&ts.SpansDropped, tracen)
 			continue
 		}
+		// the span rules apply to the normalized spans, before they are filtered, obfuscated
+		// and aggregated in the stats
+		a.SpanProcessor.Process(t)
 
 		// Root span is used to carry some trace-level metadata, such as sampling rate and priority.
 		root := traceutil.GetRoot(t)
@@ -327,14 +350,27 @@ func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (events []*pb.Span,
 		return nil, false
 	}
 
+	// the samplers are run even when the tail sampler decides, so that they keep
+	// counting the traffic to compute the rates sent back to the tracers
 	sampled := a.runSamplers(pt, hasPriority)
+	if a.TailSampler != nil {
+		// the tail sampler sends the complete trace itself once it is decided,
+		// overriding the decision of the samplers
+		a.TailSampler.Add(pt.Trace)
+		return a.extractEvents(ts, pt), false
+	}
+
+	return a.extractEvents(ts, pt), sampled
+}
 
+// extractEvents extracts the APM events of pt.
+func (a *Agent) extractEvents(ts *info.TagStats, pt ProcessedTrace) []*pb.Span {
 	events, numExtracted := a.EventProcessor.Process(pt.Root, pt.Trace)
 
 	atomic.AddInt64(&ts.EventsExtracted, int64(numExtracted))
 	atomic.AddInt64(&ts.EventsSampled, int64(len(events)))
 
-	return events, sampled
+	return events
 }
 
 // runSamplers runs all the agent's samplers on pt and returns the sampling decision
@@ -98,6 +98,43 @@ func TestProcess(t *testing.T) {
 		assert.Equal("SELECT name FROM people WHERE age = ? AND extra = ?", span.Meta["sql.query"])
 	})
 
+	t.Run("SpanRules", func(t *testing.T) {
+		// Ensures that the span rules are applied before the obfuscator.
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.SpanRules = []*config.SpanRule{
+			{Action: "extract", Key: "db.statement", Target: "resource"},
+			{Action: "delete", Key: "db.statement"},
+			{Action: "set", Key: "team", Value: "storage", Conditions: []*config.Tag{{K: "db.system", V: "postgresql"}}},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewAgent(ctx, cfg)
+		defer cancel()
+
+		now := time.Now()
+		span := &pb.Span{
+			TraceID:  1,
+			SpanID:   1,
+			Resource: "query",
+			Type:     "sql",
+			Start:    now.Add(-time.Second).UnixNano(),
+			Duration: (500 * time.Millisecond).Nanoseconds(),
+			Meta: map[string]string{
+				"db.statement": "SELECT name FROM people WHERE age = 42",
+				"db.system":    "postgresql",
+			},
+		}
+		agnt.Process(&api.Payload{
+			Traces: pb.Traces{{span}},
+			Source: info.NewReceiverStats().GetTagStats(info.Tags{}),
+		})
+
+		assert := assert.New(t)
+		assert.Equal("SELECT name FROM people WHERE age = ?", span.Resource)
+		assert.NotContains(span.Meta, "db.statement")
+		assert.Equal("storage", span.Meta["team"])
+	})
+
 	t.Run("Blacklister", func(t *testing.T) {
 		cfg := config.New()
 		cfg.Endpoints[0].APIKey = "test"
@@ -0,0 +1,287 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+	"github.com/DataDog/datadog-agent/pkg/trace/writer"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// tailDecisionTick is the interval at which the decision windows of the buffered traces are checked
+const tailDecisionTick = time.Second
+
+// tailTrace is a trace buffered by the tail sampler.
+type tailTrace struct {
+	id      uint64
+	spans   pb.Trace
+	size    int64
+	arrival time.Time
+}
+
+// tailDecision is the time a decision was taken on a trace, the decision is applied to
+// its late spans during a decision window.
+type tailDecision struct {
+	id uint64
+	at time.Time
+}
+
+// TailSampler buffers the spans of the traces during a decision window, then keeps the
+// complete traces matching one of its policies and sends them to the trace writer.
+//
+// The size of the buffered spans is capped, and so is the memory used by the agent when
+// apm_config.max_memory is set: past these limits, the decisions are taken early on the
+// oldest traces.
+type TailSampler struct {
+	out          chan *writer.SampledSpans
+	decisionWait time.Duration
+	maxSize      int64
+	maxMemory    float64
+	memInterval  time.Duration
+	policies     []*tailSamplingPolicy
+
+	mu        sync.Mutex
+	traces    map[uint64]*tailTrace
+	queue     []*tailTrace // buffered traces, in arrival order
+	size      int64
+	decisions map[uint64]bool
+	decided   []tailDecision // recent decisions, in the order they were taken
+	stopped   bool           // set once the decisions on all the buffered traces are taken at exit
+
+	exit   chan struct{}
+	exitWG sync.WaitGroup
+
+	now      func() time.Time
+	memAlloc func() uint64
+}
+
+// NewTailSampler returns a tail sampler sending the traces it keeps to out.
+func NewTailSampler(conf *config.AgentConfig, out chan *writer.SampledSpans) *TailSampler {
+	policies := make([]*tailSamplingPolicy, 0, len(conf.TailSampling.Policies))
+	for _, cfg := range conf.TailSampling.Policies {
+		policy, err := newTailSamplingPolicy(cfg)
+		if err != nil {
+			log.Errorf("Invalid tail sampling policy %q, ignoring it: %v", cfg.Name, err)
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	if len(policies) == 0 {
+		log.Warn("Tail sampling is enabled without any valid policy, only the traces with a user-kept sampling priority are kept")
+	}
+	return &TailSampler{
+		out:          out,
+		decisionWait: conf.TailSampling.DecisionWait,
+		maxSize:      conf.TailSampling.MaxMemory,
+		maxMemory:    conf.MaxMemory,
+		memInterval:  conf.WatchdogInterval,
+		policies:     policies,
+		traces:       make(map[uint64]*tailTrace),
+		decisions:    make(map[uint64]bool),
+		exit:         make(chan struct{}),
+		now:          time.Now,
+		memAlloc:     func() uint64 { return watchdog.Mem().Alloc },
+	}
+}
+
+// Start starts the loop taking the decisions on the traces whose decision window is over.
+func (s *TailSampler) Start() {
+	s.exitWG.Add(1)
+	go func() {
+		defer watchdog.LogOnPanic()
+		defer s.exitWG.Done()
+		s.run()
+	}()
+}
+
+func (s *TailSampler) run() {
+	decisionTicker := time.NewTicker(tailDecisionTick)
+	defer decisionTicker.Stop()
+	memTicker := time.NewTicker(s.memInterval)
+	defer memTicker.Stop()
+	for {
+		select {
+		case <-decisionTicker.C:
+			s.decideExpired()
+		case <-memTicker.C:
+			s.checkMemory()
+		case <-s.exit:
+			log.Info("Exiting tail sampler, taking the decisions on the buffered traces")
+			s.decideAll()
+			return
+		}
+	}
+}
+
+// Stop stops the tail sampler, after taking the decisions on all the buffered traces.
+func (s *TailSampler) Stop() {
+	close(s.exit)
+	s.exitWG.Wait()
+}
+
+// Add buffers the spans of a trace chunk until the decision on their trace is taken.
+// The spans of the traces already decided follow their decision. The spans added once
+// the tail sampler is stopped are dropped, as the trace writer may be stopped as well.
+func (s *TailSampler) Add(t pb.Trace) {
+	if len(t) == 0 {
+		return
+	}
+	id := t[0].TraceID
+	size := int64(t.Msgsize())
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		metrics.Count("datadog.trace_agent.tail_sampling.dropped_after_stop", int64(len(t)), nil, 1)
+		return
+	}
+	if keep, ok := s.decisions[id]; ok {
+		s.mu.Unlock()
+		metrics.Count("datadog.trace_agent.tail_sampling.late_spans", int64(len(t)), nil, 1)
+		if keep {
+			s.send([]pb.Trace{t})
+		}
+		return
+	}
+	tt, ok := s.traces[id]
+	if !ok {
+		tt = &tailTrace{id: id, arrival: s.now()}
+		s.traces[id] = tt
+		s.queue = append(s.queue, tt)
+	}
+	tt.spans = append(tt.spans, t...)
+	tt.size += size
+	s.size += size
+
+	var kept []pb.Trace
+	early := 0
+	for ; s.size > s.maxSize && len(s.queue) > 0; early++ {
+		kept = s.decideOldestLocked(1, kept)
+	}
+	s.mu.Unlock()
+
+	if early > 0 {
+		metrics.Count("datadog.trace_agent.tail_sampling.early_decisions", int64(early), []string{"reason:max_memory"}, 1)
+	}
+	s.send(kept)
+}
+
+// decideExpired takes the decisions on the traces whose decision window is over.
+func (s *TailSampler) decideExpired() {
+	now := s.now()
+	s.mu.Lock()
+	n := 0
+	for n < len(s.queue) && now.Sub(s.queue[n].arrival) >= s.decisionWait {
+		n++
+	}
+	kept := s.decideOldestLocked(n, nil)
+	// forget the decisions whose window is over
+	i := 0
+	for i < len(s.decided) && now.Sub(s.decided[i].at) >= s.decisionWait {
+		delete(s.decisions, s.decided[i].id)
+		i++
+	}
+	s.decided = s.decided[i:]
+	metrics.Gauge("datadog.trace_agent.tail_sampling.buffered_traces", float64(len(s.queue)), nil, 1)
+	metrics.Gauge("datadog.trace_agent.tail_sampling.buffered_bytes", float64(s.size), nil, 1)
+	s.mu.Unlock()
+
+	s.send(kept)
+}
+
+// checkMemory takes the decisions on the oldest half of the buffered traces when the
+// agent uses more memory than allowed.
+func (s *TailSampler) checkMemory() {
+	if s.maxMemory <= 0 {
+		return
+	}
+	alloc := float64(s.memAlloc())
+	if alloc <= s.maxMemory {
+		return
+	}
+	s.mu.Lock()
+	n := (len(s.queue) + 1) / 2
+	kept := s.decideOldestLocked(n, nil)
+	s.mu.Unlock()
+
+	log.Warnf("Memory threshold exceeded (apm_config.max_memory: %.0f bytes): %.0f, taking the decisions on %d buffered traces early", s.maxMemory, alloc, n)
+	metrics.Count("datadog.trace_agent.tail_sampling.early_decisions", int64(n), []string{"reason:watchdog"}, 1)
+	s.send(kept)
+}
+
+// decideAll takes the decisions on all the buffered traces when the tail sampler exits,
+// the spans added afterwards are dropped.
+func (s *TailSampler) decideAll() {
+	s.mu.Lock()
+	kept := s.decideOldestLocked(len(s.queue), nil)
+	s.stopped = true
+	s.mu.Unlock()
+	s.send(kept)
+}
+
+// decideOldestLocked takes the decisions on the n oldest buffered traces and appends
+// the ones which are kept to kept. It must be called with the lock held.
+func (s *TailSampler) decideOldestLocked(n int, kept []pb.Trace) []pb.Trace {
+	if n > len(s.queue) {
+		n = len(s.queue)
+	}
+	now := s.now()
+	for _, tt := range s.queue[:n] {
+		keep, policy := s.sample(tt.spans, now)
+		delete(s.traces, tt.id)
+		s.size -= tt.size
+		s.decisions[tt.id] = keep
+		s.decided = append(s.decided, tailDecision{id: tt.id, at: now})
+		if keep {
+			kept = append(kept, tt.spans)
+			metrics.Count("datadog.trace_agent.tail_sampling.traces", 1, []string{"decision:keep", "policy:" + policy}, 1)
+		} else {
+			metrics.Count("datadog.trace_agent.tail_sampling.traces", 1, []string{"decision:drop"}, 1)
+		}
+	}
+	s.queue = s.queue[n:]
+	return kept
+}
+
+// sample returns whether a trace is kept, along with the name of the policy keeping it.
+// The traces with a user-kept sampling priority are always kept.
+func (s *TailSampler) sample(t pb.Trace, now time.Time) (bool, string) {
+	root := traceutil.GetRoot(t)
+	if priority, ok := sampler.GetSamplingPriority(root); ok && priority >= sampler.PriorityUserKeep {
+		return true, "user_keep"
+	}
+	for _, p := range s.policies {
+		if p.sample(t, root, now) {
+			return true, p.name
+		}
+	}
+	return false, ""
+}
+
+// send sends the traces to the trace writer.
+func (s *TailSampler) send(traces []pb.Trace) {
+	ss := new(writer.SampledSpans)
+	for _, t := range traces {
+		ss.Traces = append(ss.Traces, traceutil.APITrace(t))
+		ss.Size += t.Msgsize()
+		ss.SpanCount += int64(len(t))
+		if ss.Size > writer.MaxPayloadSize {
+			s.out <- ss
+			ss = new(writer.SampledSpans)
+		}
+	}
+	if ss.Size > 0 {
+		s.out <- ss
+	}
+}
@@ -0,0 +1,291 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/writer"
+)
+
+// newTestTailSampler returns a tail sampler using the policies and a clock set with
+// the returned function.
+func newTestTailSampler(policies ...*config.TailSamplingPolicy) (*TailSampler, chan *writer.SampledSpans, func(time.Time)) {
+	conf := config.New()
+	conf.TailSampling.Enabled = true
+	conf.TailSampling.Policies = policies
+	out := make(chan *writer.SampledSpans, 100)
+	s := NewTailSampler(conf, out)
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+	return s, out, func(t time.Time) { now = t }
+}
+
+func tailSpan(traceID, spanID, parentID uint64, start, duration int64) *pb.Span {
+	return &pb.Span{
+		Service:  "web",
+		Name:     "http.request",
+		TraceID:  traceID,
+		SpanID:   spanID,
+		ParentID: parentID,
+		Start:    start,
+		Duration: duration,
+		Meta:     map[string]string{},
+		Metrics:  map[string]float64{},
+	}
+}
+
+// receivedTraces returns the traces sent by the tail sampler.
+func receivedTraces(out chan *writer.SampledSpans) []*pb.APITrace {
+	var traces []*pb.APITrace
+	for {
+		select {
+		case ss := <-out:
+			traces = append(traces, ss.Traces...)
+		default:
+			return traces
+		}
+	}
+}
+
+func TestTailSamplingPolicies(t *testing.T) {
+	ms := int64(time.Millisecond)
+	now := time.Unix(1000, 0)
+
+	for name, tt := range map[string]struct {
+		cfg   config.TailSamplingPolicy
+		trace pb.Trace
+		keep  bool
+	}{
+		"latency-kept": {
+			cfg:   config.TailSamplingPolicy{Type: "latency", ThresholdMs: 100},
+			trace: pb.Trace{tailSpan(1, 1, 0, 0, 50*ms), tailSpan(1, 2, 1, 80*ms, 30*ms)},
+			keep:  true,
+		},
+		"latency-dropped": {
+			cfg:   config.TailSamplingPolicy{Type: "latency", ThresholdMs: 100},
+			trace: pb.Trace{tailSpan(1, 1, 0, 0, 99*ms)},
+		},
+		"error-kept": {
+			cfg:   config.TailSamplingPolicy{Type: "error"},
+			trace: pb.Trace{tailSpan(1, 1, 0, 0, 1), {TraceID: 1, SpanID: 2, ParentID: 1, Error: 1}},
+			keep:  true,
+		},
+		"error-dropped": {
+			cfg:   config.TailSamplingPolicy{Type: "error"},
+			trace: pb.Trace{tailSpan(1, 1, 0, 0, 1)},
+		},
+		"attribute-value": {
+			cfg:   config.TailSamplingPolicy{Type: "attribute", Key: "customer.tier", Values: []string{"gold", "platinum"}},
+			trace: pb.Trace{tailSpan(1, 1, 0, 0, 1), {TraceID: 1, SpanID: 2, Meta: map[string]string{"customer.tier": "gold"}}},
+			keep:  true,
+		},
+		"attribute-other-value": {
+			cfg:   config.TailSamplingPolicy{Type: "attribute", Key: "customer.tier", Values: []string{"gold"}},
+			trace: pb.Trace{{TraceID: 1, SpanID: 1, Meta: map[string]string{"customer.tier": "silver"}}},
+		},
+		"attribute-set": {
+			cfg:   config.TailSamplingPolicy{Type: "attribute", Key: "debug"},
+			trace: pb.Trace{{TraceID: 1, SpanID: 1, Meta: map[string]string{"debug": ""}}},
+			keep:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, err := newTailSamplingPolicy(&tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.cfg.Type, p.name)
+			assert.Equal(t, tt.keep, p.sample(tt.trace, tt.trace[0], now))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, cfg := range []config.TailSamplingPolicy{
+			{Type: "latency"},
+			{Type: "attribute"},
+			{Type: "rate_limit"},
+			{Type: "unknown"},
+		} {
+			_, err := newTailSamplingPolicy(&cfg)
+			assert.Error(t, err, cfg.Type)
+		}
+	})
+
+	t.Run("rate_limit", func(t *testing.T) {
+		p, err := newTailSamplingPolicy(&config.TailSamplingPolicy{Name: "two-per-second", Type: "rate_limit", TracesPerSecond: 2})
+		require.NoError(t, err)
+		assert.Equal(t, "two-per-second", p.name)
+
+		web := tailSpan(1, 1, 0, 0, 1)
+		db := tailSpan(2, 2, 0, 0, 1)
+		db.Service = "db"
+		assert.True(t, p.sample(pb.Trace{web}, web, now))
+		assert.True(t, p.sample(pb.Trace{web}, web, now))
+		assert.False(t, p.sample(pb.Trace{web}, web, now))
+		// the services are limited separately
+		assert.True(t, p.sample(pb.Trace{db}, db, now))
+		// the tokens are refilled over time
+		now = now.Add(500 * time.Millisecond)
+		assert.True(t, p.sample(pb.Trace{web}, web, now))
+		assert.False(t, p.sample(pb.Trace{web}, web, now))
+	})
+}
+
+func TestTailSamplerDecisionWait(t *testing.T) {
+	s, out, setNow := newTestTailSampler(&config.TailSamplingPolicy{Type: "error"})
+	start := s.now()
+
+	// the spans of a trace arrive in several chunks, the error comes last
+	s.Add(pb.Trace{tailSpan(1, 1, 0, 0, 10)})
+	s.Add(pb.Trace{tailSpan(2, 1, 0, 0, 10)})
+	setNow(start.Add(5 * time.Second))
+	errSpan := tailSpan(1, 2, 1, 0, 5)
+	errSpan.Error = 1
+	s.Add(pb.Trace{errSpan})
+
+	s.decideExpired()
+	assert.Len(t, receivedTraces(out), 0)
+
+	setNow(start.Add(10 * time.Second))
+	s.decideExpired()
+	traces := receivedTraces(out)
+	require.Len(t, traces, 1)
+	assert.Equal(t, uint64(1), traces[0].TraceID)
+	assert.Len(t, traces[0].Spans, 2)
+	assert.Len(t, s.queue, 0)
+	assert.Len(t, s.traces, 0)
+	assert.Equal(t, int64(0), s.size)
+
+	// the late spans follow the decision taken on their trace
+	s.Add(pb.Trace{tailSpan(1, 3, 1, 0, 1)})
+	s.Add(pb.Trace{tailSpan(2, 3, 1, 0, 1)})
+	traces = receivedTraces(out)
+	require.Len(t, traces, 1)
+	assert.Equal(t, uint64(1), traces[0].TraceID)
+	assert.Len(t, s.queue, 0)
+
+	// then the decisions are forgotten
+	setNow(start.Add(20 * time.Second))
+	s.decideExpired()
+	assert.Len(t, s.decisions, 0)
+	assert.Len(t, s.decided, 0)
+	s.Add(pb.Trace{tailSpan(2, 4, 1, 0, 1)})
+	assert.Len(t, s.queue, 1)
+}
+
+func TestTailSamplerUserKeep(t *testing.T) {
+	s, out, _ := newTestTailSampler()
+	kept := tailSpan(1, 1, 0, 0, 1)
+	sampler.SetSamplingPriority(kept, sampler.PriorityUserKeep)
+	s.Add(pb.Trace{kept})
+	s.Add(pb.Trace{tailSpan(2, 1, 0, 0, 1)})
+
+	s.decideAll()
+	traces := receivedTraces(out)
+	require.Len(t, traces, 1)
+	assert.Equal(t, uint64(1), traces[0].TraceID)
+}
+
+func TestTailSamplerMaxMemory(t *testing.T) {
+	s, out, _ := newTestTailSampler(&config.TailSamplingPolicy{Type: "latency", ThresholdMs: 1})
+	s.maxSize = int64(pb.Trace{tailSpan(1, 1, 0, 0, 0)}.Msgsize()) * 2
+
+	// the decisions on the oldest traces are taken early once the buffer is full
+	for i := uint64(1); i <= 3; i++ {
+		s.Add(pb.Trace{tailSpan(i, 1, 0, 0, int64(time.Second))})
+	}
+	traces := receivedTraces(out)
+	require.Len(t, traces, 1)
+	assert.Equal(t, uint64(1), traces[0].TraceID)
+	assert.Len(t, s.queue, 2)
+	assert.LessOrEqual(t, s.size, s.maxSize)
+
+	var size int64
+	for _, tt := range s.queue {
+		size += tt.size
+	}
+	assert.Equal(t, size, s.size)
+}
+
+func TestTailSamplerWatchdog(t *testing.T) {
+	s, out, _ := newTestTailSampler(&config.TailSamplingPolicy{Type: "latency", ThresholdMs: 1})
+	s.maxMemory = 1000
+	alloc := uint64(500)
+	s.memAlloc = func() uint64 { return alloc }
+	for i := uint64(1); i <= 4; i++ {
+		s.Add(pb.Trace{tailSpan(i, 1, 0, 0, int64(time.Second))})
+	}
+
+	s.checkMemory()
+	assert.Len(t, receivedTraces(out), 0)
+
+	// the decisions on the oldest half of the traces are taken early past the limit
+	alloc = 2000
+	s.checkMemory()
+	traces := receivedTraces(out)
+	require.Len(t, traces, 2)
+	assert.Equal(t, uint64(1), traces[0].TraceID)
+	assert.Equal(t, uint64(2), traces[1].TraceID)
+	assert.Len(t, s.queue, 2)
+}
+
+func TestTailSamplerStop(t *testing.T) {
+	s, out, _ := newTestTailSampler(&config.TailSamplingPolicy{Type: "error"})
+	s.Start()
+	span := tailSpan(1, 1, 0, 0, 1)
+	span.Error = 1
+	s.Add(pb.Trace{span})
+	s.Stop()
+
+	traces := receivedTraces(out)
+	require.Len(t, traces, 1)
+	assert.Equal(t, uint64(1), traces[0].TraceID)
+
+	// the spans added after the stop are dropped, whether their trace was decided or not
+	s.Add(pb.Trace{tailSpan(1, 2, 1, 0, 1)})
+	s.Add(pb.Trace{tailSpan(2, 3, 0, 0, 1)})
+	assert.Empty(t, s.queue)
+	assert.Empty(t, receivedTraces(out))
+}
+
+func TestProcessTailSampling(t *testing.T) {
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.TailSampling.Enabled = true
+	cfg.TailSampling.Policies = []*config.TailSamplingPolicy{{Type: "error"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	agnt := NewAgent(ctx, cfg)
+	require.NotNil(t, agnt.TailSampler)
+
+	span := tailSpan(1, 1, 0, 0, 1)
+	span.Error = 1
+	sampler.SetSamplingPriority(span, sampler.PriorityAutoKeep)
+	agnt.Process(&api.Payload{
+		Traces: pb.Traces{{span}},
+		Source: info.NewReceiverStats().GetTagStats(info.Tags{}),
+	})
+	// the trace is buffered by the tail sampler instead of being sent right away
+	assert.Len(t, agnt.TraceWriter.In, 0)
+	assert.Len(t, agnt.TailSampler.queue, 1)
+	// the priority sampler still counts the traffic
+	assert.True(t, agnt.PrioritySampler.Sampler.Backend.GetTotalScore() > 0)
+
+	agnt.TailSampler.decideAll()
+	require.Len(t, agnt.TraceWriter.In, 1)
+	ss := <-agnt.TraceWriter.In
+	require.Len(t, ss.Traces, 1)
+	assert.Equal(t, uint64(1), ss.Traces[0].TraceID)
+}
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// tailSamplingPolicy is a policy of the tail sampler, deciding whether a complete
+// trace is kept.
+type tailSamplingPolicy struct {
+	name   string
+	sample func(t pb.Trace, root *pb.Span, now time.Time) bool
+}
+
+// newTailSamplingPolicy returns the policy described by cfg.
+func newTailSamplingPolicy(cfg *config.TailSamplingPolicy) (*tailSamplingPolicy, error) {
+	p := &tailSamplingPolicy{name: cfg.Name}
+	if p.name == "" {
+		p.name = cfg.Type
+	}
+	switch cfg.Type {
+	case "latency":
+		if cfg.ThresholdMs <= 0 {
+			return nil, errors.New("threshold_ms must be positive")
+		}
+		p.sample = latencyPolicy(int64(cfg.ThresholdMs * float64(time.Millisecond)))
+	case "error":
+		p.sample = errorPolicy
+	case "attribute":
+		if cfg.Key == "" {
+			return nil, errors.New("key must be set")
+		}
+		p.sample = attributePolicy(cfg.Key, cfg.Values)
+	case "rate_limit":
+		if cfg.TracesPerSecond <= 0 {
+			return nil, errors.New("traces_per_second must be positive")
+		}
+		p.sample = rateLimitPolicy(cfg.TracesPerSecond)
+	default:
+		return nil, fmt.Errorf("unknown type %q", cfg.Type)
+	}
+	return p, nil
+}
+
+// latencyPolicy keeps the traces lasting at least threshold nanoseconds, from the start
+// of their first span to the end of their last one.
+func latencyPolicy(threshold int64) func(pb.Trace, *pb.Span, time.Time) bool {
+	return func(t pb.Trace, _ *pb.Span, _ time.Time) bool {
+		start, end := int64(math.MaxInt64), int64(math.MinInt64)
+		for _, s := range t {
+			if s.Start < start {
+				start = s.Start
+			}
+			if s.Start+s.Duration > end {
+				end = s.Start + s.Duration
+			}
+		}
+		return end-start >= threshold
+	}
+}
+
+// errorPolicy keeps the traces having a span in error.
+func errorPolicy(t pb.Trace, _ *pb.Span, _ time.Time) bool {
+	for _, s := range t {
+		if s.Error != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// attributePolicy keeps the traces having a span whose key tag is one of values, or is
+// set when values is empty.
+func attributePolicy(key string, values []string) func(pb.Trace, *pb.Span, time.Time) bool {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return func(t pb.Trace, _ *pb.Span, _ time.Time) bool {
+		for _, s := range t {
+			v, ok := s.Meta[key]
+			if !ok {
+				continue
+			}
+			if len(set) == 0 {
+				return true
+			}
+			if _, ok := set[v]; ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// tokenBucket holds the traces a service can still keep.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimitPolicy keeps up to tps traces per second for each service of the root spans.
+// It is not safe for concurrent use, the tail sampler calls it with its lock held.
+func rateLimitPolicy(tps float64) func(pb.Trace, *pb.Span, time.Time) bool {
+	capacity := math.Max(1, tps)
+	buckets := make(map[string]*tokenBucket)
+	return func(_ pb.Trace, root *pb.Span, now time.Time) bool {
+		b, ok := buckets[root.Service]
+		if !ok {
+			b = &tokenBucket{tokens: capacity, last: now}
+			buckets[root.Service] = b
+		}
+		if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+			b.tokens = math.Min(capacity, b.tokens+elapsed*tps)
+			b.last = now
+		}
+		if b.tokens < 1 {
+			return false
+		}
+		b.tokens--
+		return true
+	}
+}
@@ -490,7 +490,11 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 		ClientComputedStats:    req.Header.Get(headerComputedStats) != "",
 		ClientDroppedP0s:       droppedTracesFromHeader(req.Header, ts),
 	}
+	r.sendPayload(payload)
+}
 
+// sendPayload sends a payload to the out channel, without blocking the caller.
+func (r *HTTPReceiver) sendPayload(payload *Payload) {
 	select {
 	case r.out <- payload:
 		// ok
@@ -721,6 +725,23 @@ func tracesFromSpans(spans []pb.Span) pb.Traces {
 	return traces
 }
 
+// groupByTrace groups spans into traces, following the order in which their traces
+// first appear.
+func groupByTrace(spans []*pb.Span) pb.Traces {
+	traces := pb.Traces{}
+	indexes := make(map[uint64]int)
+	for _, s := range spans {
+		i, ok := indexes[s.TraceID]
+		if !ok {
+			i = len(traces)
+			indexes[s.TraceID] = i
+			traces = append(traces, nil)
+		}
+		traces[i] = append(traces[i], s)
+	}
+	return traces
+}
+
 // getContainerTag returns container and orchestrator tags belonging to containerID. If containerID
 // is empty or no tags are found, an empty string is returned.
 func getContainerTags(containerID string) string {
@@ -75,4 +75,14 @@ var endpoints = []endpoint{
 		Pattern: "/profiling/v1/input",
 		Handler: func(r *HTTPReceiver) http.Handler { return r.profileProxyHandler() },
 	},
+	{
+		Pattern: "/api/v2/spans",
+		Handler: func(r *HTTPReceiver) http.Handler { return http.HandlerFunc(r.handleZipkinSpans) },
+		Hidden:  true,
+	},
+	{
+		Pattern: "/api/traces",
+		Handler: func(r *HTTPReceiver) http.Handler { return http.HandlerFunc(r.handleJaegerTraces) },
+		Hidden:  true,
+	},
 }
@@ -0,0 +1,403 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/otlp"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The types below are the structures of the Jaeger Thrift model used by the clients
+// reporting their spans over HTTP, see
+// https://github.com/jaegertracing/jaeger-idl/blob/master/thrift/jaeger.thrift
+
+// The types of the values of the Jaeger tags
+const (
+	jaegerTagString int32 = 0
+	jaegerTagDouble int32 = 1
+	jaegerTagBool   int32 = 2
+	jaegerTagLong   int32 = 3
+	jaegerTagBinary int32 = 4
+)
+
+const (
+	// jaegerRefChildOf is the type of the references to the parent spans
+	jaegerRefChildOf int32 = 0
+	// jaegerFlagDebug flags the spans which must be kept
+	jaegerFlagDebug int32 = 2
+)
+
+// jaegerBatch is the payload sent by the Jaeger clients.
+type jaegerBatch struct {
+	process *jaegerProcess
+	spans   []*jaegerSpan
+}
+
+// jaegerProcess is the process which produced the spans of a batch.
+type jaegerProcess struct {
+	serviceName string
+	tags        []*jaegerTag
+}
+
+// jaegerSpan is a Jaeger span, timestamps and durations are in microseconds.
+type jaegerSpan struct {
+	traceIDLow    int64
+	traceIDHigh   int64
+	spanID        int64
+	parentSpanID  int64
+	operationName string
+	references    []*jaegerSpanRef
+	flags         int32
+	startTime     int64
+	duration      int64
+	tags          []*jaegerTag
+	logs          []*jaegerLog
+}
+
+// jaegerTag is a tag of a span or process, whose value is set in the field of its type.
+type jaegerTag struct {
+	key     string
+	vType   int32
+	vStr    string
+	vDouble float64
+	vBool   bool
+	vLong   int64
+	vBinary []byte
+}
+
+// jaegerLog is an event which happened during a span.
+type jaegerLog struct {
+	timestamp int64
+	fields    []*jaegerTag
+}
+
+// jaegerSpanRef is a reference to another span.
+type jaegerSpanRef struct {
+	refType     int32
+	traceIDLow  int64
+	traceIDHigh int64
+	spanID      int64
+}
+
+func readJaegerBatch(r *thriftReader) (*jaegerBatch, error) {
+	b := &jaegerBatch{}
+	err := r.readStruct(func(id int16, typ byte) (err error) {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			b.process, err = readJaegerProcess(r)
+		case id == 2 && typ == thriftList:
+			err = r.readList(thriftStruct, func() error {
+				s, err := readJaegerSpan(r)
+				b.spans = append(b.spans, s)
+				return err
+			})
+		default:
+			err = r.skip(typ, 0)
+		}
+		return err
+	})
+	if err == nil && b.process == nil {
+		err = fmt.Errorf("the batch has no process")
+	}
+	return b, err
+}
+
+func readJaegerProcess(r *thriftReader) (*jaegerProcess, error) {
+	p := &jaegerProcess{}
+	err := r.readStruct(func(id int16, typ byte) (err error) {
+		switch {
+		case id == 1 && typ == thriftString:
+			p.serviceName, err = r.readString()
+		case id == 2 && typ == thriftList:
+			p.tags, err = readJaegerTags(r)
+		default:
+			err = r.skip(typ, 0)
+		}
+		return err
+	})
+	return p, err
+}
+
+func readJaegerSpan(r *thriftReader) (*jaegerSpan, error) {
+	s := &jaegerSpan{}
+	err := r.readStruct(func(id int16, typ byte) (err error) {
+		switch {
+		case id == 1 && typ == thriftI64:
+			s.traceIDLow, err = r.readI64()
+		case id == 2 && typ == thriftI64:
+			s.traceIDHigh, err = r.readI64()
+		case id == 3 && typ == thriftI64:
+			s.spanID, err = r.readI64()
+		case id == 4 && typ == thriftI64:
+			s.parentSpanID, err = r.readI64()
+		case id == 5 && typ == thriftString:
+			s.operationName, err = r.readString()
+		case id == 6 && typ == thriftList:
+			err = r.readList(thriftStruct, func() error {
+				ref, err := readJaegerSpanRef(r)
+				s.references = append(s.references, ref)
+				return err
+			})
+		case id == 7 && typ == thriftI32:
+			s.flags, err = r.readI32()
+		case id == 8 && typ == thriftI64:
+			s.startTime, err = r.readI64()
+		case id == 9 && typ == thriftI64:
+			s.duration, err = r.readI64()
+		case id == 10 && typ == thriftList:
+			s.tags, err = readJaegerTags(r)
+		case id == 11 && typ == thriftList:
+			err = r.readList(thriftStruct, func() error {
+				l, err := readJaegerLog(r)
+				s.logs = append(s.logs, l)
+				return err
+			})
+		default:
+			err = r.skip(typ, 0)
+		}
+		return err
+	})
+	return s, err
+}
+
+func readJaegerTags(r *thriftReader) ([]*jaegerTag, error) {
+	var tags []*jaegerTag
+	err := r.readList(thriftStruct, func() error {
+		t := &jaegerTag{}
+		tags = append(tags, t)
+		return r.readStruct(func(id int16, typ byte) (err error) {
+			switch {
+			case id == 1 && typ == thriftString:
+				t.key, err = r.readString()
+			case id == 2 && typ == thriftI32:
+				t.vType, err = r.readI32()
+			case id == 3 && typ == thriftString:
+				t.vStr, err = r.readString()
+			case id == 4 && typ == thriftDouble:
+				t.vDouble, err = r.readDouble()
+			case id == 5 && typ == thriftBool:
+				t.vBool, err = r.readBool()
+			case id == 6 && typ == thriftI64:
+				t.vLong, err = r.readI64()
+			case id == 7 && typ == thriftString:
+				t.vBinary, err = r.readBinary()
+			default:
+				err = r.skip(typ, 0)
+			}
+			return err
+		})
+	})
+	return tags, err
+}
+
+func readJaegerLog(r *thriftReader) (*jaegerLog, error) {
+	l := &jaegerLog{}
+	err := r.readStruct(func(id int16, typ byte) (err error) {
+		switch {
+		case id == 1 && typ == thriftI64:
+			l.timestamp, err = r.readI64()
+		case id == 2 && typ == thriftList:
+			l.fields, err = readJaegerTags(r)
+		default:
+			err = r.skip(typ, 0)
+		}
+		return err
+	})
+	return l, err
+}
+
+func readJaegerSpanRef(r *thriftReader) (*jaegerSpanRef, error) {
+	ref := &jaegerSpanRef{}
+	err := r.readStruct(func(id int16, typ byte) (err error) {
+		switch {
+		case id == 1 && typ == thriftI32:
+			ref.refType, err = r.readI32()
+		case id == 2 && typ == thriftI64:
+			ref.traceIDLow, err = r.readI64()
+		case id == 3 && typ == thriftI64:
+			ref.traceIDHigh, err = r.readI64()
+		case id == 4 && typ == thriftI64:
+			ref.spanID, err = r.readI64()
+		default:
+			err = r.skip(typ, 0)
+		}
+		return err
+	})
+	return ref, err
+}
+
+// String returns the value of a tag as a string.
+func (t *jaegerTag) String() string {
+	switch t.vType {
+	case jaegerTagDouble:
+		return strconv.FormatFloat(t.vDouble, 'f', -1, 64)
+	case jaegerTagBool:
+		return strconv.FormatBool(t.vBool)
+	case jaegerTagLong:
+		return strconv.FormatInt(t.vLong, 10)
+	case jaegerTagBinary:
+		return base64.StdEncoding.EncodeToString(t.vBinary)
+	default:
+		return t.vStr
+	}
+}
+
+// handleJaegerTraces handles the Jaeger batches encoded with Thrift, as sent to the
+// HTTP endpoint of the Jaeger collectors.
+func (r *HTTPReceiver) handleJaegerTraces(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch mediaType := getMediaType(req); mediaType {
+	case "application/x-thrift", "application/vnd.apache.thrift.binary":
+	default:
+		http.Error(w, fmt.Sprintf("unsupported media type: %q", mediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+	body := NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		httpDecodingError(err, []string{"handler:jaeger", "v:thrift"}, w)
+		return
+	}
+	batch, err := readJaegerBatch(&thriftReader{data: data})
+	if err != nil {
+		httpDecodingError(err, []string{"handler:jaeger", "v:thrift"}, w)
+		log.Errorf("Cannot decode Jaeger batch: %v", err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+
+	processMeta := make(map[string]string, len(batch.process.tags))
+	for _, t := range batch.process.tags {
+		processMeta[t.key] = t.String()
+	}
+	tags := info.Tags{EndpointVersion: "jaeger_thrift"}
+	if version := processMeta["jaeger.version"]; version != "" {
+		// the version of the Jaeger clients is of the form Go-2.30.0
+		tags.TracerVersion = version
+		tags.Lang = strings.ToLower(strings.SplitN(version, "-", 2)[0])
+	}
+	ts := r.Stats.GetTagStats(tags)
+
+	spans := make([]*pb.Span, 0, len(batch.spans))
+	for _, s := range batch.spans {
+		spans = append(spans, convertJaegerSpan(batch.process.serviceName, processMeta, s))
+	}
+	traces := groupByTrace(spans)
+	atomic.AddInt64(&ts.TracesReceived, int64(len(traces)))
+	atomic.AddInt64(&ts.TracesBytes, body.Count)
+	atomic.AddInt64(&ts.PayloadAccepted, 1)
+	if len(traces) == 0 {
+		return
+	}
+	payload := &Payload{Source: ts, Traces: traces}
+	if containerID := req.Header.Get(headerContainerID); containerID != "" {
+		payload.ContainerTags = getContainerTags(containerID)
+	}
+	r.sendPayload(payload)
+}
+
+var jaegerSpanKinds = map[string]otlp.SpanKind{
+	"client":   otlp.SpanKindClient,
+	"server":   otlp.SpanKindServer,
+	"producer": otlp.SpanKindProducer,
+	"consumer": otlp.SpanKindConsumer,
+}
+
+// convertJaegerSpan converts a Jaeger span into a Datadog span. The tags of the process
+// and the span which are not numbers are added to its meta, its `error` tag flags it in
+// error and its error logs are reported in the error tags.
+func convertJaegerSpan(service string, processMeta map[string]string, in *jaegerSpan) *pb.Span {
+	span := &pb.Span{
+		Service:  service,
+		Resource: in.operationName,
+		TraceID:  uint64(in.traceIDLow),
+		SpanID:   uint64(in.spanID),
+		ParentID: uint64(in.parentSpanID),
+		Start:    in.startTime * 1000,
+		Duration: in.duration * 1000,
+		Meta:     make(map[string]string, len(processMeta)+len(in.tags)+2),
+		Metrics:  make(map[string]float64),
+	}
+	if span.ParentID == 0 {
+		for _, ref := range in.references {
+			if ref.refType == jaegerRefChildOf && ref.traceIDLow == in.traceIDLow {
+				span.ParentID = uint64(ref.spanID)
+				break
+			}
+		}
+	}
+	for k, v := range processMeta {
+		span.Meta[k] = v
+	}
+	for _, t := range in.tags {
+		switch {
+		case t.key == "error" && t.vType == jaegerTagBool:
+			if t.vBool {
+				span.Error = 1
+			}
+		case t.key == "http.status_code":
+			// the Datadog tracers report the HTTP status codes as tags
+			span.Meta[t.key] = t.String()
+		case t.vType == jaegerTagLong:
+			span.Metrics[t.key] = float64(t.vLong)
+		case t.vType == jaegerTagDouble:
+			span.Metrics[t.key] = t.vDouble
+		default:
+			span.Meta[t.key] = t.String()
+		}
+	}
+	if in.traceIDHigh != 0 {
+		span.Meta["jaeger.trace_id"] = fmt.Sprintf("%016x%016x", uint64(in.traceIDHigh), uint64(in.traceIDLow))
+	}
+
+	kind, ok := jaegerSpanKinds[span.Meta["span.kind"]]
+	if !ok {
+		kind = otlp.SpanKindInternal
+		span.Meta["span.kind"] = spanKindName(kind)
+	}
+	span.Name = "jaeger." + spanKindName(kind)
+	span.Type = spanType(kind, span.Meta)
+
+	for _, l := range in.logs {
+		fields := make(map[string]string, len(l.fields))
+		for _, f := range l.fields {
+			fields[f.key] = f.String()
+		}
+		if fields["event"] != "error" {
+			continue
+		}
+		span.Error = 1
+		if v, ok := fields["error.kind"]; ok {
+			span.Meta["error.type"] = v
+		}
+		if v, ok := fields["message"]; ok {
+			span.Meta["error.msg"] = v
+		} else if v, ok := fields["error.object"]; ok {
+			span.Meta["error.msg"] = v
+		}
+		if v, ok := fields["stack"]; ok {
+			span.Meta["error.stack"] = v
+		}
+	}
+	if in.flags&jaegerFlagDebug != 0 {
+		sampler.SetSamplingPriority(span, sampler.PriorityUserKeep)
+	}
+	return span
+}
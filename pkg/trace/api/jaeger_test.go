@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+)
+
+// thriftWriter encodes values with the Thrift binary protocol
+type thriftWriter struct {
+	bytes.Buffer
+}
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.WriteByte(typ)
+	binary.Write(w, binary.BigEndian, id)
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	binary.Write(w, binary.BigEndian, v)
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	binary.Write(w, binary.BigEndian, v)
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(thriftString, id)
+	binary.Write(w, binary.BigEndian, int32(len(v)))
+	w.WriteString(v)
+}
+
+func (w *thriftWriter) list(id int16, elemType byte, size int) {
+	w.field(thriftList, id)
+	w.WriteByte(elemType)
+	binary.Write(w, binary.BigEndian, int32(size))
+}
+
+func (w *thriftWriter) stop() {
+	w.WriteByte(thriftStop)
+}
+
+// tags writes a list of tags, whose values are strings, int64, float64 or bool
+func (w *thriftWriter) tags(id int16, tags [][2]interface{}) {
+	w.list(id, thriftStruct, len(tags))
+	for _, t := range tags {
+		w.str(1, t[0].(string))
+		switch v := t[1].(type) {
+		case string:
+			w.i32(2, jaegerTagString)
+			w.str(3, v)
+		case float64:
+			w.i32(2, jaegerTagDouble)
+			w.field(thriftDouble, 4)
+			binary.Write(w, binary.BigEndian, math.Float64bits(v))
+		case bool:
+			w.i32(2, jaegerTagBool)
+			w.field(thriftBool, 5)
+			if v {
+				w.WriteByte(1)
+			} else {
+				w.WriteByte(0)
+			}
+		case int64:
+			w.i32(2, jaegerTagLong)
+			w.i64(6, v)
+		}
+		w.stop()
+	}
+}
+
+// testJaegerBatch returns a batch holding a server span and its client child span
+func testJaegerBatch() []byte {
+	w := &thriftWriter{}
+	// process
+	w.field(thriftStruct, 1)
+	w.str(1, "checkout")
+	w.tags(2, [][2]interface{}{{"jaeger.version", "Go-2.30.0"}, {"hostname", "web-1"}})
+	w.stop()
+	// spans
+	w.list(2, thriftStruct, 2)
+	// server span
+	w.i64(1, 2)
+	w.i64(2, 1)
+	w.i64(3, 3)
+	w.i64(4, 0)
+	w.str(5, "HTTP GET /cart")
+	w.i32(7, 3)
+	w.i64(8, 100)
+	w.i64(9, 20)
+	w.tags(10, [][2]interface{}{
+		{"span.kind", "server"},
+		{"http.method", "GET"},
+		{"http.status_code", int64(500)},
+		{"error", true},
+		{"retries", int64(2)},
+		{"ratio", 0.5},
+	})
+	w.list(11, thriftStruct, 1)
+	w.i64(1, 110)
+	w.tags(2, [][2]interface{}{
+		{"event", "error"},
+		{"error.kind", "ValueError"},
+		{"message", "invalid cart"},
+		{"stack", "main.go:12"},
+	})
+	w.stop()
+	// unknown fields are skipped
+	w.field(thriftMap, 99)
+	w.WriteByte(thriftString)
+	w.WriteByte(thriftI32)
+	binary.Write(w, binary.BigEndian, int32(0))
+	w.stop()
+	// client span, whose parent is set in its references
+	w.i64(1, 2)
+	w.i64(2, 1)
+	w.i64(3, 4)
+	w.str(5, "SELECT")
+	w.list(6, thriftStruct, 1)
+	w.i32(1, jaegerRefChildOf)
+	w.i64(2, 2)
+	w.i64(3, 1)
+	w.i64(4, 3)
+	w.stop()
+	w.i64(8, 105)
+	w.i64(9, 10)
+	w.tags(10, [][2]interface{}{{"span.kind", "client"}, {"db.system", "redis"}})
+	w.stop()
+	w.stop()
+	return w.Bytes()
+}
+
+func postJaeger(r *HTTPReceiver, contentType string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.handleJaegerTraces(w, req)
+	return w
+}
+
+func TestJaegerThrift(t *testing.T) {
+	r := newTestReceiverFromConfig(config.New())
+	w := postJaeger(r, "application/x-thrift", testJaegerBatch())
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	require.Len(t, r.out, 1)
+	payload := <-r.out
+	assert.Equal(t, "jaeger_thrift", payload.Source.EndpointVersion)
+	assert.Equal(t, "go", payload.Source.Lang)
+	assert.Equal(t, "Go-2.30.0", payload.Source.TracerVersion)
+	require.Len(t, payload.Traces, 1)
+	require.Len(t, payload.Traces[0], 2)
+
+	server, client := payload.Traces[0][0], payload.Traces[0][1]
+	assert.Equal(t, &pb.Span{
+		Service:  "checkout",
+		Name:     "jaeger.server",
+		Resource: "HTTP GET /cart",
+		TraceID:  2,
+		SpanID:   3,
+		Start:    100000,
+		Duration: 20000,
+		Error:    1,
+		Type:     "web",
+		Meta: map[string]string{
+			"jaeger.version":   "Go-2.30.0",
+			"hostname":         "web-1",
+			"span.kind":        "server",
+			"http.method":      "GET",
+			"http.status_code": "500",
+			"jaeger.trace_id":  "00000000000000010000000000000002",
+			"error.type":       "ValueError",
+			"error.msg":        "invalid cart",
+			"error.stack":      "main.go:12",
+		},
+		Metrics: map[string]float64{
+			"retries":                   2,
+			"ratio":                     0.5,
+			sampler.KeySamplingPriority: float64(sampler.PriorityUserKeep),
+		},
+	}, server)
+
+	assert.Equal(t, uint64(3), client.ParentID)
+	assert.Equal(t, "jaeger.client", client.Name)
+	assert.Equal(t, "cache", client.Type)
+	assert.Equal(t, int32(0), client.Error)
+	assert.NotContains(t, client.Metrics, sampler.KeySamplingPriority)
+}
+
+func TestJaegerInvalidPayloads(t *testing.T) {
+	r := newTestReceiverFromConfig(config.New())
+
+	w := postJaeger(r, "application/json", []byte("{}"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	batch := testJaegerBatch()
+	w = postJaeger(r, "application/x-thrift", batch[:len(batch)-10])
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// the batches must have a process
+	w = postJaeger(r, "application/vnd.apache.thrift.binary", []byte{thriftStop})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// the size of the lists is checked before reading them
+	tw := &thriftWriter{}
+	tw.list(2, thriftStruct, math.MaxInt32)
+	w = postJaeger(r, "application/x-thrift", tw.Bytes())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, r.out, 0)
+}
@@ -0,0 +1,455 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	stdlog "log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/otlp"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/logutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// otlpHTTPPath is the path of the OTLP/HTTP traces endpoint
+	otlpHTTPPath = "/v1/traces"
+	// otlpDefaultScopeName names the spans of the instrumentation scopes without name
+	otlpDefaultScopeName = "opentelemetry"
+)
+
+// OTLPReceiver receives the traces sent with the OpenTelemetry protocol over gRPC
+// and HTTP, and converts them into payloads processed as the ones sent by the
+// Datadog tracers.
+type OTLPReceiver struct {
+	out   chan *Payload
+	conf  *config.AgentConfig
+	stats *info.ReceiverStats
+
+	httpServer *http.Server
+	grpcServer *grpc.Server
+
+	wg sync.WaitGroup // waits for all payloads to be sent
+}
+
+// NewOTLPReceiver returns a new OTLPReceiver sending the payloads it receives to out.
+// Its stats are reported with the ones of the HTTP receiver which owns `stats`.
+func NewOTLPReceiver(out chan *Payload, conf *config.AgentConfig, stats *info.ReceiverStats) *OTLPReceiver {
+	return &OTLPReceiver{out: out, conf: conf, stats: stats}
+}
+
+// Start starts the OTLP/HTTP and OTLP/gRPC servers whose port is set.
+func (o *OTLPReceiver) Start() {
+	cfg := o.conf.OTLPReceiver
+	if cfg == nil {
+		return
+	}
+	if cfg.HTTPPort != 0 {
+		addr := fmt.Sprintf("%s:%d", cfg.BindHost, cfg.HTTPPort)
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			killProcess("Error creating OTLP/HTTP listener: %v", err)
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc(otlpHTTPPath, o.handleHTTPTraces)
+		o.httpServer = &http.Server{
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+			ErrorLog:     stdlog.New(logutil.NewThrottled(5, 10*time.Second), "http.Server: ", 0),
+			Handler:      mux,
+		}
+		go func() {
+			defer watchdog.LogOnPanic()
+			o.httpServer.Serve(ln)
+		}()
+		log.Infof("Listening for OTLP traces at http://%s%s", addr, otlpHTTPPath)
+	}
+	if cfg.GRPCPort != 0 {
+		addr := fmt.Sprintf("%s:%d", cfg.BindHost, cfg.GRPCPort)
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			killProcess("Error creating OTLP/gRPC listener: %v", err)
+		}
+		o.grpcServer = grpc.NewServer(
+			grpc.CustomCodec(otlp.Codec{}),
+			grpc.MaxRecvMsgSize(int(o.conf.MaxRequestBytes)),
+			grpc.UnknownServiceHandler(o.handleGRPCStream),
+		)
+		go func() {
+			defer watchdog.LogOnPanic()
+			o.grpcServer.Serve(ln)
+		}()
+		log.Infof("Listening for OTLP traces with gRPC at %s", addr)
+	}
+}
+
+// Stop stops the servers and waits for the received payloads to be sent. It must be
+// called before the out channel is closed.
+func (o *OTLPReceiver) Stop() error {
+	if o.grpcServer != nil {
+		o.grpcServer.GracefulStop()
+	}
+	var err error
+	if o.httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = o.httpServer.Shutdown(ctx)
+	}
+	o.wg.Wait()
+	return err
+}
+
+// handleHTTPTraces handles the OTLP/HTTP export requests encoded with protobuf.
+func (o *OTLPReceiver) handleHTTPTraces(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if mediaType := getMediaType(req); mediaType != "application/x-protobuf" {
+		http.Error(w, fmt.Sprintf("unsupported media type: %q", mediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := ioutil.ReadAll(NewLimitedReader(req.Body, o.conf.MaxRequestBytes))
+	if err != nil {
+		httpDecodingError(err, []string{"handler:otlp", "v:http_v1"}, w)
+		return
+	}
+	request := &otlp.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, request); err != nil {
+		httpDecodingError(err, []string{"handler:otlp", "v:http_v1"}, w)
+		log.Errorf("Cannot decode OTLP/HTTP traces payload: %v", err)
+		return
+	}
+	o.processRequest("http", request)
+
+	response, err := proto.Marshal(&otlp.ExportTraceServiceResponse{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(response)
+}
+
+// handleGRPCStream handles the calls of the OTLP/gRPC trace service, whose stubs are
+// not generated.
+func (o *OTLPReceiver) handleGRPCStream(srv interface{}, stream grpc.ServerStream) error {
+	if method, _ := grpc.MethodFromServerStream(stream); method != otlp.TracesExportMethod {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	request := &otlp.ExportTraceServiceRequest{}
+	if err := stream.RecvMsg(request); err != nil {
+		metrics.Count(receiverErrorKey, 1, []string{"handler:otlp", "v:grpc_v1", "error:decoding-error"}, 1)
+		return err
+	}
+	o.processRequest("grpc", request)
+	return stream.SendMsg(&otlp.ExportTraceServiceResponse{})
+}
+
+// processRequest sends a payload for each resource of an export request.
+func (o *OTLPReceiver) processRequest(protocol string, request *otlp.ExportTraceServiceRequest) {
+	for _, rs := range request.ResourceSpans {
+		o.processResourceSpans(protocol, rs)
+	}
+}
+
+func (o *OTLPReceiver) processResourceSpans(protocol string, rs *otlp.ResourceSpans) {
+	var resourceMeta map[string]string
+	if rs.Resource != nil {
+		resourceMeta = attributesToMeta(rs.Resource.Attributes)
+	}
+	ts := o.stats.GetTagStats(info.Tags{
+		Lang:            resourceMeta["telemetry.sdk.language"],
+		TracerVersion:   "otlp-" + resourceMeta["telemetry.sdk.version"],
+		EndpointVersion: "opentelemetry_" + protocol + "_v1",
+	})
+
+	traces := make(map[uint64]pb.Trace)
+	for _, scopeSpans := range [][]*otlp.ScopeSpans{rs.ScopeSpans, rs.InstrumentationLibrarySpans} {
+		for _, ss := range scopeSpans {
+			for _, span := range ss.Spans {
+				s := convertSpan(resourceMeta, ss.Scope, span)
+				traces[s.TraceID] = append(traces[s.TraceID], s)
+			}
+		}
+	}
+	if len(traces) == 0 {
+		return
+	}
+	payload := &Payload{
+		Source: ts,
+		Traces: make(pb.Traces, 0, len(traces)),
+	}
+	for _, trace := range traces {
+		payload.Traces = append(payload.Traces, trace)
+	}
+	if containerID := resourceMeta["container.id"]; containerID != "" {
+		payload.ContainerTags = getContainerTags(containerID)
+	}
+
+	atomic.AddInt64(&ts.TracesReceived, int64(len(payload.Traces)))
+	atomic.AddInt64(&ts.TracesBytes, int64(proto.Size(rs)))
+	atomic.AddInt64(&ts.PayloadAccepted, 1)
+
+	select {
+	case o.out <- payload:
+		// ok
+	default:
+		// channel blocked, add a goroutine to ensure we never drop
+		o.wg.Add(1)
+		go func() {
+			metrics.Count("datadog.trace_agent.receiver.queued_send", 1, nil, 1)
+			defer func() {
+				o.wg.Done()
+				watchdog.LogOnPanic()
+			}()
+			o.out <- payload
+		}()
+	}
+}
+
+// convertSpan converts an OTLP span into a Datadog span. The resource attributes are
+// added to its meta, along with the attributes of the span which are not numbers.
+func convertSpan(resourceMeta map[string]string, scope *otlp.InstrumentationScope, in *otlp.Span) *pb.Span {
+	span := &pb.Span{
+		Service:  resourceMeta["service.name"],
+		TraceID:  idToUint64(in.TraceID),
+		SpanID:   idToUint64(in.SpanID),
+		ParentID: idToUint64(in.ParentSpanID),
+		Start:    int64(in.StartTimeUnixNano),
+		Duration: int64(in.EndTimeUnixNano) - int64(in.StartTimeUnixNano),
+		Meta:     make(map[string]string, len(resourceMeta)+len(in.Attributes)+4),
+		Metrics:  make(map[string]float64),
+	}
+	for k, v := range resourceMeta {
+		span.Meta[k] = v
+	}
+	for _, kv := range in.Attributes {
+		if kv.Value == nil {
+			continue
+		}
+		switch {
+		case kv.Value.IntValue != nil:
+			span.Metrics[kv.Key] = float64(*kv.Value.IntValue)
+		case kv.Value.DoubleValue != nil:
+			span.Metrics[kv.Key] = *kv.Value.DoubleValue
+		default:
+			span.Meta[kv.Key] = anyValueToString(kv.Value)
+		}
+	}
+	if code, ok := span.Metrics["http.status_code"]; ok {
+		// the Datadog tracers report the HTTP status codes as tags
+		span.Meta["http.status_code"] = strconv.FormatFloat(code, 'f', -1, 64)
+		delete(span.Metrics, "http.status_code")
+	}
+	if env, ok := resourceMeta["deployment.environment"]; ok && span.Meta["env"] == "" {
+		span.Meta["env"] = env
+	}
+	if version, ok := resourceMeta["service.version"]; ok && span.Meta["version"] == "" {
+		span.Meta["version"] = version
+	}
+	if len(in.TraceID) == 16 {
+		span.Meta["otel.trace_id"] = hex.EncodeToString(in.TraceID)
+	}
+
+	scopeName := otlpDefaultScopeName
+	if scope != nil && scope.Name != "" {
+		scopeName = scope.Name
+		span.Meta["otel.library.name"] = scope.Name
+		if scope.Version != "" {
+			span.Meta["otel.library.version"] = scope.Version
+		}
+	}
+	kind := spanKindName(in.Kind)
+	span.Meta["span.kind"] = kind
+	span.Name = scopeName + "." + kind
+	span.Resource = spanResource(in.Name, span.Meta)
+	span.Type = spanType(in.Kind, span.Meta)
+	setStatus(span, in)
+	return span
+}
+
+// idToUint64 returns the 64 lower bits of a big-endian span or trace ID, the trace IDs
+// of the OpenTelemetry, Zipkin and Jaeger spans being 128-bit long.
+func idToUint64(id []byte) uint64 {
+	if len(id) < 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(id[len(id)-8:])
+}
+
+// spanKindName returns the name of a span kind, as used in the `span.kind` tag.
+func spanKindName(kind otlp.SpanKind) string {
+	switch kind {
+	case otlp.SpanKindInternal:
+		return "internal"
+	case otlp.SpanKindServer:
+		return "server"
+	case otlp.SpanKindClient:
+		return "client"
+	case otlp.SpanKindProducer:
+		return "producer"
+	case otlp.SpanKindConsumer:
+		return "consumer"
+	default:
+		return "unspecified"
+	}
+}
+
+// spanResource returns the resource of a span from its semantic attributes, which
+// defaults to the name of the span.
+func spanResource(name string, meta map[string]string) string {
+	if method := meta["http.method"]; method != "" {
+		if route := meta["http.route"]; route != "" {
+			return method + " " + route
+		}
+		return method
+	}
+	if operation := meta["messaging.operation"]; operation != "" {
+		if destination := meta["messaging.destination"]; destination != "" {
+			return operation + " " + destination
+		}
+		return operation
+	}
+	if method := meta["rpc.method"]; method != "" {
+		if service := meta["rpc.service"]; service != "" {
+			return method + " " + service
+		}
+		return method
+	}
+	return name
+}
+
+// spanType returns the Datadog type of a span from its kind and semantic attributes.
+func spanType(kind otlp.SpanKind, meta map[string]string) string {
+	switch kind {
+	case otlp.SpanKindServer:
+		return "web"
+	case otlp.SpanKindClient:
+		switch db := meta["db.system"]; db {
+		case "":
+		case "redis", "memcached":
+			return "cache"
+		default:
+			return "db"
+		}
+		if meta["http.method"] != "" {
+			return "http"
+		}
+	}
+	return "custom"
+}
+
+// setStatus sets the OTLP status of a span in its meta and flags the span in error
+// if its status is an error, in which case its exception events are reported in the
+// error tags.
+func setStatus(span *pb.Span, in *otlp.Span) {
+	if in.Status == nil {
+		span.Meta["otel.status_code"] = "Unset"
+		return
+	}
+	switch in.Status.Code {
+	case otlp.StatusCodeOk:
+		span.Meta["otel.status_code"] = "Ok"
+	case otlp.StatusCodeError:
+		span.Meta["otel.status_code"] = "Error"
+	default:
+		span.Meta["otel.status_code"] = "Unset"
+	}
+	if in.Status.Message != "" {
+		span.Meta["otel.status_description"] = in.Status.Message
+	}
+	if in.Status.Code != otlp.StatusCodeError {
+		return
+	}
+
+	span.Error = 1
+	if in.Status.Message != "" {
+		span.Meta["error.msg"] = in.Status.Message
+	}
+	for _, event := range in.Events {
+		if event.Name != "exception" {
+			continue
+		}
+		attributes := attributesToMeta(event.Attributes)
+		if v, ok := attributes["exception.type"]; ok {
+			span.Meta["error.type"] = v
+		}
+		if v, ok := attributes["exception.message"]; ok {
+			span.Meta["error.msg"] = v
+		}
+		if v, ok := attributes["exception.stacktrace"]; ok {
+			span.Meta["error.stack"] = v
+		}
+	}
+}
+
+// attributesToMeta returns the attributes as strings.
+func attributesToMeta(attributes []*otlp.KeyValue) map[string]string {
+	meta := make(map[string]string, len(attributes))
+	for _, kv := range attributes {
+		if kv.Value != nil {
+			meta[kv.Key] = anyValueToString(kv.Value)
+		}
+	}
+	return meta
+}
+
+// anyValueToString returns the string representation of an attribute value, the arrays
+// and key-value lists are written in a JSON-like format.
+func anyValueToString(v *otlp.AnyValue) string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strconv.FormatInt(*v.IntValue, 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.ArrayValue != nil:
+		values := make([]string, 0, len(v.ArrayValue.Values))
+		for _, value := range v.ArrayValue.Values {
+			if value != nil {
+				values = append(values, anyValueToString(value))
+			}
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	case v.KvlistValue != nil:
+		values := make([]string, 0, len(v.KvlistValue.Values))
+		for _, kv := range v.KvlistValue.Values {
+			if kv.Value != nil {
+				values = append(values, kv.Key+":"+anyValueToString(kv.Value))
+			}
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	case v.BytesValue != nil:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	}
+	return ""
+}
@@ -0,0 +1,262 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/DataDog/datadog-agent/pkg/otlp"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func otlpString(key, value string) *otlp.KeyValue {
+	return &otlp.KeyValue{Key: key, Value: &otlp.AnyValue{StringValue: &value}}
+}
+
+func otlpInt(key string, value int64) *otlp.KeyValue {
+	return &otlp.KeyValue{Key: key, Value: &otlp.AnyValue{IntValue: &value}}
+}
+
+// testOTLPRequest returns a request holding a server span and its client child span
+func testOTLPRequest() *otlp.ExportTraceServiceRequest {
+	traceID := []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2}
+	return &otlp.ExportTraceServiceRequest{
+		ResourceSpans: []*otlp.ResourceSpans{{
+			Resource: &otlp.Resource{Attributes: []*otlp.KeyValue{
+				otlpString("service.name", "checkout"),
+				otlpString("telemetry.sdk.language", "go"),
+			}},
+			ScopeSpans: []*otlp.ScopeSpans{{
+				Scope: &otlp.InstrumentationScope{Name: "net/http"},
+				Spans: []*otlp.Span{
+					{
+						TraceID:           traceID,
+						SpanID:            []byte{0, 0, 0, 0, 0, 0, 0, 3},
+						Name:              "HTTP GET",
+						Kind:              otlp.SpanKindServer,
+						StartTimeUnixNano: 100,
+						EndTimeUnixNano:   300,
+						Attributes:        []*otlp.KeyValue{otlpString("http.method", "GET")},
+					},
+					{
+						TraceID:           traceID,
+						SpanID:            []byte{0, 0, 0, 0, 0, 0, 0, 4},
+						ParentSpanID:      []byte{0, 0, 0, 0, 0, 0, 0, 3},
+						Name:              "SELECT",
+						Kind:              otlp.SpanKindClient,
+						StartTimeUnixNano: 150,
+						EndTimeUnixNano:   250,
+						Attributes:        []*otlp.KeyValue{otlpString("db.system", "postgresql")},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func newTestOTLPReceiver(conf *config.AgentConfig) (*OTLPReceiver, chan *Payload) {
+	out := make(chan *Payload, 10)
+	return NewOTLPReceiver(out, conf, info.NewReceiverStats()), out
+}
+
+func TestConvertSpan(t *testing.T) {
+	resourceMeta := map[string]string{
+		"service.name":           "checkout",
+		"service.version":        "1.2.3",
+		"deployment.environment": "prod",
+	}
+	in := &otlp.Span{
+		TraceID:           []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2},
+		SpanID:            []byte{0, 0, 0, 0, 0, 0, 0, 3},
+		ParentSpanID:      []byte{0, 0, 0, 0, 0, 0, 0, 4},
+		Name:              "HTTP POST",
+		Kind:              otlp.SpanKindServer,
+		StartTimeUnixNano: 1000,
+		EndTimeUnixNano:   1500,
+		Attributes: []*otlp.KeyValue{
+			otlpString("http.method", "POST"),
+			otlpString("http.route", "/cart/{id}"),
+			otlpInt("http.status_code", 500),
+			otlpInt("retries", 2),
+		},
+		Events: []*otlp.SpanEvent{{
+			Name: "exception",
+			Attributes: []*otlp.KeyValue{
+				otlpString("exception.type", "ValueError"),
+				otlpString("exception.message", "invalid cart"),
+				otlpString("exception.stacktrace", "main.go:12"),
+			},
+		}},
+		Status: &otlp.SpanStatus{Code: otlp.StatusCodeError, Message: "internal error"},
+	}
+
+	span := convertSpan(resourceMeta, &otlp.InstrumentationScope{Name: "net/http", Version: "0.1"}, in)
+	assert.Equal(t, &pb.Span{
+		Service:  "checkout",
+		Name:     "net/http.server",
+		Resource: "POST /cart/{id}",
+		TraceID:  2,
+		SpanID:   3,
+		ParentID: 4,
+		Start:    1000,
+		Duration: 500,
+		Error:    1,
+		Type:     "web",
+		Meta: map[string]string{
+			"service.name":            "checkout",
+			"service.version":         "1.2.3",
+			"deployment.environment":  "prod",
+			"env":                     "prod",
+			"version":                 "1.2.3",
+			"http.method":             "POST",
+			"http.route":              "/cart/{id}",
+			"http.status_code":        "500",
+			"span.kind":               "server",
+			"otel.trace_id":           "00000000000000010000000000000002",
+			"otel.library.name":       "net/http",
+			"otel.library.version":    "0.1",
+			"otel.status_code":        "Error",
+			"otel.status_description": "internal error",
+			"error.type":              "ValueError",
+			"error.msg":               "invalid cart",
+			"error.stack":             "main.go:12",
+		},
+		Metrics: map[string]float64{"retries": 2},
+	}, span)
+}
+
+func TestConvertSpanDefaults(t *testing.T) {
+	span := convertSpan(nil, nil, &otlp.Span{Name: "work", Kind: otlp.SpanKindInternal})
+	assert.Equal(t, "opentelemetry.internal", span.Name)
+	assert.Equal(t, "work", span.Resource)
+	assert.Equal(t, "custom", span.Type)
+	assert.Equal(t, int32(0), span.Error)
+	assert.Equal(t, "Unset", span.Meta["otel.status_code"])
+
+	// the exception events of the spans which are not in error are skipped
+	span = convertSpan(nil, nil, &otlp.Span{
+		Status: &otlp.SpanStatus{Code: otlp.StatusCodeOk},
+		Events: []*otlp.SpanEvent{{Name: "exception", Attributes: []*otlp.KeyValue{otlpString("exception.message", "handled")}}},
+	})
+	assert.Equal(t, int32(0), span.Error)
+	assert.Equal(t, "Ok", span.Meta["otel.status_code"])
+	assert.NotContains(t, span.Meta, "error.msg")
+}
+
+func TestSpanResourceAndType(t *testing.T) {
+	for _, tt := range []struct {
+		kind     otlp.SpanKind
+		meta     map[string]string
+		resource string
+		spanType string
+	}{
+		{otlp.SpanKindServer, map[string]string{"http.method": "GET"}, "GET", "web"},
+		{otlp.SpanKindClient, map[string]string{"http.method": "GET", "http.route": "/users"}, "GET /users", "http"},
+		{otlp.SpanKindClient, map[string]string{"db.system": "redis"}, "span", "cache"},
+		{otlp.SpanKindClient, map[string]string{"db.system": "mysql"}, "span", "db"},
+		{otlp.SpanKindClient, map[string]string{"rpc.service": "Cart", "rpc.method": "Get"}, "Get Cart", "custom"},
+		{otlp.SpanKindProducer, map[string]string{"messaging.operation": "send", "messaging.destination": "orders"}, "send orders", "custom"},
+		{otlp.SpanKindConsumer, map[string]string{}, "span", "custom"},
+	} {
+		assert.Equal(t, tt.resource, spanResource("span", tt.meta))
+		assert.Equal(t, tt.spanType, spanType(tt.kind, tt.meta))
+	}
+}
+
+func TestAnyValueToString(t *testing.T) {
+	b := true
+	d := 1.5
+	i := int64(-3)
+	s := "a"
+	assert.Equal(t, "true", anyValueToString(&otlp.AnyValue{BoolValue: &b}))
+	assert.Equal(t, "1.5", anyValueToString(&otlp.AnyValue{DoubleValue: &d}))
+	assert.Equal(t, "[a,-3]", anyValueToString(&otlp.AnyValue{ArrayValue: &otlp.ArrayValue{Values: []*otlp.AnyValue{{StringValue: &s}, {IntValue: &i}}}}))
+	assert.Equal(t, "{k:a}", anyValueToString(&otlp.AnyValue{KvlistValue: &otlp.KeyValueList{Values: []*otlp.KeyValue{otlpString("k", "a")}}}))
+	assert.Equal(t, "AQI=", anyValueToString(&otlp.AnyValue{BytesValue: []byte{1, 2}}))
+}
+
+func TestOTLPReceiverHTTP(t *testing.T) {
+	o, out := newTestOTLPReceiver(config.New())
+
+	body, err := proto.Marshal(testOTLPRequest())
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, otlpHTTPPath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	w := httptest.NewRecorder()
+	o.handleHTTPTraces(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-protobuf", w.Header().Get("Content-Type"))
+
+	require.Len(t, out, 1)
+	payload := <-out
+	require.Len(t, payload.Traces, 1)
+	require.Len(t, payload.Traces[0], 2)
+	assert.Equal(t, "checkout", payload.Traces[0][0].Service)
+	assert.Equal(t, "web", payload.Traces[0][0].Type)
+	assert.Equal(t, "db", payload.Traces[0][1].Type)
+	assert.Equal(t, uint64(3), payload.Traces[0][1].ParentID)
+	assert.Equal(t, "go", payload.Source.Lang)
+	assert.Equal(t, "opentelemetry_http_v1", payload.Source.EndpointVersion)
+	assert.EqualValues(t, 1, payload.Source.TracesReceived)
+
+	// JSON payloads are not supported
+	req = httptest.NewRequest(http.MethodPost, otlpHTTPPath, bytes.NewReader([]byte("{}")))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	o.handleHTTPTraces(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, otlpHTTPPath, bytes.NewReader([]byte("invalid")))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	w = httptest.NewRecorder()
+	o.handleHTTPTraces(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, out, 0)
+}
+
+func TestOTLPReceiverGRPC(t *testing.T) {
+	// pick a free port
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	conf := config.New()
+	conf.OTLPReceiver = &config.OTLP{BindHost: "127.0.0.1", GRPCPort: port}
+	o, out := newTestOTLPReceiver(conf)
+	o.Start()
+	defer o.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, ln.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.Invoke(ctx, otlp.TracesExportMethod, testOTLPRequest(), &otlp.ExportTraceServiceResponse{}, grpc.ForceCodec(otlp.Codec{}))
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	payload := <-out
+	require.Len(t, payload.Traces, 1)
+	assert.Len(t, payload.Traces[0], 2)
+	assert.Equal(t, "opentelemetry_grpc_v1", payload.Source.EndpointVersion)
+
+	err = conn.Invoke(ctx, otlp.MetricsExportMethod, testOTLPRequest(), &otlp.ExportTraceServiceResponse{}, grpc.ForceCodec(otlp.Codec{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,218 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The types of the fields of the Thrift binary protocol
+const (
+	thriftStop   byte = 0
+	thriftBool   byte = 2
+	thriftByte   byte = 3
+	thriftDouble byte = 4
+	thriftI16    byte = 6
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftMap    byte = 13
+	thriftSet    byte = 14
+	thriftList   byte = 15
+)
+
+// thriftMaxDepth is the maximum nesting of the skipped structures and containers
+const thriftMaxDepth = 64
+
+var errThriftShortBuffer = errors.New("thrift: unexpected end of payload")
+
+// thriftReader decodes the values encoded with the Thrift binary protocol, as sent by
+// the Jaeger clients. Only the decoding of the types used by Jaeger is implemented.
+type thriftReader struct {
+	data []byte
+	off  int
+}
+
+func (r *thriftReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.off < n {
+		return nil, errThriftShortBuffer
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b, nil
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *thriftReader) readBool() (bool, error) {
+	b, err := r.readByte()
+	return b != 0, err
+}
+
+func (r *thriftReader) readI16() (int16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(b)), nil
+}
+
+func (r *thriftReader) readI32() (int32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *thriftReader) readI64() (int64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+func (r *thriftReader) readDouble() (float64, error) {
+	v, err := r.readI64()
+	return math.Float64frombits(uint64(v)), err
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	size, err := r.readI32()
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(size))
+}
+
+func (r *thriftReader) readString() (string, error) {
+	b, err := r.readBinary()
+	return string(b), err
+}
+
+// readStruct reads the fields of a structure, calling readField with the ID and type
+// of each of them. readField must read or skip the field.
+func (r *thriftReader) readStruct(readField func(id int16, typ byte) error) error {
+	for {
+		typ, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if typ == thriftStop {
+			return nil
+		}
+		id, err := r.readI16()
+		if err != nil {
+			return err
+		}
+		if err := readField(id, typ); err != nil {
+			return err
+		}
+	}
+}
+
+// readList reads the elements of a list, calling readElem for each of them. The lists
+// whose elements are not of type elemType are skipped.
+func (r *thriftReader) readList(elemType byte, readElem func() error) error {
+	typ, size, err := r.readListHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < size; i++ {
+		if typ != elemType {
+			err = r.skip(typ, 0)
+		} else {
+			err = readElem()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *thriftReader) readListHeader() (byte, int, error) {
+	typ, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err := r.readI32()
+	if err != nil {
+		return 0, 0, err
+	}
+	// each element takes at least one byte
+	if size < 0 || int(size) > len(r.data)-r.off {
+		return 0, 0, fmt.Errorf("thrift: invalid container size %d", size)
+	}
+	return typ, int(size), nil
+}
+
+// skip skips a value of the given type.
+func (r *thriftReader) skip(typ byte, depth int) error {
+	if depth > thriftMaxDepth {
+		return errors.New("thrift: maximum depth exceeded")
+	}
+	var err error
+	switch typ {
+	case thriftBool, thriftByte:
+		_, err = r.next(1)
+	case thriftI16:
+		_, err = r.next(2)
+	case thriftI32:
+		_, err = r.next(4)
+	case thriftDouble, thriftI64:
+		_, err = r.next(8)
+	case thriftString:
+		_, err = r.readBinary()
+	case thriftStruct:
+		err = r.readStruct(func(_ int16, typ byte) error {
+			return r.skip(typ, depth+1)
+		})
+	case thriftMap:
+		var keyType, valueType byte
+		var size int32
+		if keyType, err = r.readByte(); err != nil {
+			return err
+		}
+		if valueType, err = r.readByte(); err != nil {
+			return err
+		}
+		if size, err = r.readI32(); err != nil {
+			return err
+		}
+		if size < 0 {
+			return fmt.Errorf("thrift: invalid map size %d", size)
+		}
+		for i := int32(0); i < size && err == nil; i++ {
+			if err = r.skip(keyType, depth+1); err == nil {
+				err = r.skip(valueType, depth+1)
+			}
+		}
+	case thriftSet, thriftList:
+		var elemType byte
+		var size int
+		if elemType, size, err = r.readListHeader(); err != nil {
+			return err
+		}
+		for i := 0; i < size && err == nil; i++ {
+			err = r.skip(elemType, depth+1)
+		}
+	default:
+		err = fmt.Errorf("thrift: unknown type %d", typ)
+	}
+	return err
+}
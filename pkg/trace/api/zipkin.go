@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/pkg/otlp"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// zipkinJSONSpan is a Zipkin v2 span encoded with JSON, its IDs are hex strings.
+type zipkinJSONSpan struct {
+	TraceID        string              `json:"traceId"`
+	ParentID       string              `json:"parentId"`
+	ID             string              `json:"id"`
+	Kind           string              `json:"kind"`
+	Name           string              `json:"name"`
+	Timestamp      uint64              `json:"timestamp"`
+	Duration       uint64              `json:"duration"`
+	LocalEndpoint  *zipkinJSONEndpoint `json:"localEndpoint"`
+	RemoteEndpoint *zipkinJSONEndpoint `json:"remoteEndpoint"`
+	Annotations    []*ZipkinAnnotation `json:"annotations"`
+	Tags           map[string]string   `json:"tags"`
+	Debug          bool                `json:"debug"`
+	Shared         bool                `json:"shared"`
+}
+
+// zipkinJSONEndpoint is the network context of a Zipkin span encoded with JSON.
+type zipkinJSONEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int32  `json:"port"`
+}
+
+var zipkinJSONSpanKinds = map[string]ZipkinSpanKind{
+	"CLIENT":   ZipkinSpanKindClient,
+	"SERVER":   ZipkinSpanKindServer,
+	"PRODUCER": ZipkinSpanKindProducer,
+	"CONSUMER": ZipkinSpanKindConsumer,
+}
+
+// toProto returns the protobuf representation of a span.
+func (s *zipkinJSONSpan) toProto() (*ZipkinSpan, error) {
+	span := &ZipkinSpan{
+		Kind:           zipkinJSONSpanKinds[s.Kind],
+		Name:           s.Name,
+		Timestamp:      s.Timestamp,
+		Duration:       s.Duration,
+		LocalEndpoint:  s.LocalEndpoint.toProto(),
+		RemoteEndpoint: s.RemoteEndpoint.toProto(),
+		Annotations:    s.Annotations,
+		Tags:           s.Tags,
+		Debug:          s.Debug,
+		Shared:         s.Shared,
+	}
+	var err error
+	if span.TraceID, err = decodeZipkinID("traceId", s.TraceID); err != nil {
+		return nil, err
+	}
+	if span.ID, err = decodeZipkinID("id", s.ID); err != nil {
+		return nil, err
+	}
+	if s.ParentID != "" {
+		if span.ParentID, err = decodeZipkinID("parentId", s.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	return span, nil
+}
+
+func (e *zipkinJSONEndpoint) toProto() *ZipkinEndpoint {
+	if e == nil {
+		return nil
+	}
+	return &ZipkinEndpoint{
+		ServiceName: e.ServiceName,
+		IPv4:        net.ParseIP(e.IPv4).To4(),
+		IPv6:        net.ParseIP(e.IPv6).To16(),
+		Port:        e.Port,
+	}
+}
+
+// decodeZipkinID decodes an ID written with up to 16 hex characters, or 32 for the
+// trace IDs. The shorter IDs are left-padded with zeros.
+func decodeZipkinID(field, id string) ([]byte, error) {
+	maxLen := 16
+	if field == "traceId" {
+		maxLen = 32
+	}
+	if len(id) == 0 || len(id) > maxLen {
+		return nil, fmt.Errorf("invalid %s %q: it must be 1 to %d hex characters long", field, id, maxLen)
+	}
+	if len(id) <= 16 {
+		id = strings.Repeat("0", 16-len(id)) + id
+	} else {
+		id = strings.Repeat("0", 32-len(id)) + id
+	}
+	decoded, err := hex.DecodeString(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %v", field, id, err)
+	}
+	return decoded, nil
+}
+
+// handleZipkinSpans handles the Zipkin v2 spans encoded with JSON or protobuf.
+func (r *HTTPReceiver) handleZipkinSpans(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ts := r.Stats.GetTagStats(info.Tags{EndpointVersion: "zipkin_v2"})
+	body := NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
+	spans, err := decodeZipkinSpans(req, body, r.conf.MaxRequestBytes)
+	if err != nil {
+		httpDecodingError(err, []string{"handler:zipkin", "v:v2"}, w)
+		log.Errorf("Cannot decode Zipkin spans payload: %v", err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+
+	traces := groupByTrace(spans)
+	atomic.AddInt64(&ts.TracesReceived, int64(len(traces)))
+	atomic.AddInt64(&ts.TracesBytes, body.Count)
+	atomic.AddInt64(&ts.PayloadAccepted, 1)
+	if len(traces) == 0 {
+		return
+	}
+	payload := &Payload{Source: ts, Traces: traces}
+	if containerID := req.Header.Get(headerContainerID); containerID != "" {
+		payload.ContainerTags = getContainerTags(containerID)
+	}
+	r.sendPayload(payload)
+}
+
+// decodeZipkinSpans decodes the spans of a request, encoded with protobuf or JSON
+// according to its media type. The decompressed body is limited to maxBytes too.
+func decodeZipkinSpans(req *http.Request, body io.Reader, maxBytes int64) ([]*pb.Span, error) {
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = NewLimitedReader(gz, maxBytes)
+	}
+
+	var zipkinSpans []*ZipkinSpan
+	switch getMediaType(req) {
+	case "application/x-protobuf", "application/protobuf":
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		list := &ZipkinListOfSpans{}
+		if err := proto.Unmarshal(data, list); err != nil {
+			return nil, err
+		}
+		zipkinSpans = list.Spans
+	default:
+		var jsonSpans []*zipkinJSONSpan
+		if err := json.NewDecoder(body).Decode(&jsonSpans); err != nil {
+			return nil, err
+		}
+		zipkinSpans = make([]*ZipkinSpan, 0, len(jsonSpans))
+		for _, s := range jsonSpans {
+			span, err := s.toProto()
+			if err != nil {
+				return nil, err
+			}
+			zipkinSpans = append(zipkinSpans, span)
+		}
+	}
+
+	spans := make([]*pb.Span, 0, len(zipkinSpans))
+	for _, s := range zipkinSpans {
+		spans = append(spans, convertZipkinSpan(s))
+	}
+	return spans, nil
+}
+
+var zipkinSpanKinds = map[ZipkinSpanKind]otlp.SpanKind{
+	ZipkinSpanKindUnspecified: otlp.SpanKindInternal,
+	ZipkinSpanKindClient:      otlp.SpanKindClient,
+	ZipkinSpanKindServer:      otlp.SpanKindServer,
+	ZipkinSpanKindProducer:    otlp.SpanKindProducer,
+	ZipkinSpanKindConsumer:    otlp.SpanKindConsumer,
+}
+
+// convertZipkinSpan converts a Zipkin span into a Datadog span. Its tags are added to
+// the meta, and its `error` tag flags it in error. A shared server span, which reuses the
+// ID of the client span, gets a new ID and is parented to the client span.
+func convertZipkinSpan(in *ZipkinSpan) *pb.Span {
+	span := &pb.Span{
+		Resource: in.Name,
+		TraceID:  idToUint64(in.TraceID),
+		SpanID:   idToUint64(in.ID),
+		ParentID: idToUint64(in.ParentID),
+		Start:    int64(in.Timestamp) * 1000,
+		Duration: int64(in.Duration) * 1000,
+		Meta:     make(map[string]string, len(in.Tags)+4),
+		Metrics:  make(map[string]float64),
+	}
+	for k, v := range in.Tags {
+		span.Meta[k] = v
+	}
+	if in.LocalEndpoint != nil {
+		span.Service = in.LocalEndpoint.ServiceName
+	}
+	if remote := in.RemoteEndpoint; remote != nil {
+		if remote.ServiceName != "" {
+			span.Meta["peer.service"] = remote.ServiceName
+		}
+		if len(remote.IPv4) == net.IPv4len {
+			span.Meta["peer.ipv4"] = net.IP(remote.IPv4).String()
+		}
+		if len(remote.IPv6) == net.IPv6len {
+			span.Meta["peer.ipv6"] = net.IP(remote.IPv6).String()
+		}
+		if remote.Port != 0 {
+			span.Meta["peer.port"] = strconv.Itoa(int(remote.Port))
+		}
+	}
+	if len(in.TraceID) == 16 {
+		span.Meta["zipkin.trace_id"] = hex.EncodeToString(in.TraceID)
+	}
+
+	kind := zipkinSpanKinds[in.Kind]
+	if in.Shared && kind == otlp.SpanKindServer {
+		span.ParentID = span.SpanID
+		span.SpanID = sharedSpanID(in.ID)
+	}
+	span.Meta["span.kind"] = spanKindName(kind)
+	span.Name = "zipkin." + spanKindName(kind)
+	span.Type = spanType(kind, span.Meta)
+	if msg, ok := in.Tags["error"]; ok {
+		span.Error = 1
+		if msg != "" && msg != "true" {
+			span.Meta["error.msg"] = msg
+		}
+	}
+	if in.Debug {
+		sampler.SetSamplingPriority(span, sampler.PriorityUserKeep)
+	}
+	return span
+}
+
+// sharedSpanID derives the ID of a shared server span from the ID of the client span.
+func sharedSpanID(clientID []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(clientID) //nolint:errcheck
+	h.Write([]byte("shared"))
+	if id := h.Sum64(); id != 0 {
+		return id
+	}
+	return 1
+}
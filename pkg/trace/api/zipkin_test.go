@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+)
+
+const testZipkinJSON = `[
+  {
+    "traceId": "463ac35c9f6413ad48485a3953bb6124",
+    "id": "a2fb4a1d1a96d312",
+    "kind": "SERVER",
+    "name": "get /users/{id}",
+    "timestamp": 1472470996199000,
+    "duration": 207000,
+    "localEndpoint": {"serviceName": "frontend", "ipv4": "192.168.99.1"},
+    "remoteEndpoint": {"ipv4": "172.19.0.2", "port": 58648},
+    "tags": {"http.method": "GET", "http.path": "/users/12", "http.status_code": "500", "error": "Internal Server Error"},
+    "debug": true
+  },
+  {
+    "traceId": "48485a3953bb6124",
+    "parentId": "a2fb4a1d1a96d312",
+    "id": "b7ad6b7169203331",
+    "kind": "CLIENT",
+    "name": "query",
+    "timestamp": 1472470996238000,
+    "duration": 91000,
+    "localEndpoint": {"serviceName": "frontend"},
+    "remoteEndpoint": {"serviceName": "mysql"},
+    "tags": {"db.system": "mysql"}
+  }
+]`
+
+func postZipkin(r *HTTPReceiver, contentType string, body []byte, gzipped bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	w := httptest.NewRecorder()
+	r.handleZipkinSpans(w, req)
+	return w
+}
+
+func TestZipkinJSON(t *testing.T) {
+	r := newTestReceiverFromConfig(config.New())
+	w := postZipkin(r, "application/json", []byte(testZipkinJSON), false)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	require.Len(t, r.out, 1)
+	payload := <-r.out
+	assert.Equal(t, "zipkin_v2", payload.Source.EndpointVersion)
+	// both trace IDs share the same lower 64 bits
+	require.Len(t, payload.Traces, 1)
+	require.Len(t, payload.Traces[0], 2)
+
+	server, client := payload.Traces[0][0], payload.Traces[0][1]
+	assert.Equal(t, &pb.Span{
+		Service:  "frontend",
+		Name:     "zipkin.server",
+		Resource: "get /users/{id}",
+		TraceID:  0x48485a3953bb6124,
+		SpanID:   0xa2fb4a1d1a96d312,
+		Start:    1472470996199000000,
+		Duration: 207000000,
+		Error:    1,
+		Type:     "web",
+		Meta: map[string]string{
+			"http.method":      "GET",
+			"http.path":        "/users/12",
+			"http.status_code": "500",
+			"error":            "Internal Server Error",
+			"error.msg":        "Internal Server Error",
+			"peer.ipv4":        "172.19.0.2",
+			"peer.port":        "58648",
+			"span.kind":        "server",
+			"zipkin.trace_id":  "463ac35c9f6413ad48485a3953bb6124",
+		},
+		Metrics: map[string]float64{sampler.KeySamplingPriority: float64(sampler.PriorityUserKeep)},
+	}, server)
+
+	assert.Equal(t, uint64(0xa2fb4a1d1a96d312), client.ParentID)
+	assert.Equal(t, "db", client.Type)
+	assert.Equal(t, "mysql", client.Meta["peer.service"])
+	assert.NotContains(t, client.Meta, "zipkin.trace_id")
+	assert.Equal(t, int32(0), client.Error)
+}
+
+func TestZipkinProtoGzip(t *testing.T) {
+	r := newTestReceiverFromConfig(config.New())
+	body, err := proto.Marshal(&ZipkinListOfSpans{Spans: []*ZipkinSpan{{
+		TraceID:       []byte{0, 0, 0, 0, 0, 0, 0, 1},
+		ID:            []byte{0, 0, 0, 0, 0, 0, 0, 2},
+		Kind:          ZipkinSpanKindProducer,
+		Name:          "send",
+		Timestamp:     10,
+		Duration:      5,
+		LocalEndpoint: &ZipkinEndpoint{ServiceName: "orders"},
+		Tags:          map[string]string{"messaging.system": "kafka"},
+	}}})
+	require.NoError(t, err)
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err = gz.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	w := postZipkin(r, "application/x-protobuf", gzipped.Bytes(), true)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	require.Len(t, r.out, 1)
+	payload := <-r.out
+	require.Len(t, payload.Traces, 1)
+	span := payload.Traces[0][0]
+	assert.Equal(t, uint64(1), span.TraceID)
+	assert.Equal(t, uint64(2), span.SpanID)
+	assert.Equal(t, "orders", span.Service)
+	assert.Equal(t, "zipkin.producer", span.Name)
+	assert.Equal(t, "custom", span.Type)
+	assert.Equal(t, int64(10000), span.Start)
+	assert.Equal(t, int64(5000), span.Duration)
+	assert.Equal(t, "kafka", span.Meta["messaging.system"])
+}
+
+func TestConvertZipkinSharedSpan(t *testing.T) {
+	id := []byte{0, 0, 0, 0, 0, 0, 0, 2}
+	client := convertZipkinSpan(&ZipkinSpan{
+		TraceID:  []byte{0, 0, 0, 0, 0, 0, 0, 1},
+		ID:       id,
+		ParentID: []byte{0, 0, 0, 0, 0, 0, 0, 3},
+		Kind:     ZipkinSpanKindClient,
+	})
+	server := convertZipkinSpan(&ZipkinSpan{
+		TraceID:  []byte{0, 0, 0, 0, 0, 0, 0, 1},
+		ID:       id,
+		ParentID: []byte{0, 0, 0, 0, 0, 0, 0, 3},
+		Kind:     ZipkinSpanKindServer,
+		Shared:   true,
+	})
+
+	assert.Equal(t, uint64(2), client.SpanID)
+	assert.Equal(t, uint64(3), client.ParentID)
+	// the server span gets its own ID and is a child of the client span
+	assert.NotEqual(t, client.SpanID, server.SpanID)
+	assert.NotZero(t, server.SpanID)
+	assert.Equal(t, client.SpanID, server.ParentID)
+	assert.Equal(t, server.SpanID, convertZipkinSpan(&ZipkinSpan{ID: id, Kind: ZipkinSpanKindServer, Shared: true}).SpanID)
+}
+
+func TestZipkinInvalidPayloads(t *testing.T) {
+	r := newTestReceiverFromConfig(config.New())
+	for _, body := range []string{
+		`{"traceId": "1"}`,
+		`[{"traceId": "not hex", "id": "1"}]`,
+		`[{"traceId": "1", "id": "1234567890abcdef1"}]`,
+		`[{"traceId": "1"}]`,
+	} {
+		w := postZipkin(r, "application/json", []byte(body), false)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Len(t, r.out, 0)
+}
+
+func TestZipkinPayloadTooLarge(t *testing.T) {
+	conf := config.New()
+	conf.MaxRequestBytes = 100
+	r := newTestReceiverFromConfig(conf)
+	body := []byte(`[` + strings.Repeat(`{"traceId": "1", "id": "1"},`, 20) + `{"traceId": "1", "id": "1"}]`)
+
+	w := postZipkin(r, "application/json", body, false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// the decompressed payload is limited too
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err := gz.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.Less(t, gzipped.Len(), 100)
+	w = postZipkin(r, "application/json", gzipped.Bytes(), true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Len(t, r.out, 0)
+}
+
+func TestDecodeZipkinID(t *testing.T) {
+	id, err := decodeZipkinID("id", "1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1}, id)
+	id, err = decodeZipkinID("traceId", "10000000000000001")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1}, id)
+	_, err = decodeZipkinID("id", "")
+	assert.Error(t, err)
+	_, err = decodeZipkinID("id", "10000000000000001")
+	assert.Error(t, err)
+}
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// The types below are the messages of the Zipkin v2 protobuf format, see
+// https://github.com/openzipkin/zipkin-api/blob/master/zipkin.proto
+
+// ZipkinSpanKind is the kind of a Zipkin span.
+type ZipkinSpanKind int32
+
+// The kinds of Zipkin spans
+const (
+	ZipkinSpanKindUnspecified ZipkinSpanKind = 0
+	ZipkinSpanKindClient      ZipkinSpanKind = 1
+	ZipkinSpanKindServer      ZipkinSpanKind = 2
+	ZipkinSpanKindProducer    ZipkinSpanKind = 3
+	ZipkinSpanKindConsumer    ZipkinSpanKind = 4
+)
+
+// ZipkinListOfSpans is the payload of the Zipkin v2 protobuf requests.
+type ZipkinListOfSpans struct {
+	Spans []*ZipkinSpan `protobuf:"bytes,1,rep,name=spans,proto3" json:"spans,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ZipkinListOfSpans) Reset() { *m = ZipkinListOfSpans{} }
+
+// String implements proto.Message
+func (m *ZipkinListOfSpans) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ZipkinListOfSpans) ProtoMessage() {}
+
+// ZipkinSpan is a Zipkin v2 span, timestamps and durations are in microseconds.
+type ZipkinSpan struct {
+	TraceID        []byte              `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	ParentID       []byte              `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	ID             []byte              `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	Kind           ZipkinSpanKind      `protobuf:"varint,4,opt,name=kind,proto3,enum=ZipkinSpanKind" json:"kind,omitempty"`
+	Name           string              `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	Timestamp      uint64              `protobuf:"fixed64,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Duration       uint64              `protobuf:"varint,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	LocalEndpoint  *ZipkinEndpoint     `protobuf:"bytes,8,opt,name=local_endpoint,json=localEndpoint,proto3" json:"local_endpoint,omitempty"`
+	RemoteEndpoint *ZipkinEndpoint     `protobuf:"bytes,9,opt,name=remote_endpoint,json=remoteEndpoint,proto3" json:"remote_endpoint,omitempty"`
+	Annotations    []*ZipkinAnnotation `protobuf:"bytes,10,rep,name=annotations,proto3" json:"annotations,omitempty"`
+	Tags           map[string]string   `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Debug          bool                `protobuf:"varint,12,opt,name=debug,proto3" json:"debug,omitempty"`
+	Shared         bool                `protobuf:"varint,13,opt,name=shared,proto3" json:"shared,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ZipkinSpan) Reset() { *m = ZipkinSpan{} }
+
+// String implements proto.Message
+func (m *ZipkinSpan) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ZipkinSpan) ProtoMessage() {}
+
+// ZipkinEndpoint is the network context of a Zipkin span.
+type ZipkinEndpoint struct {
+	ServiceName string `protobuf:"bytes,1,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	IPv4        []byte `protobuf:"bytes,2,opt,name=ipv4,proto3" json:"ipv4,omitempty"`
+	IPv6        []byte `protobuf:"bytes,3,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	Port        int32  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ZipkinEndpoint) Reset() { *m = ZipkinEndpoint{} }
+
+// String implements proto.Message
+func (m *ZipkinEndpoint) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ZipkinEndpoint) ProtoMessage() {}
+
+// ZipkinAnnotation is an event which happened during a Zipkin span.
+type ZipkinAnnotation struct {
+	Timestamp uint64 `protobuf:"fixed64,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Value     string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ZipkinAnnotation) Reset() { *m = ZipkinAnnotation{} }
+
+// String implements proto.Message
+func (m *ZipkinAnnotation) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ZipkinAnnotation) ProtoMessage() {}
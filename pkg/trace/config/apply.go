@@ -64,6 +64,39 @@ type HTTPObfuscationConfig struct {
 	RemovePathDigits bool `mapstructure:"remove_paths_with_digits" json:"remove_path_digits"`
 }
 
+// SpanRule specifies a rule of the span processor.
+type SpanRule struct {
+	// Action specifies what the rule does:
+	// • "delete" deletes the Key tag
+	// • "rename" renames the Key tag into NewKey
+	// • "hash" replaces the value of the Key tag by its SHA-256 hash, prefixed with Salt
+	// • "extract" copies the value of the Key tag into the Target, "service" or "resource"
+	// • "set" sets the Key tag to Value
+	Action string `mapstructure:"action"`
+
+	// Key specifies the tag the rule addresses.
+	Key string `mapstructure:"key"`
+
+	// NewKey specifies the new name of the tag renamed.
+	NewKey string `mapstructure:"new_key"`
+
+	// Target specifies the span field the tag is extracted into.
+	Target string `mapstructure:"target"`
+
+	// Value specifies the value of the tag set.
+	Value string `mapstructure:"value"`
+
+	// Salt specifies the salt of the hashed values.
+	Salt string `mapstructure:"salt"`
+
+	// When specifies the tags, in the form "key:value" or "key" to match any value, the
+	// spans must all have for the rule to apply.
+	When []string `mapstructure:"when"`
+
+	// Conditions holds the parsed When tags and is only used internally.
+	Conditions []*Tag `mapstructure:"-"`
+}
+
 // Enablable can represent any option that has an "enabled" boolean sub-field.
 type Enablable struct {
 	Enabled bool `mapstructure:"enabled"`
@@ -238,6 +271,18 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		}
 	}
 
+	if k := "apm_config.span_rules"; config.Datadog.IsSet(k) {
+		rules := make([]*SpanRule, 0)
+		if err := config.Datadog.UnmarshalKey(k, &rules); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"action\": \"action\",\"key\":\"tag_name\"}]', error: %v", k, err)
+		} else {
+			if err := compileSpanRules(rules); err != nil {
+				osutil.Exitf("span_rules: %s", err)
+			}
+			c.SpanRules = rules
+		}
+	}
+
 	if config.Datadog.IsSet("bind_host") || config.Datadog.IsSet("apm_config.apm_non_local_traffic") {
 		if config.Datadog.IsSet("bind_host") {
 			host := config.Datadog.GetString("bind_host")
@@ -254,6 +299,35 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		c.ReceiverHost = "0.0.0.0"
 	}
 
+	c.OTLPReceiver.BindHost = c.ReceiverHost
+	if k := "apm_config.otlp.bind_host"; config.Datadog.IsSet(k) {
+		c.OTLPReceiver.BindHost = config.Datadog.GetString(k)
+	}
+	if k := "apm_config.otlp.http_port"; config.Datadog.IsSet(k) {
+		c.OTLPReceiver.HTTPPort = config.Datadog.GetInt(k)
+	}
+	if k := "apm_config.otlp.grpc_port"; config.Datadog.IsSet(k) {
+		c.OTLPReceiver.GRPCPort = config.Datadog.GetInt(k)
+	}
+
+	if k := "apm_config.tail_sampling.enabled"; config.Datadog.IsSet(k) {
+		c.TailSampling.Enabled = config.Datadog.GetBool(k)
+	}
+	if k := "apm_config.tail_sampling.decision_wait"; config.Datadog.IsSet(k) {
+		c.TailSampling.DecisionWait = time.Duration(config.Datadog.GetFloat64(k) * float64(time.Second))
+	}
+	if k := "apm_config.tail_sampling.max_memory"; config.Datadog.IsSet(k) {
+		c.TailSampling.MaxMemory = config.Datadog.GetInt64(k)
+	}
+	if k := "apm_config.tail_sampling.policies"; config.Datadog.IsSet(k) {
+		var policies []*TailSamplingPolicy
+		if err := config.Datadog.UnmarshalKey(k, &policies); err != nil {
+			log.Errorf("Bad format for %q: %v", k, err)
+		} else {
+			c.TailSampling.Policies = policies
+		}
+	}
+
 	if config.Datadog.IsSet("apm_config.obfuscation") {
 		var o ObfuscationConfig
 		err := config.Datadog.UnmarshalKey("apm_config.obfuscation", &o)
@@ -278,6 +352,20 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		}
 	}
 
+	if k := "apm_config.extra_aggregators"; config.Datadog.IsSet(k) {
+		// the legacy configuration separates the tags with commas
+		for _, tags := range config.Datadog.GetStringSlice(k) {
+			for _, tag := range strings.Split(tags, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					c.ExtraAggregators = append(c.ExtraAggregators, tag)
+				}
+			}
+		}
+	}
+	if k := "apm_config.extra_aggregators_max_values"; config.Datadog.IsSet(k) {
+		c.MaxExtraAggregatorValues = config.Datadog.GetInt(k)
+	}
+
 	// undocumented
 	if config.Datadog.IsSet("apm_config.max_cpu_percent") {
 		c.MaxCPU = config.Datadog.GetFloat64("apm_config.max_cpu_percent") / 100
@@ -463,6 +551,34 @@ func toFloat64(val interface{}) (float64, error) {
 	}
 }
 
+// compileSpanRules validates the span rules and parses their conditions.
+// If it fails it returns the first error.
+func compileSpanRules(rules []*SpanRule) error {
+	for _, r := range rules {
+		if r.Key == "" {
+			return fmt.Errorf("action %q: all rules must have a \"key\"", r.Action)
+		}
+		switch r.Action {
+		case "delete", "hash", "set":
+		case "rename":
+			if r.NewKey == "" {
+				return fmt.Errorf("key %q: rename rules must have a \"new_key\"", r.Key)
+			}
+		case "extract":
+			if r.Target != "service" && r.Target != "resource" {
+				return fmt.Errorf("key %q: the \"target\" of extract rules must be \"service\" or \"resource\"", r.Key)
+			}
+		default:
+			return fmt.Errorf("key %q: unknown action %q (use one of delete, rename, hash, extract or set)", r.Key, r.Action)
+		}
+		r.Conditions = make([]*Tag, 0, len(r.When))
+		for _, tag := range r.When {
+			r.Conditions = append(r.Conditions, splitTag(tag))
+		}
+	}
+	return nil
+}
+
 // splitTag splits a "k:v" formatted string and returns a Tag.
 func splitTag(tag string) *Tag {
 	parts := strings.SplitN(tag, ":", 2)
@@ -28,6 +28,26 @@ func TestParseRepaceRules(t *testing.T) {
 	}
 }
 
+func TestCompileSpanRules(t *testing.T) {
+	assert := assert.New(t)
+	rules := []*SpanRule{
+		{Action: "hash", Key: "usr.id"},
+		{Action: "set", Key: "error.class", Value: "server", When: []string{"http.status_code:500", "http.method"}},
+	}
+	assert.NoError(compileSpanRules(rules))
+	assert.Empty(rules[0].Conditions)
+	assert.Equal([]*Tag{{K: "http.status_code", V: "500"}, {K: "http.method"}}, rules[1].Conditions)
+
+	for _, r := range []*SpanRule{
+		{Action: "delete"},
+		{Action: "rename", Key: "a"},
+		{Action: "extract", Key: "a", Target: "name"},
+		{Action: "truncate", Key: "a"},
+	} {
+		assert.Error(compileSpanRules([]*SpanRule{r}), r.Action)
+	}
+}
+
 func TestSplitTag(t *testing.T) {
 	for _, tt := range []struct {
 		tag string
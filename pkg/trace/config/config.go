@@ -62,7 +62,10 @@ type AgentConfig struct {
 
 	// Concentrator
 	BucketInterval   time.Duration // the size of our pre-aggregation per bucket
-	ExtraAggregators []string
+	ExtraAggregators []string      // span tags added to the dimensions of the stats
+	// MaxExtraAggregatorValues is the maximum number of values of each extra aggregator
+	// between two flushes of the stats, the other values are aggregated as "other".
+	MaxExtraAggregatorValues int
 
 	// Sampler configuration
 	ExtraSampleRate float64
@@ -77,6 +80,12 @@ type AgentConfig struct {
 	ReceiverTimeout int
 	MaxRequestBytes int64 // specifies the maximum allowed request size for incoming trace payloads
 
+	// OTLPReceiver holds the configuration of the OpenTelemetry receiver
+	OTLPReceiver *OTLP
+
+	// TailSampling holds the configuration of the tail-based sampling
+	TailSampling *TailSampling
+
 	// Writers
 	SynchronousFlushing     bool // Mode where traces are only submitted when FlushAsync is called, used for Serverless Extension
 	StatsWriter             *WriterConfig
@@ -108,6 +117,10 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// SpanRules are applied by the span processor to delete, rename, hash or copy the tags
+	// of the spans, or to set tags.
+	SpanRules []*SpanRule
+
 	// GlobalTags list metadata that will be added to all spans
 	GlobalTags map[string]string
 
@@ -128,6 +141,40 @@ type AgentConfig struct {
 	RejectTags []*Tag
 }
 
+// OTLP holds the configuration of the receiver accepting the traces sent with the
+// OpenTelemetry protocol. A port set to 0 disables the corresponding server.
+type OTLP struct {
+	BindHost string
+	HTTPPort int
+	GRPCPort int
+}
+
+// TailSampling holds the configuration of the tail-based sampling, which buffers the
+// spans of the traces during DecisionWait and keeps the complete traces matching one
+// of the Policies. MaxMemory is the maximum size of the buffered spans, in bytes.
+type TailSampling struct {
+	Enabled      bool
+	DecisionWait time.Duration
+	MaxMemory    int64
+	Policies     []*TailSamplingPolicy
+}
+
+// TailSamplingPolicy is a policy of the tail-based sampling. The fields used depend on
+// its type:
+//   - latency: the traces lasting at least ThresholdMs are kept.
+//   - error: the traces with an error are kept.
+//   - attribute: the traces with a span whose Key tag is one of Values, or is set if
+//     Values is empty, are kept.
+//   - rate_limit: up to TracesPerSecond traces are kept per service.
+type TailSamplingPolicy struct {
+	Name            string   `mapstructure:"name"`
+	Type            string   `mapstructure:"type"`
+	ThresholdMs     float64  `mapstructure:"threshold_ms"`
+	Key             string   `mapstructure:"key"`
+	Values          []string `mapstructure:"values"`
+	TracesPerSecond float64  `mapstructure:"traces_per_second"`
+}
+
 // Tag represents a key/value pair.
 type Tag struct {
 	K, V string
@@ -140,7 +187,8 @@ func New() *AgentConfig {
 		DefaultEnv: "none",
 		Endpoints:  []*Endpoint{{Host: "https://trace.agent.datadoghq.com"}},
 
-		BucketInterval: time.Duration(10) * time.Second,
+		BucketInterval:           time.Duration(10) * time.Second,
+		MaxExtraAggregatorValues: 100,
 
 		ExtraSampleRate: 1.0,
 		TargetTPS:       10,
@@ -149,6 +197,12 @@ func New() *AgentConfig {
 		ReceiverHost:    "localhost",
 		ReceiverPort:    8126,
 		MaxRequestBytes: 50 * 1024 * 1024, // 50MB
+		OTLPReceiver:    &OTLP{},
+
+		TailSampling: &TailSampling{
+			DecisionWait: 10 * time.Second,
+			MaxMemory:    100 * 1024 * 1024, // 100MB
+		},
 
 		StatsWriter:             new(WriterConfig),
 		TraceWriter:             new(WriterConfig),
@@ -162,6 +162,18 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.Equal(0.5, c.MaxCPU)
 	assert.EqualValues(123.4, c.MaxMemory)
 	assert.Equal("0.0.0.0", c.ReceiverHost)
+	assert.Equal(&OTLP{BindHost: "0.0.0.0", HTTPPort: 14318, GRPCPort: 14317}, c.OTLPReceiver)
+	assert.Equal([]string{"customer_tier", "region"}, c.ExtraAggregators)
+	assert.Equal(50, c.MaxExtraAggregatorValues)
+	assert.Equal(&TailSampling{
+		Enabled:      true,
+		DecisionWait: 30 * time.Second,
+		MaxMemory:    1000000,
+		Policies: []*TailSamplingPolicy{
+			{Name: "slow", Type: "latency", ThresholdMs: 500},
+			{Type: "attribute", Key: "customer.tier", Values: []string{"gold", "platinum"}},
+		},
+	}, c.TailSampling)
 	assert.True(c.LogThrottling)
 
 	noProxy := true
@@ -205,6 +217,17 @@ func TestFullYamlConfig(t *testing.T) {
 		},
 	}, c.ReplaceTags)
 
+	assert.Equal([]*SpanRule{
+		{Action: "hash", Key: "usr.id", Salt: "s3cr3t", Conditions: []*Tag{}},
+		{
+			Action:     "extract",
+			Key:        "http.route",
+			Target:     "resource",
+			When:       []string{"span.kind:server"},
+			Conditions: []*Tag{{K: "span.kind", V: "server"}},
+		},
+	}, c.SpanRules)
+
 	assert.EqualValues([]string{"/health", "/500"}, c.Ignore["resource"])
 
 	o := c.Obfuscation
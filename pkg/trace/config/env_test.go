@@ -9,6 +9,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/cihub/seelog"
@@ -186,6 +187,45 @@ func TestLoadEnv(t *testing.T) {
 		assert.Equal("0.0.0.0", cfg.ReceiverHost)
 	})
 
+	env = "DD_APM_OTLP_GRPC_PORT"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, "4317")
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/undocumented.yaml")
+		assert.NoError(err)
+		assert.Equal(4317, cfg.OTLPReceiver.GRPCPort)
+		assert.Equal(0, cfg.OTLPReceiver.HTTPPort)
+	})
+
+	env = "DD_APM_EXTRA_AGGREGATORS"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, "customer_tier,region")
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/undocumented.yaml")
+		assert.NoError(err)
+		assert.Equal([]string{"customer_tier", "region"}, cfg.ExtraAggregators)
+		assert.Equal(100, cfg.MaxExtraAggregatorValues)
+	})
+
+	env = "DD_APM_TAIL_SAMPLING_DECISION_WAIT"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, "2.5")
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/undocumented.yaml")
+		assert.NoError(err)
+		assert.Equal(2500*time.Millisecond, cfg.TailSampling.DecisionWait)
+		assert.False(cfg.TailSampling.Enabled)
+	})
+
 	for _, envKey := range []string{
 		"DD_IGNORE_RESOURCE", // deprecated
 		"DD_APM_IGNORE_RESOURCES",
@@ -253,6 +293,21 @@ func TestLoadEnv(t *testing.T) {
 		assert.Contains(cfg.ReplaceTags, rule2)
 	})
 
+	env = "DD_APM_SPAN_RULES"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `[{"action":"delete", "key":"password"}, {"action":"set","key":"team","value":"web","when":["service:web"]}]`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal([]*SpanRule{
+			{Action: "delete", Key: "password", Conditions: []*Tag{}},
+			{Action: "set", Key: "team", Value: "web", When: []string{"service:web"}, Conditions: []*Tag{{K: "service", V: "web"}}},
+		}, cfg.SpanRules)
+	})
+
 	env = "DD_APM_FILTER_TAGS_REQUIRE"
 	t.Run(env, func(t *testing.T) {
 		defer cleanConfig()()
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// SpanProcessor applies its rules to the tags of the spans, in order. It keeps
+// all spans.
+type SpanProcessor struct {
+	rules []*config.SpanRule
+}
+
+// NewSpanProcessor returns a new SpanProcessor which will use the given set of rules.
+// The rules must have been validated by the configuration.
+func NewSpanProcessor(rules []*config.SpanRule) *SpanProcessor {
+	return &SpanProcessor{rules: rules}
+}
+
+// Process applies the rules to all the spans of the trace.
+func (f SpanProcessor) Process(trace pb.Trace) {
+	if len(f.rules) == 0 {
+		return
+	}
+	for _, s := range trace {
+		for _, rule := range f.rules {
+			if matchesConditions(s, rule.Conditions) {
+				applySpanRule(s, rule)
+			}
+		}
+	}
+}
+
+// matchesConditions returns whether the span has all the tags. The tags without a
+// value match any value.
+func matchesConditions(s *pb.Span, conditions []*config.Tag) bool {
+	for _, tag := range conditions {
+		v, ok := s.Meta[tag.K]
+		if !ok || (tag.V != "" && v != tag.V) {
+			return false
+		}
+	}
+	return true
+}
+
+func applySpanRule(s *pb.Span, rule *config.SpanRule) {
+	switch rule.Action {
+	case "delete":
+		delete(s.Meta, rule.Key)
+		delete(s.Metrics, rule.Key)
+	case "rename":
+		if v, ok := s.Meta[rule.Key]; ok {
+			delete(s.Meta, rule.Key)
+			s.Meta[rule.NewKey] = v
+		}
+		if v, ok := s.Metrics[rule.Key]; ok {
+			delete(s.Metrics, rule.Key)
+			s.Metrics[rule.NewKey] = v
+		}
+	case "hash":
+		if v, ok := s.Meta[rule.Key]; ok {
+			sum := sha256.Sum256([]byte(rule.Salt + v))
+			s.Meta[rule.Key] = hex.EncodeToString(sum[:])
+		}
+	case "extract":
+		v, ok := s.Meta[rule.Key]
+		if !ok || v == "" {
+			return
+		}
+		switch rule.Target {
+		case "service":
+			// the values which are not valid services are ignored
+			if svc, err := traceutil.NormalizeService(v, ""); err == nil || err == traceutil.ErrTooLong {
+				s.Service = svc
+			}
+		case "resource":
+			// the spans were already normalized, the resource is truncated the same way
+			s.Resource, _ = traceutil.TruncateResource(v)
+		}
+	case "set":
+		traceutil.SetMeta(s, rule.Key, rule.Value)
+	}
+}
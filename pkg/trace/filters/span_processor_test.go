@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestSpanProcessor(t *testing.T) {
+	for name, tt := range map[string]struct {
+		rules []*config.SpanRule
+		got   *pb.Span
+		want  *pb.Span
+	}{
+		"delete": {
+			rules: []*config.SpanRule{{Action: "delete", Key: "password"}, {Action: "delete", Key: "retries"}},
+			got: &pb.Span{
+				Meta:    map[string]string{"password": "hunter2", "user": "bob"},
+				Metrics: map[string]float64{"retries": 2},
+			},
+			want: &pb.Span{Meta: map[string]string{"user": "bob"}, Metrics: map[string]float64{}},
+		},
+		"rename": {
+			rules: []*config.SpanRule{{Action: "rename", Key: "tenant", NewKey: "customer.id"}, {Action: "rename", Key: "missing", NewKey: "other"}},
+			got:   &pb.Span{Meta: map[string]string{"tenant": "acme"}},
+			want:  &pb.Span{Meta: map[string]string{"customer.id": "acme"}},
+		},
+		"hash": {
+			rules: []*config.SpanRule{{Action: "hash", Key: "usr.id"}, {Action: "hash", Key: "usr.email", Salt: "s3cr3t"}},
+			got:   &pb.Span{Meta: map[string]string{"usr.id": "1234", "usr.email": "bob@example.com"}},
+			want: &pb.Span{Meta: map[string]string{
+				"usr.id":    sha256Hex("1234"),
+				"usr.email": sha256Hex("s3cr3tbob@example.com"),
+			}},
+		},
+		"extract": {
+			rules: []*config.SpanRule{
+				{Action: "extract", Key: "peer.service", Target: "service"},
+				{Action: "extract", Key: "http.route", Target: "resource"},
+			},
+			got: &pb.Span{
+				Service:  "web",
+				Resource: "GET",
+				Meta:     map[string]string{"peer.service": "Billing API", "http.route": "GET /users/:id"},
+			},
+			want: &pb.Span{
+				Service:  "billing_api",
+				Resource: "GET /users/:id",
+				Meta:     map[string]string{"peer.service": "Billing API", "http.route": "GET /users/:id"},
+			},
+		},
+		"extract-invalid-service": {
+			rules: []*config.SpanRule{{Action: "extract", Key: "peer.service", Target: "service"}},
+			got:   &pb.Span{Service: "web", Meta: map[string]string{"peer.service": "!!"}},
+			want:  &pb.Span{Service: "web", Meta: map[string]string{"peer.service": "!!"}},
+		},
+		"extract-long-resource": {
+			rules: []*config.SpanRule{{Action: "extract", Key: "sql.query", Target: "resource"}},
+			got:   &pb.Span{Resource: "query", Meta: map[string]string{"sql.query": strings.Repeat("a", traceutil.MaxResourceLen+10)}},
+			want: &pb.Span{
+				Resource: strings.Repeat("a", traceutil.MaxResourceLen),
+				Meta:     map[string]string{"sql.query": strings.Repeat("a", traceutil.MaxResourceLen+10)},
+			},
+		},
+		"set": {
+			rules: []*config.SpanRule{{Action: "set", Key: "team", Value: "checkout"}},
+			got:   &pb.Span{},
+			want:  &pb.Span{Meta: map[string]string{"team": "checkout"}},
+		},
+		"conditions": {
+			rules: []*config.SpanRule{
+				{Action: "set", Key: "error.class", Value: "server", Conditions: []*config.Tag{{K: "http.status_code", V: "500"}}},
+				{Action: "set", Key: "error.class", Value: "client", Conditions: []*config.Tag{{K: "http.status_code", V: "404"}}},
+				{Action: "set", Key: "http", Value: "true", Conditions: []*config.Tag{{K: "http.method"}, {K: "http.status_code"}}},
+				{Action: "set", Key: "grpc", Value: "true", Conditions: []*config.Tag{{K: "grpc.method"}}},
+			},
+			got: &pb.Span{Meta: map[string]string{"http.status_code": "500", "http.method": "GET"}},
+			want: &pb.Span{Meta: map[string]string{
+				"http.status_code": "500",
+				"http.method":      "GET",
+				"error.class":      "server",
+				"http":             "true",
+			}},
+		},
+		"order": {
+			rules: []*config.SpanRule{
+				{Action: "rename", Key: "uid", NewKey: "usr.id"},
+				{Action: "hash", Key: "usr.id"},
+			},
+			got:  &pb.Span{Meta: map[string]string{"uid": "42"}},
+			want: &pb.Span{Meta: map[string]string{"usr.id": sha256Hex("42")}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			NewSpanProcessor(tt.rules).Process(pb.Trace{tt.got})
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -15,6 +16,9 @@ const (
 	tagVersion    = "version"
 	tagOrigin     = "_dd.origin"
 	tagSynthetics = "synthetics"
+
+	// otherValue replaces the values of the extra aggregators past their cardinality limit
+	otherValue = "other"
 )
 
 // Aggregation contains all the dimension on which we aggregate statistics
@@ -29,6 +33,7 @@ type Aggregation struct {
 	StatusCode uint32
 	Version    string
 	Synthetics bool
+	ExtraTags  string // the extra aggregators, as comma-separated tags
 }
 
 func getStatusCode(s *pb.Span) uint32 {
@@ -62,3 +67,84 @@ func NewAggregationFromSpan(s *pb.Span, env string, agentHostname string) Aggreg
 		Synthetics: synthetics,
 	}
 }
+
+// extraAggregators computes the extra aggregators of the spans, which are span tags added
+// to the dimensions of the stats. Up to maxValues values are allowed for a tag, its other values
+// are replaced by "other". An allowed value is kept across flushes until it has not been seen
+// for ttl flushes, so the values of a tag only change slowly. It is not thread-safe.
+type extraAggregators struct {
+	keys      []string
+	maxValues int
+	ttl       int64
+	flushes   int64              // number of flushes so far
+	values    []map[string]int64 // allowed values, by key, with the last flush they were seen before
+	overflows []int64            // values replaced since the last flush, by key
+	buf       strings.Builder
+}
+
+func newExtraAggregators(keys []string, maxValues int, ttl int64) *extraAggregators {
+	e := &extraAggregators{
+		keys:      keys,
+		maxValues: maxValues,
+		ttl:       ttl,
+		values:    make([]map[string]int64, len(keys)),
+		overflows: make([]int64, len(keys)),
+	}
+	for i := range keys {
+		e.values[i] = make(map[string]int64)
+	}
+	return e
+}
+
+// tags returns the extra aggregators of the span as comma-separated normalized tags. The
+// tags missing from the span are omitted.
+func (e *extraAggregators) tags(s *pb.Span) string {
+	if len(e.keys) == 0 {
+		return ""
+	}
+	e.buf.Reset()
+	for i, k := range e.keys {
+		v, ok := s.Meta[k]
+		if !ok {
+			continue
+		}
+		if _, ok := e.values[i][v]; ok || e.maxValues <= 0 || len(e.values[i]) < e.maxValues {
+			e.values[i][v] = e.flushes
+		} else {
+			v = otherValue
+			e.overflows[i]++
+		}
+		if e.buf.Len() > 0 {
+			e.buf.WriteByte(',')
+		}
+		// the normalization replaces the commas
+		e.buf.WriteString(traceutil.NormalizeTag(k + ":" + v))
+	}
+	return e.buf.String()
+}
+
+// flush reports the number of values replaced by "other" since the last flush and forgets
+// the values which have not been seen for ttl flushes.
+func (e *extraAggregators) flush() {
+	e.flushes++
+	for i, k := range e.keys {
+		if e.overflows[i] > 0 {
+			log.Debugf("Extra aggregator %q exceeded %d values, %d values were aggregated as %q", k, e.maxValues, e.overflows[i], otherValue)
+			metrics.Count("datadog.trace_agent.stats.extra_aggregators.overflow", e.overflows[i], []string{"tag:" + k}, 1)
+			e.overflows[i] = 0
+		}
+		for v, seen := range e.values[i] {
+			if e.flushes-seen > e.ttl {
+				delete(e.values[i], v)
+			}
+		}
+	}
+}
+
+// splitExtraTags returns the tags of the extra aggregators of an aggregation.
+func splitExtraTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
@@ -19,6 +19,10 @@ import (
 // units used by the concentrator.
 const defaultBufferLen = 2
 
+// extraAggregatorValueTTL is the time after which an unseen value of an extra aggregator is
+// forgotten, making room for a new value.
+const extraAggregatorValueTTL = time.Hour
+
 // Concentrator produces time bucketed statistics from a stream of raw traces.
 // https://en.wikipedia.org/wiki/Knelson_concentrator
 // Gets an imperial shitton of traces, and outputs pre-computed data structures
@@ -43,6 +47,8 @@ type Concentrator struct {
 	mu            sync.Mutex
 	agentEnv      string
 	agentHostname string
+	// extraAggregators are the span tags added to the dimensions of the stats, guarded by mu
+	extraAggregators *extraAggregators
 }
 
 // NewConcentrator initializes a new concentrator ready to be started
@@ -61,6 +67,8 @@ func NewConcentrator(conf *config.AgentConfig, out chan pb.StatsPayload, now tim
 		exit:          make(chan struct{}),
 		agentEnv:      conf.DefaultEnv,
 		agentHostname: conf.Hostname,
+
+		extraAggregators: newExtraAggregators(conf.ExtraAggregators, conf.MaxExtraAggregatorValues, int64(extraAggregatorValueTTL)/bsize),
 	}
 	return &c
 }
@@ -150,7 +158,7 @@ func (c *Concentrator) addNow(i *Input) {
 			b = NewRawBucket(uint64(btime), uint64(c.bsize))
 			c.buckets[btime] = b
 		}
-		b.HandleSpan(s, env, c.agentHostname)
+		b.handleSpan(s, env, c.agentHostname, c.extraAggregators.tags(s.Span))
 	}
 }
 
@@ -176,6 +184,7 @@ func (c *Concentrator) flushNow(now int64) pb.StatsPayload {
 		}
 		delete(c.buckets, ts)
 	}
+	c.extraAggregators.flush()
 	// After flushing, update the oldest timestamp allowed to prevent having stats for
 	// an already-flushed bucket.
 	newOldestTs := alignTs(now, c.bsize) - int64(c.bufferLen-1)*c.bsize
@@ -8,6 +8,7 @@ package stats
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -485,3 +486,94 @@ func TestDistributions(t *testing.T) {
 		}
 	})
 }
+
+func TestConcentratorExtraAggregators(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	cfg := config.AgentConfig{
+		BucketInterval:           time.Duration(testBucketInterval),
+		DefaultEnv:               "env",
+		Hostname:                 "hostname",
+		ExtraAggregators:         []string{"customer_tier", "region"},
+		MaxExtraAggregatorValues: 2,
+	}
+	c := NewConcentrator(&cfg, make(chan pb.StatsPayload), now)
+
+	var trace pb.Trace
+	for i, meta := range []map[string]string{
+		{"customer_tier": "gold", "region": "us-east-1"},
+		{"customer_tier": "gold", "region": "us-east-1"},
+		{"customer_tier": "silver"},
+		{"customer_tier": "bronze", "region": "us-east-1"},
+		{"customer_tier": "platinum", "region": "us-east-1"},
+		{},
+	} {
+		span := testSpan(uint64(i+1), 0, 10, 0, "A1", "resource1", 0)
+		span.Meta = meta
+		trace = append(trace, span)
+	}
+	traceutil.ComputeTopLevel(trace)
+	c.addNow(&Input{Env: "none", Trace: NewWeightedTrace(trace, traceutil.GetRoot(trace))})
+
+	flushTime := now.UnixNano() + int64(c.bufferLen)*testBucketInterval
+	stats := c.flushNow(flushTime)
+	if !assert.Len(stats.Stats, 1) || !assert.Len(stats.Stats[0].Stats, 1) {
+		t.FailNow()
+	}
+	hits := make(map[string]uint64)
+	for _, g := range stats.Stats[0].Stats[0].Stats {
+		hits[strings.Join(g.Tags, ",")] += g.Hits
+	}
+	// past 2 values, the tiers are aggregated as "other"
+	assert.Equal(map[string]uint64{
+		"customer_tier:gold,region:us-east-1":  2,
+		"customer_tier:silver":                 1,
+		"customer_tier:other,region:us-east-1": 2,
+		"":                                     1,
+	}, hits)
+
+	// the allowed values are kept across flushes
+	assert.Equal("customer_tier:other", c.extraAggregators.tags(&pb.Span{Meta: map[string]string{"customer_tier": "bronze"}}))
+	assert.Equal("customer_tier:gold", c.extraAggregators.tags(&pb.Span{Meta: map[string]string{"customer_tier": "gold"}}))
+}
+
+func TestExtraAggregatorsAcrossFlushes(t *testing.T) {
+	assert := assert.New(t)
+	tier := func(v string) *pb.Span { return &pb.Span{Meta: map[string]string{"customer_tier": v}} }
+	e := newExtraAggregators([]string{"customer_tier"}, 2, 3)
+
+	assert.Equal("customer_tier:gold", e.tags(tier("gold")))
+	assert.Equal("customer_tier:silver", e.tags(tier("silver")))
+	assert.Equal("customer_tier:other", e.tags(tier("bronze")))
+
+	// new values keep being aggregated as "other" while the allowed values are seen
+	for i := 0; i < 10; i++ {
+		e.flush()
+		assert.Equal("customer_tier:gold", e.tags(tier("gold")))
+		assert.Equal("customer_tier:other", e.tags(tier(fmt.Sprintf("tier-%d", i))))
+		if i%2 == 0 {
+			assert.Equal("customer_tier:silver", e.tags(tier("silver")))
+		}
+	}
+	assert.Len(e.values[0], 2)
+
+	// once a value has not been seen for 3 flushes, it makes room for a new one
+	for i := 0; i < 2; i++ {
+		e.flush()
+		assert.Equal("customer_tier:gold", e.tags(tier("gold")))
+		assert.Equal("customer_tier:other", e.tags(tier("bronze")))
+	}
+	e.flush()
+	assert.Equal("customer_tier:bronze", e.tags(tier("bronze")))
+	assert.Equal("customer_tier:other", e.tags(tier("silver")))
+}
+
+func TestExtraAggregatorsNormalization(t *testing.T) {
+	e := newExtraAggregators([]string{"customer.name", "region"}, 0, 1)
+	assert.Equal(t, "", e.tags(&pb.Span{}))
+	assert.Equal(t, "customer.name:acme_inc,region:eu", e.tags(&pb.Span{Meta: map[string]string{
+		"customer.name": "Acme, Inc",
+		"region":        "EU",
+	}}))
+	assert.Nil(t, splitExtraTags(""))
+}
@@ -73,6 +73,7 @@ func (s *groupedStats) export(k statsKey) (pb.ClientGroupedStats, error) {
 		OkSummary:      okSummary,
 		ErrorSummary:   errSummary,
 		Synthetics:     k.aggr.Synthetics,
+		Tags:           splitExtraTags(k.aggr.ExtraTags),
 	}, nil
 }
 
@@ -160,10 +161,16 @@ func (sb *RawBucket) Export() map[PayloadKey]pb.ClientStatsBucket {
 
 // HandleSpan adds the span to this bucket stats, aggregated with the finest grain matching given aggregators
 func (sb *RawBucket) HandleSpan(s *WeightedSpan, env string, agentHostname string) {
+	sb.handleSpan(s, env, agentHostname, "")
+}
+
+// handleSpan adds the span to this bucket stats, with the given extra aggregators.
+func (sb *RawBucket) handleSpan(s *WeightedSpan, env string, agentHostname string, extraTags string) {
 	if env == "" {
 		panic("env should never be empty")
 	}
 	aggr := NewAggregationFromSpan(s.Span, env, agentHostname)
+	aggr.ExtraTags = extraTags
 	sb.add(s, aggr)
 }
 
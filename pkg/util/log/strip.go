@@ -63,12 +63,17 @@ func init() {
 		Hints: []string{"community_string", "authKey", "privKey"},
 		Repl:  []byte(`$1 ********`),
 	}
+	encryptionKeyReplacer := Replacer{
+		Regex: matchYAMLKey(`forwarder_storage_encryption_key`),
+		Hints: []string{"forwarder_storage_encryption_key"},
+		Repl:  []byte(`$1 ********`),
+	}
 	certReplacer := Replacer{
 		Regex: matchCert(),
 		Hints: []string{"BEGIN"},
 		Repl:  []byte(`********`),
 	}
-	singleLineReplacers = []Replacer{hintedKeyReplacer, apiKeyReplacer, appKeyReplacer, uriPasswordReplacer, passwordReplacer, tokenReplacer, snmpReplacer}
+	singleLineReplacers = []Replacer{hintedKeyReplacer, apiKeyReplacer, appKeyReplacer, uriPasswordReplacer, passwordReplacer, tokenReplacer, snmpReplacer, encryptionKeyReplacer}
 	multiLineReplacers = []Replacer{certReplacer}
 }
 
@@ -253,6 +253,15 @@ func TestSNMPConfig(t *testing.T) {
 		`   community_string: ********`)
 }
 
+func TestForwarderStorageEncryptionKey(t *testing.T) {
+	assertClean(t,
+		`forwarder_storage_encryption_key: cZ0tOmK1tvAnJbeNT2mHD0hN8jxIMJmg2gHlbz9R+pE=`,
+		`forwarder_storage_encryption_key: ********`)
+	assertClean(t,
+		`forwarder_storage_encryption_key_file: /etc/datadog-agent/forwarder_storage.key`,
+		`forwarder_storage_encryption_key_file: /etc/datadog-agent/forwarder_storage.key`)
+}
+
 func TestYamlConfig(t *testing.T) {
 	contents := `foobar: baz`
 	cleaned, err := CredentialsCleanerBytes([]byte(contents))
@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package wildcard converts the wildcard patterns of the configuration to regular expressions.
+package wildcard
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Expr returns the unanchored regular expression of a wildcard pattern, where `*`
+// matches any sequence of characters and `?` any single character.
+func Expr(pattern string) string {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.Replace(expr, `\*`, ".*", -1)
+	return strings.Replace(expr, `\?`, ".", -1)
+}
+
+// Regexp returns a regexp matching the whole strings matched by a wildcard pattern.
+func Regexp(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + Expr(pattern) + "$")
+}
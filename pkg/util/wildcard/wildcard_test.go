@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package wildcard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexp(t *testing.T) {
+	re := Regexp("http_*_total")
+	assert.True(t, re.MatchString("http_requests_total"))
+	assert.True(t, re.MatchString("http__total"))
+	assert.False(t, re.MatchString("grpc_http_requests_total"))
+
+	re = Regexp("node_cpu?")
+	assert.True(t, re.MatchString("node_cpu0"))
+	assert.False(t, re.MatchString("node_cpu"))
+	assert.False(t, re.MatchString("node_cpu10"))
+
+	// the other characters are matched literally
+	re = Regexp("go.gc(1)")
+	assert.True(t, re.MatchString("go.gc(1)"))
+	assert.False(t, re.MatchString("go_gc(1)"))
+}